@@ -0,0 +1,10 @@
+// Package bench holds reproducible benchmarks of zerolog across field mixes
+// and writers, to catch performance regressions and help sizing logging
+// pipelines. It contains no code to import; run the benchmarks with:
+//
+//     go test -run - -bench . -benchmem ./bench
+//
+// Each benchmark is named Benchmark<Mix>/<writer>. The encoding is selected
+// at build time: run the same benchmarks with -tags zerolog_bson to measure
+// the BSON encoding, and compare both runs with benchstat.
+package bench
@@ -0,0 +1,156 @@
+package bench
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	errExample  = errors.New("connection reset by peer")
+	fakeMessage = "Test logging, but use a somewhat realistic message length."
+	longString  = strings.Repeat("lorem ipsum dolor sit amet ", 20)
+)
+
+// writers are the outputs the field mixes are benchmarked against. new
+// returns the writer and a function releasing it.
+var writers = []struct {
+	name string
+	new  func(b *testing.B) (io.Writer, func())
+}{
+	{"discard", func(b *testing.B) (io.Writer, func()) {
+		return ioutil.Discard, func() {}
+	}},
+	{"sync", func(b *testing.B) (io.Writer, func()) {
+		return zerolog.SyncWriter(ioutil.Discard), func() {}
+	}},
+	{"file", func(b *testing.B) (io.Writer, func()) {
+		f := tempFile(b)
+		return f, func() { f.Close() }
+	}},
+	{"batch", func(b *testing.B) (io.Writer, func()) {
+		f := tempFile(b)
+		w := zerolog.NewBatchWriter(f, 64<<10, 100*time.Millisecond)
+		return w, func() { w.Close(); f.Close() }
+	}},
+	{"ring", func(b *testing.B) (io.Writer, func()) {
+		f := tempFile(b)
+		w := zerolog.NewRingWriter(f, runtime.GOMAXPROCS(0), 4096)
+		return w, func() { w.Close(); f.Close() }
+	}},
+	{"sharded", func(b *testing.B) (io.Writer, func()) {
+		f := tempFile(b)
+		w := zerolog.NewShardedWriter(f, 64<<10, 100*time.Millisecond)
+		return w, func() { w.Close(); f.Close() }
+	}},
+}
+
+func tempFile(b *testing.B) *os.File {
+	f, err := ioutil.TempFile(b.TempDir(), "bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return f
+}
+
+// run benchmarks log, called concurrently with a logger built by newLogger,
+// against each of the writers.
+func run(b *testing.B, newLogger func(w io.Writer) zerolog.Logger, log func(l zerolog.Logger)) {
+	for _, w := range writers {
+		w := w
+		b.Run(w.name, func(b *testing.B) {
+			out, release := w.new(b)
+			defer release()
+			l := newLogger(out)
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					log(l)
+				}
+			})
+		})
+	}
+}
+
+func newLogger(w io.Writer) zerolog.Logger {
+	return zerolog.New(w)
+}
+
+func BenchmarkMessage(b *testing.B) {
+	run(b, newLogger, func(l zerolog.Logger) {
+		l.Info().Msg(fakeMessage)
+	})
+}
+
+func BenchmarkRequest(b *testing.B) {
+	run(b, newLogger, func(l zerolog.Logger) {
+		l.Info().
+			Str("method", "GET").
+			Str("url", "/api/v1/users/42").
+			Int("status", 200).
+			Int64("size", 1532).
+			Dur("duration", 1500*time.Microsecond).
+			Msg("request")
+	})
+}
+
+func BenchmarkError(b *testing.B) {
+	run(b, newLogger, func(l zerolog.Logger) {
+		l.Error().
+			Err(errExample).
+			Str("peer", "10.0.0.1:5432").
+			Int("attempt", 3).
+			Msg(fakeMessage)
+	})
+}
+
+func BenchmarkLarge(b *testing.B) {
+	run(b, newLogger, func(l zerolog.Logger) {
+		l.Info().
+			Str("string", longString).
+			Dict("dict", zerolog.Dict().
+				Str("a", "b").
+				Int("c", 1).
+				Bool("d", false)).
+			Bool("bool", true).
+			Int("int", 123).
+			Uint64("uint64", 1<<40).
+			Float64("float64", 3.14159).
+			Time("time", time.Time{}).
+			Dur("dur", time.Second).
+			Interface("object", map[string]int{"a": 1}).
+			Msg(fakeMessage)
+	})
+}
+
+func BenchmarkContext(b *testing.B) {
+	newContextLogger := func(w io.Writer) zerolog.Logger {
+		return zerolog.New(w).With().
+			Timestamp().
+			Str("service", "api").
+			Str("version", "v1.2.3").
+			Str("host", "web-1").
+			Int("pid", 4242).
+			Logger()
+	}
+	run(b, newContextLogger, func(l zerolog.Logger) {
+		l.Info().Str("user", "42").Msg(fakeMessage)
+	})
+}
+
+func BenchmarkDisabled(b *testing.B) {
+	newDisabledLogger := func(w io.Writer) zerolog.Logger {
+		return zerolog.New(w).Level(zerolog.InfoLevel)
+	}
+	run(b, newDisabledLogger, func(l zerolog.Logger) {
+		l.Debug().Str("method", "GET").Int("status", 200).Msg(fakeMessage)
+	})
+}
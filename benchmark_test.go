@@ -1,6 +1,7 @@
 package zerolog
 
 import (
+	"encoding/base64"
 	"errors"
 	"io/ioutil"
 	"testing"
@@ -32,6 +33,37 @@ func BenchmarkDisabled(b *testing.B) {
 	})
 }
 
+func BenchmarkDisabledHooks(b *testing.B) {
+	logger := New(ioutil.Discard).Level(WarnLevel).
+		Sample(&BasicSampler{N: 2}).
+		Hook(HookFunc(func(e *Event, level Level, msg string) {})).
+		With().Str("foo", "bar").Logger()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info().Msg(fakeMessage)
+		}
+	})
+}
+
+// TestDisabledHooksNoAlloc asserts that disabled events stay on the fast
+// path, without allocations nor going through the sampler and the hooks.
+// BenchmarkDisabledHooks measures their cost.
+func TestDisabledHooksNoAlloc(t *testing.T) {
+	logger := New(ioutil.Discard).Level(WarnLevel).
+		Sample(&BasicSampler{N: 2}).
+		Hook(HookFunc(func(e *Event, level Level, msg string) {
+			t.Error("hook called for a disabled event")
+		})).
+		With().Str("foo", "bar").Logger()
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Info().Msg(fakeMessage)
+	})
+	if allocs != 0 {
+		t.Errorf("disabled event allocated %v times, want 0", allocs)
+	}
+}
+
 func BenchmarkInfo(b *testing.B) {
 	logger := New(ioutil.Discard)
 	b.ResetTimer()
@@ -42,6 +74,14 @@ func BenchmarkInfo(b *testing.B) {
 	})
 }
 
+func BenchmarkInfoEmitter(b *testing.B) {
+	em := New(ioutil.Discard).Emitter()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		em.Info().Msg(fakeMessage)
+	}
+}
+
 func BenchmarkContextFields(b *testing.B) {
 	logger := New(ioutil.Discard).With().
 		Str("string", "four!").
@@ -71,3 +111,162 @@ func BenchmarkLogFields(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkLogFieldsStrUnsafe(b *testing.B) {
+	logger := New(ioutil.Discard)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info().
+				StrUnsafe("request_id", "c2f1a9e4-0b7d-4d1e-9a43-5e8b6f7a2c10").
+				StrUnsafe("route", "/api/v1/users").
+				Msg(fakeMessage)
+		}
+	})
+}
+
+func BenchmarkLogFieldsBytes(b *testing.B) {
+	logger := New(ioutil.Discard)
+	payload := []byte(fakeMessage)
+	raw := []byte(`{"id":"c2f1a9e4-0b7d-4d1e-9a43-5e8b6f7a2c10","tags":["a","b"],"n":42}`)
+	for _, validate := range []bool{true, false} {
+		name := "validated"
+		if !validate {
+			name = "trusted"
+		}
+		b.Run(name, func(b *testing.B) {
+			defer func() { RawJSONValidation = true }()
+			RawJSONValidation = validate
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					logger.Info().
+						Bytes("payload", payload).
+						RawJSON("raw", raw).
+						Msg(fakeMessage)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkLogFieldsBase64(b *testing.B) {
+	logger := New(ioutil.Discard)
+	sig := make([]byte, 64)
+	for i := range sig {
+		sig[i] = byte(i * 7)
+	}
+	b.Run("Base64", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				logger.Info().Base64("sig", sig).Msg(fakeMessage)
+			}
+		})
+	})
+	b.Run("EncodeToString", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				logger.Info().Str("sig", base64.StdEncoding.EncodeToString(sig)).Msg(fakeMessage)
+			}
+		})
+	})
+}
+
+func BenchmarkLogFieldsSampledOut(b *testing.B) {
+	for _, deferred := range []bool{false, true} {
+		name := "direct"
+		logger := New(ioutil.Discard).Sample(&FirstNSampler{N: 1})
+		if deferred {
+			name = "deferred"
+			logger = logger.DeferFields()
+		}
+		b.Run(name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					logger.Info().
+						Str("string", "four!").
+						Time("time", time.Time{}).
+						Int("int", 123).
+						Float32("float", -2.203230293249593).
+						Msg(fakeMessage)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkLogHooks(b *testing.B) {
+	nop := HookFunc(func(e *Event, level Level, msg string) {})
+	logger := New(ioutil.Discard).
+		Hook(nop).
+		Hook(WithMinLevel(WarnLevel, nop)).
+		Hook(nop)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info().Msg(fakeMessage)
+		}
+	})
+}
+
+func BenchmarkLogCaller(b *testing.B) {
+	logger := New(ioutil.Discard)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info().Caller().Msg(fakeMessage)
+		}
+	})
+}
+
+func BenchmarkWithFanOut(b *testing.B) {
+	logger := New(ioutil.Discard).With().Str("service", "api").Str("region", "eu").Logger()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l := logger.With().Logger()
+			l.Info().Msg(fakeMessage)
+		}
+	})
+}
+
+func BenchmarkTimestamp(b *testing.B) {
+	logger := New(ioutil.Discard).With().Timestamp().Logger()
+	for _, res := range []time.Duration{0, time.Millisecond} {
+		b.Run(res.String(), func(b *testing.B) {
+			SetTimestampCache(res)
+			defer SetTimestampCache(0)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					logger.Info().Msg(fakeMessage)
+				}
+			})
+		})
+	}
+}
+
+func TestConsoleWriterNoAlloc(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector drops the pooled buffers")
+	}
+	w := ConsoleWriter{Out: ioutil.Discard}
+	p := []byte(`{"level":"info","time":"2017-05-12T17:10:37+02:00","caller":"main.go:42","foo":"bar baz","n":123,"obj":{"a":[1, 2]},"error":"boom","message":"Foobar"}` + "\n")
+	allocs := testing.AllocsPerRun(100, func() {
+		w.Write(p)
+	})
+	if allocs != 0 {
+		t.Errorf("Write allocated %v times, want 0", allocs)
+	}
+}
+
+func BenchmarkConsoleWriter(b *testing.B) {
+	w := ConsoleWriter{Out: ioutil.Discard}
+	p := []byte(`{"level":"info","time":"2017-05-12T17:10:37+02:00","caller":"main.go:42","foo":"bar","n":123,"obj":{"a":[1,2]},"error":"boom","message":"` + fakeMessage + `"}` + "\n")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Write(p)
+	}
+}
@@ -0,0 +1,9 @@
+package zerolog
+
+import "unsafe"
+
+// bytesString returns b as a string without copying it. The string shares
+// the memory of b, so it must not be retained once the caller may modify b.
+func bytesString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
@@ -0,0 +1,47 @@
+package zerolog
+
+import (
+	"runtime"
+	"sync"
+)
+
+// contextCallerSkipFrameCount is the number of stack frames between the
+// caller hook and Event.caller when the caller is added by Context.Caller.
+const contextCallerSkipFrameCount = 4
+
+// callerCache maps the program counters of the call sites to their location
+// formatted by CallerMarshalFunc, so repeated call sites cost a map lookup
+// instead of symbolizing the program counter each time.
+var callerCache = struct {
+	sync.RWMutex
+	m map[uintptr]string
+}{m: map[uintptr]string{}}
+
+// callerHook adds the caller of Msg to the events.
+type callerHook struct{}
+
+// Run implements the Hook interface.
+func (callerHook) Run(e *Event, level Level, msg string) {
+	e.caller(CallerSkipFrameCount + contextCallerSkipFrameCount)
+}
+
+// caller returns the location of the caller skip frames above the caller of
+// caller, as formatted by CallerMarshalFunc.
+func caller(skip int) (string, bool) {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return "", false
+	}
+	callerCache.RLock()
+	loc, ok := callerCache.m[pcs[0]]
+	callerCache.RUnlock()
+	if ok {
+		return loc, true
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pcs[0]}).Next()
+	loc = CallerMarshalFunc(frame.PC, frame.File, frame.Line)
+	callerCache.Lock()
+	callerCache.m[pcs[0]] = loc
+	callerCache.Unlock()
+	return loc, true
+}
@@ -0,0 +1,70 @@
+package zerolog
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func callerLocation(t *testing.T, delta int) string {
+	_, file, line, _ := runtime.Caller(1)
+	return file + ":" + strconv.Itoa(line+delta)
+}
+
+func TestEventCaller(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Log().Caller().Msg("msg")
+	want := fmt.Sprintf(`{"caller":%q,"message":"msg"}`+"\n", callerLocation(t, -1))
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func logWithCaller(log Logger) {
+	log.Log().Caller(1).Msg("helper")
+}
+
+func TestEventCallerSkip(t *testing.T) {
+	out := &bytes.Buffer{}
+	logWithCaller(New(out))
+	want := fmt.Sprintf(`{"caller":%q,"message":"helper"}`+"\n", callerLocation(t, -1))
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestContextCaller(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().Caller().Logger()
+	log.Log().Msg("msg")
+	want := fmt.Sprintf(`{"caller":%q,"message":"msg"}`+"\n", callerLocation(t, -1))
+	log.Log().Msgf("msg %d", 2)
+	want += fmt.Sprintf(`{"caller":%q,"message":"msg 2"}`+"\n", callerLocation(t, -1))
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestCallerCache(t *testing.T) {
+	defer func(f func(pc uintptr, file string, line int) string) { CallerMarshalFunc = f }(CallerMarshalFunc)
+	calls := 0
+	CallerMarshalFunc = func(pc uintptr, file string, line int) string {
+		calls++
+		return "here"
+	}
+	out := &bytes.Buffer{}
+	log := New(out)
+	for i := 0; i < 3; i++ {
+		log.Log().Caller().Msg("")
+	}
+	if calls != 1 {
+		t.Errorf("CallerMarshalFunc called %d times, want 1", calls)
+	}
+	want := `{"caller":"here"}` + "\n" + `{"caller":"here"}` + "\n" + `{"caller":"here"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
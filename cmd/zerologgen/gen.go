@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// basicMethods maps the basic types to the Event method adding them.
+var basicMethods = map[string]string{
+	"string":  "Str",
+	"bool":    "Bool",
+	"int":     "Int",
+	"int8":    "Int8",
+	"int16":   "Int16",
+	"int32":   "Int32",
+	"rune":    "Int32",
+	"int64":   "Int64",
+	"uint":    "Uint",
+	"uint8":   "Uint8",
+	"byte":    "Uint8",
+	"uint16":  "Uint16",
+	"uint32":  "Uint32",
+	"uint64":  "Uint64",
+	"float32": "Float32",
+	"float64": "Float64",
+}
+
+// generateDir parses the Go files of dir, except the tests and the file
+// named skip, and returns the source generated for types.
+func generateDir(dir string, types []string, skip string) ([]byte, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, p := range paths {
+		if strings.HasSuffix(p, "_test.go") || filepath.Base(p) == skip {
+			continue
+		}
+		f, err := parser.ParseFile(fset, p, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return generate(files, types)
+}
+
+type generator struct {
+	pkg   string
+	decls map[string]ast.Expr
+	gen   map[string]bool
+	buf   bytes.Buffer
+}
+
+// generate returns the source of the methods and helpers of types, declared
+// in files.
+func generate(files []*ast.File, types []string) ([]byte, error) {
+	g := &generator{decls: map[string]ast.Expr{}, gen: map[string]bool{}}
+	for _, f := range files {
+		if g.pkg == "" {
+			g.pkg = f.Name.Name
+		} else if f.Name.Name != g.pkg {
+			return nil, fmt.Errorf("found packages %s and %s", g.pkg, f.Name.Name)
+		}
+		for _, d := range f.Decls {
+			gd, ok := d.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range gd.Specs {
+				ts := s.(*ast.TypeSpec)
+				g.decls[ts.Name.Name] = ts.Type
+			}
+		}
+	}
+	for _, t := range types {
+		if _, ok := g.decls[t].(*ast.StructType); !ok {
+			return nil, fmt.Errorf("struct type %s not found", t)
+		}
+		g.gen[t] = true
+	}
+	fmt.Fprintf(&g.buf, "// Code generated by zerologgen; DO NOT EDIT.\n\npackage %s\n\nimport \"github.com/rs/zerolog\"\n", g.pkg)
+	for _, t := range types {
+		g.genType(t, g.decls[t].(*ast.StructType))
+	}
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("invalid generated source: %v", err)
+	}
+	return src, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) genType(name string, st *ast.StructType) {
+	g.printf("\n// MarshalZerologObject implements the zerolog.LogObjectMarshaler interface.\n")
+	g.printf("func (v %s) MarshalZerologObject(e *zerolog.Event) {\n", name)
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			g.genEmbedded(f)
+			continue
+		}
+		for _, n := range f.Names {
+			if !n.IsExported() {
+				continue
+			}
+			key, omitEmpty, ok := fieldKey(n.Name, f.Tag)
+			if !ok {
+				continue
+			}
+			g.genField(strconv.Quote(key), "v."+n.Name, f.Type, omitEmpty)
+		}
+	}
+	g.printf("}\n")
+	r, size := utf8.DecodeRuneInString(name)
+	helper := "Log" + string(unicode.ToUpper(r)) + name[size:]
+	g.printf("\n// %s adds the fields of v to e.\n", helper)
+	g.printf("func %s(e *zerolog.Event, v %s) *zerolog.Event {\n", helper, name)
+	g.printf("if e.Enabled() {\nv.MarshalZerologObject(e)\n}\nreturn e\n}\n")
+}
+
+// genEmbedded generates the fields of an embedded field: the fields of the
+// generated types are inlined, the other types are added as a field named
+// after the type.
+func (g *generator) genEmbedded(f *ast.Field) {
+	t := f.Type
+	if s, ok := t.(*ast.StarExpr); ok {
+		t = s.X
+	}
+	var name string
+	switch t := t.(type) {
+	case *ast.Ident:
+		name = t.Name
+	case *ast.SelectorExpr:
+		name = t.Sel.Name
+	default:
+		return
+	}
+	if !ast.IsExported(name) {
+		return
+	}
+	if g.gen[name] {
+		if _, ok := f.Type.(*ast.StarExpr); ok {
+			g.printf("if v.%[1]s != nil {\nv.%[1]s.MarshalZerologObject(e)\n}\n", name)
+		} else {
+			g.printf("v.%s.MarshalZerologObject(e)\n", name)
+		}
+		return
+	}
+	key, omitEmpty, ok := fieldKey(name, f.Tag)
+	if !ok {
+		return
+	}
+	g.genField(strconv.Quote(key), "v."+name, f.Type, omitEmpty)
+}
+
+// genField generates the call adding the expression x of type t with the
+// quoted key.
+func (g *generator) genField(key, x string, t ast.Expr, omitEmpty bool) {
+	if s, ok := t.(*ast.StarExpr); ok {
+		g.printf("if %s != nil {\n", x)
+		g.genField(key, "(*"+x+")", s.X, omitEmpty)
+		g.printf("}\n")
+		return
+	}
+	if id, ok := t.(*ast.Ident); ok && g.gen[id.Name] {
+		g.printf("{\nd := zerolog.Dict()\n%s.MarshalZerologObject(d)\ne.Dict(%s, d)\n}\n", x, key)
+		return
+	}
+	if id, ok := t.(*ast.Ident); ok && id.Name == "error" {
+		g.printf("e.AnErr(%s, %s)\n", key, x)
+		return
+	}
+	if sel, ok := t.(*ast.SelectorExpr); ok {
+		if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "time" {
+			switch sel.Sel.Name {
+			case "Time":
+				if omitEmpty {
+					g.printf("if !%s.IsZero() {\ne.Time(%s, %s)\n}\n", x, key, x)
+				} else {
+					g.printf("e.Time(%s, %s)\n", key, x)
+				}
+				return
+			case "Duration":
+				g.genOmitEmpty(omitEmpty, x, "0", fmt.Sprintf("e.Dur(%s, %s)", key, x))
+				return
+			}
+		}
+	}
+	m, basic := g.method(t)
+	if m == "" {
+		g.printf("e.Interface(%s, %s)\n", key, x)
+		return
+	}
+	arg := x
+	if id, ok := t.(*ast.Ident); !ok || id.Name != basic {
+		// A named type of the package: convert it to its basic type.
+		arg = basic + "(" + x + ")"
+	}
+	call := fmt.Sprintf("e.%s(%s, %s)", m, key, arg)
+	switch basic {
+	case "string":
+		g.genOmitEmpty(omitEmpty, x, `""`, call)
+	case "bool":
+		if omitEmpty {
+			g.printf("if %s {\n%s\n}\n", x, call)
+		} else {
+			g.printf("%s\n", call)
+		}
+	default:
+		g.genOmitEmpty(omitEmpty, x, "0", call)
+	}
+}
+
+func (g *generator) genOmitEmpty(omitEmpty bool, x, zero, call string) {
+	if omitEmpty {
+		g.printf("if %s != %s {\n%s\n}\n", x, zero, call)
+	} else {
+		g.printf("%s\n", call)
+	}
+}
+
+// method returns the Event method adding the values of t, a basic type or a
+// type of the package based on a basic type, and the basic type, or an
+// empty method if t is not such a type.
+func (g *generator) method(t ast.Expr) (method, basic string) {
+	for depth := 0; depth < 10; depth++ {
+		id, ok := t.(*ast.Ident)
+		if !ok {
+			return "", ""
+		}
+		if m, ok := basicMethods[id.Name]; ok {
+			if _, declared := g.decls[id.Name]; !declared {
+				return m, id.Name
+			}
+		}
+		if t, ok = g.decls[id.Name]; !ok {
+			return "", ""
+		}
+	}
+	return "", ""
+}
+
+// fieldKey returns the key of the field name with tag, whether its zero
+// values are omitted, and false if the field is skipped.
+func fieldKey(name string, tag *ast.BasicLit) (key string, omitEmpty bool, ok bool) {
+	key = name
+	if tag == nil {
+		return key, false, true
+	}
+	s, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return key, false, true
+	}
+	v, found := reflect.StructTag(s).Lookup("zerolog")
+	if !found {
+		v, found = reflect.StructTag(s).Lookup("json")
+	}
+	if !found {
+		return key, false, true
+	}
+	parts := strings.Split(v, ",")
+	if parts[0] == "-" && len(parts) == 1 {
+		return "", false, false
+	}
+	if parts[0] != "" {
+		key = parts[0]
+	}
+	for _, o := range parts[1:] {
+		if o == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return key, omitEmpty, true
+}
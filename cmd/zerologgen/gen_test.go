@@ -0,0 +1,135 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+const testSource = `package api
+
+import "time"
+
+type Status int
+
+type Request struct {
+	Method   string
+	URL      string        ` + "`json:\"url\"`" + `
+	Status   Status        ` + "`zerolog:\"status,omitempty\"`" + `
+	Size     *int64        ` + "`json:\"size,omitempty\"`" + `
+	Duration time.Duration ` + "`json:\"duration\"`" + `
+	Start    time.Time     ` + "`json:\"start,omitempty\"`" + `
+	Err      error         ` + "`json:\"error\"`" + `
+	Client   *Client       ` + "`json:\"client\"`" + `
+	Headers  map[string]string
+	Secret   string ` + "`json:\"-\"`" + `
+	internal string
+}
+
+type Client struct {
+	Base
+	IP    string ` + "`json:\"ip\"`" + `
+	Admin bool   ` + "`json:\"admin,omitempty\"`" + `
+}
+
+type Base struct {
+	ID uint64 ` + "`json:\"id\"`" + `
+}
+`
+
+const testOutput = `// Code generated by zerologgen; DO NOT EDIT.
+
+package api
+
+import "github.com/rs/zerolog"
+
+// MarshalZerologObject implements the zerolog.LogObjectMarshaler interface.
+func (v Request) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("Method", v.Method)
+	e.Str("url", v.URL)
+	if v.Status != 0 {
+		e.Int("status", int(v.Status))
+	}
+	if v.Size != nil {
+		if (*v.Size) != 0 {
+			e.Int64("size", (*v.Size))
+		}
+	}
+	e.Dur("duration", v.Duration)
+	if !v.Start.IsZero() {
+		e.Time("start", v.Start)
+	}
+	e.AnErr("error", v.Err)
+	if v.Client != nil {
+		{
+			d := zerolog.Dict()
+			(*v.Client).MarshalZerologObject(d)
+			e.Dict("client", d)
+		}
+	}
+	e.Interface("Headers", v.Headers)
+}
+
+// LogRequest adds the fields of v to e.
+func LogRequest(e *zerolog.Event, v Request) *zerolog.Event {
+	if e.Enabled() {
+		v.MarshalZerologObject(e)
+	}
+	return e
+}
+
+// MarshalZerologObject implements the zerolog.LogObjectMarshaler interface.
+func (v Client) MarshalZerologObject(e *zerolog.Event) {
+	v.Base.MarshalZerologObject(e)
+	e.Str("ip", v.IP)
+	if v.Admin {
+		e.Bool("admin", v.Admin)
+	}
+}
+
+// LogClient adds the fields of v to e.
+func LogClient(e *zerolog.Event, v Client) *zerolog.Event {
+	if e.Enabled() {
+		v.MarshalZerologObject(e)
+	}
+	return e
+}
+
+// MarshalZerologObject implements the zerolog.LogObjectMarshaler interface.
+func (v Base) MarshalZerologObject(e *zerolog.Event) {
+	e.Uint64("id", v.ID)
+}
+
+// LogBase adds the fields of v to e.
+func LogBase(e *zerolog.Event, v Base) *zerolog.Event {
+	if e.Enabled() {
+		v.MarshalZerologObject(e)
+	}
+	return e
+}
+`
+
+func TestGenerate(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "api.go", testSource, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := generate([]*ast.File{f}, []string{"Request", "Client", "Base"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != testOutput {
+		t.Errorf("invalid generated source:\ngot:\n%s\nwant:\n%s", got, testOutput)
+	}
+}
+
+func TestGenerateNotStruct(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "api.go", testSource, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generate([]*ast.File{f}, []string{"Status"}); err == nil {
+		t.Error("generate accepted a type which is not a struct")
+	}
+}
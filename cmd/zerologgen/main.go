@@ -0,0 +1,59 @@
+// Command zerologgen generates, for struct types, MarshalZerologObject
+// methods adding their fields to zerolog events without reflection, and
+// typed helpers logging them:
+//
+//     //go:generate zerologgen -type Request,Response
+//
+// For each type T, it generates the method implementing the
+// zerolog.LogObjectMarshaler interface and a LogT function adding the fields
+// of a T to an event:
+//
+//     LogRequest(log.Info(), req).Msg("handled")
+//
+// The fields are named after their zerolog struct tag, or their json tag,
+// or their name. A "-" name skips the field and the omitempty option skips
+// zero values. Fields of types other than the basic types, time.Time,
+// time.Duration, error, the generated types and the pointers to them are
+// added with Event.Interface, using reflection.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of the struct types to generate; required")
+	output := flag.String("output", "", "output file name; default <dir>/<type>_zerolog.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: zerologgen -type T[,T...] [-output file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+	types := strings.Split(*typeNames, ",")
+	out := *output
+	if out == "" {
+		out = filepath.Join(dir, strings.ToLower(types[0])+"_zerolog.go")
+	}
+	src, err := generateDir(dir, types, filepath.Base(out))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "zerologgen: %v\n", err)
+		os.Exit(1)
+	}
+	if err := ioutil.WriteFile(out, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "zerologgen: %v\n", err)
+		os.Exit(1)
+	}
+}
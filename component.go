@@ -0,0 +1,93 @@
+package zerolog
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// noComponentLevel is stored in a componentLevel without override.
+const noComponentLevel = math.MaxInt32
+
+// componentLevel holds the level override of a component. It is shared by
+// all loggers created with the same component so an override is checked with
+// a single atomic load.
+type componentLevel struct {
+	level int32
+	name  string
+}
+
+func (c *componentLevel) get() (Level, bool) {
+	l := atomic.LoadInt32(&c.level)
+	if l == noComponentLevel {
+		return 0, false
+	}
+	return Level(l), true
+}
+
+var (
+	componentsMu sync.Mutex
+	components   = map[string]*componentLevel{}
+)
+
+// lookupComponentLevel returns the componentLevel of name, or nil if the
+// component has neither been configured nor used by a logger.
+func lookupComponentLevel(name string) *componentLevel {
+	componentsMu.Lock()
+	defer componentsMu.Unlock()
+	return components[name]
+}
+
+// getComponentLevel returns the componentLevel of name, creating it if
+// needed. Only SetComponentLevel and Context.Component create entries so the
+// registry is not grown by lookups of arbitrary names.
+func getComponentLevel(name string) *componentLevel {
+	componentsMu.Lock()
+	defer componentsMu.Unlock()
+	c, found := components[name]
+	if !found {
+		c = &componentLevel{level: noComponentLevel, name: name}
+		components[name] = c
+	}
+	return c
+}
+
+// SetComponentLevel sets the minimum accepted level of the loggers created
+// with Context.Component(name). This level overrides both the level of the
+// loggers and the global level, except when the global level is Disabled.
+//
+// Component levels can be changed at any time, including after the component
+// loggers have been created.
+func SetComponentLevel(name string, l Level) {
+	atomic.StoreInt32(&getComponentLevel(name).level, int32(l))
+}
+
+// UnsetComponentLevel removes the level override of the component name.
+func UnsetComponentLevel(name string) {
+	if c := lookupComponentLevel(name); c != nil {
+		atomic.StoreInt32(&c.level, noComponentLevel)
+	}
+}
+
+// ComponentLevel returns the level override of the component name and true,
+// or false if the component has no override.
+func ComponentLevel(name string) (Level, bool) {
+	c := lookupComponentLevel(name)
+	if c == nil {
+		return 0, false
+	}
+	return c.get()
+}
+
+// ComponentLevels returns the level of all the components with an override.
+func ComponentLevels() map[string]Level {
+	componentsMu.Lock()
+	defer componentsMu.Unlock()
+	levels := make(map[string]Level, len(components))
+	for name, c := range components {
+		if l, ok := c.get(); ok {
+			levels[name] = l
+		}
+	}
+	return levels
+}
@@ -0,0 +1,73 @@
+package zerolog
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestComponentLevel(t *testing.T) {
+	skipIfNoDebug(t)
+	defer UnsetComponentLevel("storage")
+	out := &bytes.Buffer{}
+	root := New(out).Level(InfoLevel)
+	storage := root.With().Component("storage").Logger()
+	other := root.With().Component("other").Logger()
+
+	storage.Debug().Msg("filtered")
+	if got, want := decodeIfBinaryToString(out.Bytes()), ""; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+
+	SetComponentLevel("storage", DebugLevel)
+	storage.Debug().Msg("routed")
+	other.Debug().Msg("filtered")
+	root.Debug().Msg("filtered")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"debug","component":"storage","message":"routed"}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+
+	if l, ok := ComponentLevel("storage"); !ok || l != DebugLevel {
+		t.Errorf("ComponentLevel() = %v, %v, want %v, true", l, ok, DebugLevel)
+	}
+	if got, want := ComponentLevels(), map[string]Level{"storage": DebugLevel}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ComponentLevels() = %v, want %v", got, want)
+	}
+
+	out.Reset()
+	UnsetComponentLevel("storage")
+	storage.Debug().Msg("filtered")
+	if got, want := decodeIfBinaryToString(out.Bytes()), ""; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+}
+
+func TestComponentLevelGlobalOverride(t *testing.T) {
+	skipIfNoDebug(t)
+	defer UnsetComponentLevel("storage")
+	defer SetGlobalLevel(TraceLevel)
+	out := &bytes.Buffer{}
+	storage := New(out).With().Component("storage").Logger()
+	SetComponentLevel("storage", DebugLevel)
+
+	SetGlobalLevel(WarnLevel)
+	storage.Debug().Msg("routed")
+	SetGlobalLevel(Disabled)
+	storage.Error().Msg("filtered")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"debug","component":"storage","message":"routed"}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+}
+
+func TestComponentLevelLookupDoesNotRegister(t *testing.T) {
+	if _, ok := ComponentLevel("never-configured"); ok {
+		t.Error("ComponentLevel() found a level for an unknown component")
+	}
+	UnsetComponentLevel("never-configured")
+	componentsMu.Lock()
+	_, found := components["never-configured"]
+	componentsMu.Unlock()
+	if found {
+		t.Error("lookups registered an unknown component")
+	}
+}
@@ -0,0 +1,189 @@
+package zerolog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Config holds the logging settings which can be loaded from a JSON file
+// with LoadConfig and reloaded on change with WatchConfig:
+//
+//     {
+//         "level": "info",
+//         "components": {"db": "debug"},
+//         "disable_sampling": false,
+//         "sampling": {"debug": 10, "info": 2},
+//         "filters": [{"field": "user_agent", "contains": "kube-probe"}]
+//     }
+//
+// The global level, component levels and DisableSampling are applied to all
+// loggers. Sampling rates and filters only apply to the loggers using
+// ConfigSampler and ConfigFilter.
+type Config struct {
+	// Level is the global level. If nil, the global level is left untouched.
+	Level *Level `json:"level"`
+	// Components maps component names to their level. Components set by a
+	// previous configuration and absent from this one are unset.
+	Components map[string]Level `json:"components"`
+	// DisableSampling disables sampling in all loggers.
+	DisableSampling bool `json:"disable_sampling"`
+	// Sampling maps levels to the sampling rate used by ConfigSampler: one
+	// event out of N is kept. Levels without rate are not sampled.
+	Sampling map[Level]uint32 `json:"sampling"`
+	// Filters defines the rules used by ConfigFilter.
+	Filters []FilterRule `json:"filters"`
+}
+
+// FilterRule matches the string field Field, if equal to Equals, containing
+// Contains or starting with Prefix. Only one condition should be set.
+type FilterRule struct {
+	Field    string `json:"field"`
+	Equals   string `json:"equals,omitempty"`
+	Contains string `json:"contains,omitempty"`
+	Prefix   string `json:"prefix,omitempty"`
+}
+
+func (r FilterRule) filter() FilterFunc {
+	switch {
+	case r.Contains != "":
+		return FieldContains(r.Field, r.Contains)
+	case r.Prefix != "":
+		return FieldHasPrefix(r.Field, r.Prefix)
+	}
+	return FieldEquals(r.Field, r.Equals)
+}
+
+// configState is the state of the last applied Config used by ConfigSampler
+// and ConfigFilter.
+type configState struct {
+	samplers map[Level]*BasicSampler
+	filters  []FilterFunc
+}
+
+var (
+	configMu         sync.Mutex
+	configComponents = map[string]bool{}
+	currentConfig    atomic.Value // *configState
+)
+
+// LoadConfig reads the JSON configuration file at path.
+func LoadConfig(path string) (Config, error) {
+	var c Config
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
+
+// Apply applies c to the global settings, ConfigSampler and ConfigFilter.
+func (c Config) Apply() {
+	configMu.Lock()
+	defer configMu.Unlock()
+	if c.Level != nil {
+		SetGlobalLevel(*c.Level)
+	}
+	for name := range configComponents {
+		if _, found := c.Components[name]; !found {
+			UnsetComponentLevel(name)
+			delete(configComponents, name)
+		}
+	}
+	for name, l := range c.Components {
+		SetComponentLevel(name, l)
+		configComponents[name] = true
+	}
+	DisableSampling(c.DisableSampling)
+	s := &configState{samplers: map[Level]*BasicSampler{}}
+	for l, n := range c.Sampling {
+		s.samplers[l] = &BasicSampler{N: n}
+	}
+	for _, r := range c.Filters {
+		s.filters = append(s.filters, r.filter())
+	}
+	currentConfig.Store(s)
+}
+
+// WatchConfig loads and applies the configuration file at path, then polls it
+// every interval and reapplies it when it changes. Errors occurring after the
+// initial load are reported to onError if not nil, and the previous
+// configuration is kept.
+//
+// Call stop to stop watching the file.
+func WatchConfig(path string, interval time.Duration, onError func(err error)) (stop func(), err error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	c.Apply()
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+			}
+			nfi, err := os.Stat(path)
+			if err == nil && nfi.ModTime().Equal(fi.ModTime()) && nfi.Size() == fi.Size() {
+				continue
+			}
+			if err == nil {
+				fi = nfi
+				c, err = LoadConfig(path)
+			}
+			if err != nil {
+				if onError != nil {
+					onError(err)
+				}
+				continue
+			}
+			c.Apply()
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }, nil
+}
+
+// ConfigSampler samples events using the rates of the last applied Config.
+var ConfigSampler Sampler = configSampler{}
+
+type configSampler struct{}
+
+// Sample implements the Sampler interface.
+func (configSampler) Sample(lvl Level) bool {
+	s, _ := currentConfig.Load().(*configState)
+	if s == nil {
+		return true
+	}
+	if bs := s.samplers[lvl]; bs != nil {
+		return bs.Sample(lvl)
+	}
+	return true
+}
+
+// ConfigFilter filters events using the rules of the last applied Config.
+// Rule changes do not apply to sub-loggers already muted by a context field.
+func ConfigFilter(key, val string) bool {
+	s, _ := currentConfig.Load().(*configState)
+	if s == nil {
+		return false
+	}
+	for _, f := range s.filters {
+		if f(key, val) {
+			return true
+		}
+	}
+	return false
+}
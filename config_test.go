@@ -0,0 +1,93 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigApply(t *testing.T) {
+	defer SetGlobalLevel(GlobalLevel())
+	defer Config{}.Apply()
+	out := &bytes.Buffer{}
+	log := New(out).Sample(ConfigSampler).Filter(ConfigFilter)
+
+	c := Config{}
+	if err := json.Unmarshal([]byte(`{
+		"level": "info",
+		"components": {"config-db": "debug"},
+		"sampling": {"warn": 2},
+		"filters": [{"field": "path", "equals": "/healthz"}]
+	}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	c.Apply()
+	if got := GlobalLevel(); got != InfoLevel {
+		t.Errorf("invalid global level: %v", got)
+	}
+	if l, ok := ComponentLevel("config-db"); !ok || l != DebugLevel {
+		t.Errorf("invalid component level: %v, %v", l, ok)
+	}
+	log.Debug().Msg("filtered")
+	log.Info().Str("path", "/healthz").Msg("filtered")
+	for i := 0; i < 4; i++ {
+		log.Warn().Int("i", i).Msg("")
+	}
+	want := `{"level":"warn","i":0}` + "\n" + `{"level":"warn","i":2}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	Config{}.Apply()
+	if _, ok := ComponentLevel("config-db"); ok {
+		t.Error("component level not unset")
+	}
+	out.Reset()
+	log.Info().Str("path", "/healthz").Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","path":"/healthz"}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	defer SetGlobalLevel(GlobalLevel())
+	defer Config{}.Apply()
+	dir, err := ioutil.TempDir("", "zerolog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.json")
+	if err := ioutil.WriteFile(path, []byte(`{"level":"warn"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stop, err := WatchConfig(path, 10*time.Millisecond, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	if got := GlobalLevel(); got != WarnLevel {
+		t.Errorf("invalid global level: %v", got)
+	}
+	if err := ioutil.WriteFile(path, []byte(`{"level":"error"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Make sure the modification time changes on coarse grained file systems.
+	if err := os.Chtimes(path, time.Now(), time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100 && GlobalLevel() != ErrorLevel; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := GlobalLevel(); got != ErrorLevel {
+		t.Errorf("config not reloaded: %v", got)
+	}
+
+	if _, err := WatchConfig(filepath.Join(dir, "missing.json"), time.Second, nil); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,437 @@
+package zerolog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Color is an ANSI SGR color code used by ConsoleWriter themes. The zero
+// value means no color.
+type Color int
+
+const (
+	// ColorNone disables coloring.
+	ColorNone Color = 0
+	// ColorBold renders text in bold.
+	ColorBold Color = 1
+	// ColorBlack renders text in black.
+	ColorBlack Color = iota + 28
+	// ColorRed renders text in red.
+	ColorRed
+	// ColorGreen renders text in green.
+	ColorGreen
+	// ColorYellow renders text in yellow.
+	ColorYellow
+	// ColorBlue renders text in blue.
+	ColorBlue
+	// ColorMagenta renders text in magenta.
+	ColorMagenta
+	// ColorCyan renders text in cyan.
+	ColorCyan
+	// ColorWhite renders text in white.
+	ColorWhite
+	// ColorDarkGray renders text in dark gray.
+	ColorDarkGray Color = 90
+)
+
+// ConsoleTheme defines the colors used by the default ConsoleWriter
+// formatters.
+type ConsoleTheme struct {
+	// Levels maps level names (as found in the level field) to a color.
+	Levels map[string]Color
+
+	Timestamp     Color
+	Caller        Color
+	Message       Color
+	FieldName     Color
+	ErrFieldName  Color
+	ErrFieldValue Color
+}
+
+// DefaultConsoleTheme is the theme used by ConsoleWriter when none is set.
+var DefaultConsoleTheme = ConsoleTheme{
+	Levels: map[string]Color{
+		"trace": ColorBlue,
+		"debug": ColorMagenta,
+		"info":  ColorGreen,
+		"warn":  ColorYellow,
+		"error": ColorRed,
+		"fatal": ColorRed,
+		"panic": ColorRed,
+	},
+	Timestamp:     ColorDarkGray,
+	Caller:        ColorBold,
+	Message:       ColorNone,
+	FieldName:     ColorCyan,
+	ErrFieldName:  ColorRed,
+	ErrFieldValue: ColorRed,
+}
+
+// Formatter transforms the value of an event part or field into the string
+// written by ConsoleWriter.
+type Formatter func(interface{}) string
+
+var consoleBufPool = sync.Pool{
+	New: func() interface{} {
+		return bytes.NewBuffer(make([]byte, 0, 100))
+	},
+}
+
+// ConsoleWriter parses the JSON input and writes it in a human friendly,
+// optionally colorized, format to Out. With the zerolog_bson build tag, the
+// BSON events are transcoded to JSON first.
+//
+// Each part of the line (timestamp, level, caller, message, field names and
+// values) is rendered by a Formatter. Unset formatters fall back to a default
+// implementation using Theme for colors.
+//
+// ConsoleWriter scans the top-level fields of each event without decoding
+// them and the default formatters write the fields directly to a pooled
+// buffer, so an event is written without allocating in most cases. Custom
+// formatters get the decoded values, with the nested objects and arrays
+// passed as json.RawMessage. ConsoleWriter is still meant for development
+// and should not be used where performance matters.
+type ConsoleWriter struct {
+	// Out is the output destination.
+	Out io.Writer
+
+	// NoColor disables the colorized output.
+	NoColor bool
+
+	// TimeFormat specifies the format for the timestamp in output
+	// (default: time.Kitchen).
+	TimeFormat string
+
+	// Theme defines the colors used by default formatters. If nil,
+	// DefaultConsoleTheme is used.
+	Theme *ConsoleTheme
+
+	// PartsOrder defines the order of the parts written before the fields
+	// (default: timestamp, level, caller and message field names).
+	PartsOrder []string
+
+	FormatTimestamp     Formatter
+	FormatLevel         Formatter
+	FormatCaller        Formatter
+	FormatMessage       Formatter
+	FormatFieldName     Formatter
+	FormatFieldValue    Formatter
+	FormatErrFieldName  Formatter
+	FormatErrFieldValue Formatter
+}
+
+// Write transforms the JSON input with formatters and appends to w.Out.
+func (w ConsoleWriter) Write(p []byte) (n int, err error) {
+	fp := consoleFieldsPool.Get().(*consoleFields)
+	defer func() {
+		for i := range *fp {
+			(*fp)[i] = consoleField{}
+		}
+		*fp = (*fp)[:0]
+		consoleFieldsPool.Put(fp)
+	}()
+	// The BSON events are transcoded to JSON first.
+	evt, err := scanFields((*fp)[:0], decodeIfBinaryToBytes(p))
+	*fp = evt
+	if err != nil {
+		return n, fmt.Errorf("cannot decode event: %s", err)
+	}
+
+	buf := consoleBufPool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		consoleBufPool.Put(buf)
+	}()
+
+	for _, part := range w.partsOrder() {
+		w.writePart(buf, evt, part)
+	}
+	w.writeFields(buf, evt)
+	buf.WriteByte('\n')
+
+	if _, err = buf.WriteTo(w.Out); err != nil {
+		return n, err
+	}
+	return len(p), nil
+}
+
+// writePart appends a formatted part of the event to buf.
+func (w ConsoleWriter) writePart(buf *bytes.Buffer, evt consoleFields, part string) {
+	field, _ := evt.lookup(part)
+	n := buf.Len()
+	if n > 0 {
+		buf.WriteByte(' ')
+	}
+	theme := w.theme()
+	switch part {
+	case TimestampFieldName:
+		if w.FormatTimestamp != nil {
+			buf.WriteString(w.FormatTimestamp(field.value()))
+		} else {
+			w.writeTimestamp(buf, field.raw, theme.Timestamp)
+		}
+	case LevelFieldName:
+		if w.FormatLevel != nil {
+			buf.WriteString(w.FormatLevel(field.value()))
+		} else {
+			w.writeLevel(buf, field, theme)
+		}
+	case CallerFieldName:
+		if w.FormatCaller != nil {
+			buf.WriteString(w.FormatCaller(field.value()))
+		} else if len(field.raw) > 2 && field.raw[0] == '"' {
+			on := w.colorStart(buf, theme.Caller)
+			buf.Write(unquoteJSONBytes(field.raw))
+			colorEnd(buf, on)
+			on = w.colorStart(buf, ColorCyan)
+			buf.WriteString(" >")
+			colorEnd(buf, on)
+		}
+	case MessageFieldName:
+		if w.FormatMessage != nil {
+			buf.WriteString(w.FormatMessage(field.value()))
+		} else if len(field.raw) > 0 && field.raw[0] == '"' {
+			on := w.colorStart(buf, theme.Message)
+			buf.Write(unquoteJSONBytes(field.raw))
+			colorEnd(buf, on)
+		} else if len(field.raw) > 0 && field.raw[0] != 'n' {
+			buf.WriteString(colorize(field.value(), theme.Message, w.NoColor))
+		}
+	default:
+		if w.FormatFieldValue != nil {
+			buf.WriteString(w.FormatFieldValue(field.value()))
+		} else {
+			writeFieldValue(buf, field.raw)
+		}
+	}
+	if buf.Len() == n+1 {
+		// Nothing was written but the separator.
+		buf.Truncate(n)
+	}
+}
+
+// writeTimestamp writes the timestamp raw, a JSON string formatted with
+// TimeFieldFormat or a UNIX time, formatted with TimeFormat.
+func (w ConsoleWriter) writeTimestamp(buf *bytes.Buffer, raw []byte, c Color) {
+	timeFormat := w.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.Kitchen
+	}
+	var scratch [64]byte
+	on := w.colorStart(buf, c)
+	switch {
+	case len(raw) == 0:
+		buf.WriteString("<nil>")
+	case raw[0] == '"':
+		s := unquoteJSONBytes(raw)
+		// The string is not retained past the call.
+		if t, err := time.Parse(TimeFieldFormat, bytesString(s)); err == nil {
+			buf.Write(t.AppendFormat(scratch[:0], timeFormat))
+		} else {
+			buf.Write(s)
+		}
+	case raw[0] == '-' || (raw[0] >= '0' && raw[0] <= '9'):
+		if i, err := strconv.ParseInt(bytesString(raw), 10, 64); err == nil {
+			buf.Write(time.Unix(i, 0).AppendFormat(scratch[:0], timeFormat))
+		} else {
+			buf.Write(raw)
+		}
+	default:
+		buf.WriteString("<nil>")
+	}
+	colorEnd(buf, on)
+}
+
+// writeLevel writes the level of field abbreviated to three letters.
+func (w ConsoleWriter) writeLevel(buf *bytes.Buffer, field consoleField, theme *ConsoleTheme) {
+	if len(field.raw) == 0 || field.raw[0] == 'n' {
+		on := w.colorStart(buf, ColorBold)
+		buf.WriteString("???")
+		colorEnd(buf, on)
+		return
+	}
+	if field.raw[0] != '"' {
+		buf.WriteString(strings.ToUpper(fmt.Sprintf("%s", field.value())))
+		return
+	}
+	ll := unquoteJSONBytes(field.raw)
+	var l string
+	switch string(ll) {
+	case "trace":
+		l = "TRC"
+	case "debug":
+		l = "DBG"
+	case "info":
+		l = "INF"
+	case "warn":
+		l = "WRN"
+	case "error":
+		l = "ERR"
+	case "fatal":
+		l = "FTL"
+	case "panic":
+		l = "PNC"
+	default:
+		l = strings.ToUpper(string(ll))
+		if len(l) > 3 {
+			l = l[0:3]
+		}
+	}
+	on := w.colorStart(buf, theme.Levels[string(ll)])
+	buf.WriteString(l)
+	colorEnd(buf, on)
+}
+
+// writeFields appends the fields not part of PartsOrder to buf, sorted by
+// name. The error field is always written first.
+func (w ConsoleWriter) writeFields(buf *bytes.Buffer, evt consoleFields) {
+	theme := w.theme()
+	if !w.isPart(ErrorFieldName) {
+		if field, ok := evt.lookup(ErrorFieldName); ok {
+			if buf.Len() > 0 {
+				buf.WriteByte(' ')
+			}
+			w.writeFieldName(buf, w.FormatErrFieldName, field.key, theme.ErrFieldName)
+			if w.FormatErrFieldValue != nil {
+				buf.WriteString(w.FormatErrFieldValue(field.value()))
+			} else {
+				on := w.colorStart(buf, theme.ErrFieldValue)
+				writeFieldValue(buf, field.raw)
+				colorEnd(buf, on)
+			}
+		}
+	}
+	for _, field := range evt {
+		if key := bytesString(field.key); key == ErrorFieldName || w.isPart(key) {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		w.writeFieldName(buf, w.FormatFieldName, field.key, theme.FieldName)
+		if w.FormatFieldValue != nil {
+			buf.WriteString(w.FormatFieldValue(field.value()))
+		} else {
+			writeFieldValue(buf, field.raw)
+		}
+	}
+}
+
+// writeFieldName writes key with f or, if f is nil, followed by '='.
+func (w ConsoleWriter) writeFieldName(buf *bytes.Buffer, f Formatter, key []byte, c Color) {
+	if f != nil {
+		buf.WriteString(f(string(key)))
+		return
+	}
+	on := w.colorStart(buf, c)
+	buf.Write(key)
+	buf.WriteByte('=')
+	colorEnd(buf, on)
+}
+
+// writeFieldValue writes the JSON value raw, with the strings only quoted
+// when ambiguous, and null if raw is empty.
+func writeFieldValue(buf *bytes.Buffer, raw []byte) {
+	switch {
+	case len(raw) == 0:
+		buf.WriteString("null")
+	case raw[0] == '"':
+		s := unquoteJSONBytes(raw)
+		if !needsQuote(bytesString(s)) {
+			buf.Write(s)
+			return
+		}
+		var scratch [64]byte
+		buf.Write(strconv.AppendQuote(scratch[:0], bytesString(s)))
+	case raw[0] == '{' || raw[0] == '[':
+		writeCompactJSON(buf, raw)
+	default:
+		// Numbers, booleans and null are written as is.
+		buf.Write(raw)
+	}
+}
+
+var consolePartsOrder = []string{
+	TimestampFieldName,
+	LevelFieldName,
+	CallerFieldName,
+	MessageFieldName,
+}
+
+func (w ConsoleWriter) partsOrder() []string {
+	if w.PartsOrder != nil {
+		return w.PartsOrder
+	}
+	return consolePartsOrder
+}
+
+func (w ConsoleWriter) isPart(field string) bool {
+	for _, part := range w.partsOrder() {
+		if part == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (w ConsoleWriter) theme() *ConsoleTheme {
+	if w.Theme != nil {
+		return w.Theme
+	}
+	return &DefaultConsoleTheme
+}
+
+// colorStart writes the ANSI code c to buf and returns true, unless NoColor
+// is true or c is ColorNone.
+func (w ConsoleWriter) colorStart(buf *bytes.Buffer, c Color) bool {
+	if w.NoColor || c == ColorNone {
+		return false
+	}
+	var scratch [8]byte
+	buf.WriteString("\x1b[")
+	buf.Write(strconv.AppendInt(scratch[:0], int64(c), 10))
+	buf.WriteByte('m')
+	return true
+}
+
+// colorEnd resets the color started by colorStart if on is true.
+func colorEnd(buf *bytes.Buffer, on bool) {
+	if on {
+		buf.WriteString("\x1b[0m")
+	}
+}
+
+// colorize returns the string s wrapped in ANSI code c, unless disabled is
+// true or c is ColorNone.
+func colorize(s interface{}, c Color, disabled bool) string {
+	str, ok := s.(string)
+	if disabled || c == ColorNone {
+		if ok {
+			return str
+		}
+		return fmt.Sprintf("%s", s)
+	}
+	if !ok {
+		str = fmt.Sprint(s)
+	}
+	return "\x1b[" + strconv.Itoa(int(c)) + "m" + str + "\x1b[0m"
+}
+
+// needsQuote returns true when the string s would be ambiguous if written
+// unquoted in the console output.
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e || s[i] == ' ' || s[i] == '\\' || s[i] == '"' {
+			return true
+		}
+	}
+	return false
+}
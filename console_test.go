@@ -0,0 +1,145 @@
+package zerolog_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func ExampleConsoleWriter() {
+	log := zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout, NoColor: true})
+
+	log.Info().Str("foo", "bar").Msg("Hello World")
+	// Output: <nil> INF Hello World foo=bar
+}
+
+func ExampleConsoleWriter_customFormatters() {
+	out := zerolog.ConsoleWriter{Out: os.Stdout, NoColor: true}
+	out.FormatLevel = func(i interface{}) string { return strings.ToUpper(fmt.Sprintf("%-6s|", i)) }
+	out.FormatFieldName = func(i interface{}) string { return fmt.Sprintf("%s:", i) }
+	out.FormatFieldValue = func(i interface{}) string { return strings.ToUpper(fmt.Sprintf("%s", i)) }
+	log := zerolog.New(out)
+
+	log.Info().Str("foo", "bar").Msg("Hello World")
+	// Output: <nil> INFO  | Hello World foo:BAR
+}
+
+func TestConsoleWriter(t *testing.T) {
+	t.Run("Default field formatter", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, PartsOrder: []string{"foo"}}
+
+		_, err := w.Write([]byte(`{"foo": "DEFAULT"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		if got, want := buf.String(), "DEFAULT\n"; got != want {
+			t.Errorf("Unexpected output %q, want: %q", got, want)
+		}
+	})
+
+	t.Run("Write colorized", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: false}
+
+		_, err := w.Write([]byte(`{"level": "warn", "message": "Foobar"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		if got, want := buf.String(), "\x1b[90m<nil>\x1b[0m \x1b[33mWRN\x1b[0m Foobar\n"; got != want {
+			t.Errorf("Unexpected output %q, want: %q", got, want)
+		}
+	})
+
+	t.Run("Write custom theme", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		theme := zerolog.ConsoleTheme{
+			Levels:    map[string]zerolog.Color{"warn": zerolog.ColorBlue},
+			FieldName: zerolog.ColorGreen,
+		}
+		w := zerolog.ConsoleWriter{Out: buf, Theme: &theme, PartsOrder: []string{"level", "message"}}
+
+		_, err := w.Write([]byte(`{"level": "warn", "message": "Foobar", "foo": "bar"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		if got, want := buf.String(), "\x1b[34mWRN\x1b[0m Foobar \x1b[32mfoo=\x1b[0mbar\n"; got != want {
+			t.Errorf("Unexpected output %q, want: %q", got, want)
+		}
+	})
+
+	t.Run("Write fields", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true}
+
+		d := time.Unix(0, 0).UTC().Format(time.RFC3339)
+		_, err := w.Write([]byte(`{"time": "` + d + `", "level": "debug", "message": "Foobar", "foo": "bar", "n": 1, "error": "boom"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		if got, want := buf.String(), "12:00AM DBG Foobar error=boom foo=bar n=1\n"; got != want {
+			t.Errorf("Unexpected output %q, want: %q", got, want)
+		}
+	})
+
+	t.Run("Write caller", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true}
+
+		_, err := w.Write([]byte(`{"level": "info", "caller": "foo.go:42", "message": "Foobar"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		if got, want := buf.String(), "<nil> INF foo.go:42 > Foobar\n"; got != want {
+			t.Errorf("Unexpected output %q, want: %q", got, want)
+		}
+	})
+
+	t.Run("Quote values", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, PartsOrder: []string{}}
+
+		_, err := w.Write([]byte(`{"foo": "bar baz", "obj": {"a": 1}}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		if got, want := buf.String(), `foo="bar baz" obj={"a":1}`+"\n"; got != want {
+			t.Errorf("Unexpected output %q, want: %q", got, want)
+		}
+	})
+
+	t.Run("Scan values", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, PartsOrder: []string{"message"}}
+
+		_, err := w.Write([]byte(`{"message": "a\tb \"c\"", "b": true, "n": null, "arr": [ {"x": "]}"}, 2 ], "a": 1.5e3, "a": 2}` + "\n"))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		if got, want := buf.String(), "a\tb \"c\" a=2 arr=[{\"x\":\"]}\"},2] b=true n=null\n"; got != want {
+			t.Errorf("Unexpected output %q, want: %q", got, want)
+		}
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		w := zerolog.ConsoleWriter{Out: &bytes.Buffer{}}
+		if _, err := w.Write([]byte(`{"foo"`)); err == nil {
+			t.Error("Expected error on invalid input")
+		}
+		if _, err := w.Write([]byte(`["foo"]`)); err == nil {
+			t.Error("Expected error on non-object input")
+		}
+	})
+}
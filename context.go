@@ -1,6 +1,10 @@
 package zerolog
 
-import "time"
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
 
 // Context configures a new sub-logger with contextual fields.
 type Context struct {
@@ -12,17 +16,113 @@ func (c Context) Logger() Logger {
 	return c.l
 }
 
+// Level raises the minimum accepted level of the sub-logger to lvl. Unlike
+// Logger.Level, the level is clamped: if lvl is below the level of the parent
+// logger, the parent level is kept, so a sub-logger can be made less verbose
+// than its parent but never more. The global level still applies on top.
+//
+// Context level overrides and component levels cannot lower the level of the
+// sub-logger below lvl either.
+func (c Context) Level(lvl Level) Context {
+	if lvl > c.l.level {
+		c.l.level = lvl
+	}
+	if c.l.floor == nil || lvl > *c.l.floor {
+		c.l.floor = &lvl
+	}
+	return c
+}
+
+// Ctx adds the context.Context ctx to the logger. The context is not
+// serialized: it is passed to the events of the logger so hooks can retrieve
+// it with Event.GetCtx.
+func (c Context) Ctx(ctx context.Context) Context {
+	c.l.ctx = ctx
+	return c
+}
+
+// Component adds the field zerolog.ComponentFieldName with name to the logger
+// context and binds the logger to the component's level override set with
+// SetComponentLevel.
+func (c Context) Component(name string) Context {
+	c.l.context = appendString(c.l.context, ComponentFieldName, name)
+	c.l.component = getComponentLevel(name)
+	return c
+}
+
 // Dict adds the field key with the dict to the logger context.
 func (c Context) Dict(key string, dict *Event) Context {
-	dict.buf = append(dict.buf, '}')
-	c.l.context = append(appendKey(c.l.context, key), dict.buf...)
-	eventPool.Put(dict)
+	c.l.context = appendObject(c.l.context, key, dict.buf)
+	putEvent(dict)
 	return c
 }
 
+// Object adds the field key with the fields of obj as a dict to the logger
+// context. If obj is nil, no field is added.
+func (c Context) Object(key string, obj LogObjectMarshaler) Context {
+	if obj == nil {
+		return c
+	}
+	d := Dict()
+	obj.MarshalZerologObject(d)
+	return c.Dict(key, d)
+}
+
 // Str adds the field key with val as a string to the logger context.
+//
+// If the logger is sampled by a KeySampler with key as Field, the sampling
+// decision for the entity val is bound to the logger. If the field matches
+// one of the logger's filters, the logger is muted.
 func (c Context) Str(key, val string) Context {
 	c.l.context = appendString(c.l.context, key, val)
+	for _, f := range c.l.filters {
+		if f(key, val) {
+			c.l.filtered = true
+		}
+	}
+	if ks, ok := c.l.sampler.(KeySampler); ok && key == ks.Field {
+		c.l.sampler = fixedSampler(ks.Keep(val))
+	}
+	return c
+}
+
+// StrUnsafe adds the field key with val as a string to the logger context,
+// like Str, but without escaping nor validating val: val must be valid
+// UTF-8 and must not contain control characters, double quotes nor
+// backslashes, or the events are invalid JSON.
+func (c Context) StrUnsafe(key, val string) Context {
+	c.l.context = appendStringUnsafe(c.l.context, key, val)
+	for _, f := range c.l.filters {
+		if f(key, val) {
+			c.l.filtered = true
+		}
+	}
+	if ks, ok := c.l.sampler.(KeySampler); ok && key == ks.Field {
+		c.l.sampler = fixedSampler(ks.Keep(val))
+	}
+	return c
+}
+
+// Bytes adds the field key with val as a string to the logger context.
+func (c Context) Bytes(key string, val []byte) Context {
+	return c.Str(key, string(val))
+}
+
+// Base64 adds the field key with val encoded with Base64Encoding as a string
+// to the logger context.
+func (c Context) Base64(key string, val []byte) Context {
+	c.l.context = appendBase64(c.l.context, key, val)
+	return c
+}
+
+// RawJSON adds the field key with b, an already encoded JSON value, to the
+// logger context. If RawJSONValidation is true and b is invalid, it is added
+// as a string.
+func (c Context) RawJSON(key string, b []byte) Context {
+	if RawJSONValidation && !json.Valid(b) {
+		return c.Bytes(key, b)
+	}
+	c.l.context = appendRawJSON(c.l.context, key, b)
 	return c
 }
 
@@ -117,11 +217,21 @@ func (c Context) Float64(key string, f float64) Context {
 	return c
 }
 
+// Caller adds the file:line of the caller of Msg to the events of the
+// logger with the zerolog.CallerFieldName key.
+func (c Context) Caller() Context {
+	c.l = c.l.Hook(callerHook{})
+	return c
+}
+
 // Timestamp adds the current local time as UNIX timestamp to the logger context with the "time" key.
 // To customize the key name, change zerolog.TimestampFieldName.
 func (c Context) Timestamp() Context {
 	if len(c.l.context) > 0 {
-		c.l.context[0] = 1
+		if c.l.context[0] == 0 {
+			// Copy as the context may be shared with the parent logger.
+			c.l.context = append([]byte{1}, c.l.context[1:]...)
+		}
 	} else {
 		c.l.context = append(c.l.context, 1)
 	}
@@ -0,0 +1,49 @@
+// Package chihlog provides a chi middleware for zerolog, complementing the
+// hlog helpers, which can be used as is with chi, with the route pattern of
+// the requests.
+//
+// The middleware is meant to be used with the hlog ones, in this order:
+//
+//     r := chi.NewRouter()
+//     r.Use(
+//         hlog.NewHandler(log.Logger),
+//         hlog.RequestIDHandler("request_id", "X-Request-Id"),
+//         chihlog.RouteHandler(),
+//         hlog.AccessHandler(),
+//     )
+package chihlog
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+)
+
+// RouteHandler returns a middleware adding the route pattern of the
+// requests, like "/users/{id}", to the events of the request logger using
+// hlog.RouteFieldName, including the access logs of hlog.AccessHandler.
+//
+// Chi builds the pattern while routing the request thru its sub-routers, so
+// the middlewares run before the pattern is complete. The pattern is thus
+// added when the events are logged rather than when the middleware runs,
+// which lets RouteHandler be used at any position of the middleware stack.
+func RouteHandler() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rctx := chi.RouteContext(r.Context())
+			if rctx == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := r.Context()
+			l := zerolog.Ctx(ctx).Hook(zerolog.HookFunc(func(e *zerolog.Event, level zerolog.Level, msg string) {
+				if p := rctx.RoutePattern(); p != "" {
+					e.Str(hlog.RouteFieldName, p)
+				}
+			}))
+			next.ServeHTTP(w, r.WithContext(l.WithContext(ctx)))
+		})
+	}
+}
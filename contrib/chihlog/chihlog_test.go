@@ -0,0 +1,54 @@
+package chihlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+)
+
+func decodeLines(t *testing.T, b []byte) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, l := range bytes.Split(bytes.TrimSpace(b), []byte("\n")) {
+		var m map[string]interface{}
+		if err := json.Unmarshal(l, &m); err != nil {
+			t.Fatalf("invalid log line %q: %v", l, err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestHandlers(t *testing.T) {
+	out := &bytes.Buffer{}
+	r := chi.NewRouter()
+	r.Use(hlog.NewHandler(zerolog.New(out)), RouteHandler(), hlog.AccessHandler())
+	r.Route("/users", func(r chi.Router) {
+		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			hlog.FromRequest(r).Info().Msg("handling")
+			w.WriteHeader(http.StatusTeapot)
+			w.Write([]byte("tea"))
+		})
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+
+	lines := decodeLines(t, out.Bytes())
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2: %s", len(lines), out)
+	}
+	if lines[0]["message"] != "handling" || lines[0]["route"] != "/users/{id}" {
+		t.Errorf("invalid handler log: %v", lines[0])
+	}
+	for k, v := range map[string]interface{}{"level": "warn", "method": "GET", "url": "/users/42", "route": "/users/{id}", "status": 418.0, "size": 3.0} {
+		if lines[1][k] != v {
+			t.Errorf("access log %s = %v, want %v", k, lines[1][k], v)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Package echohlog provides echo middlewares for zerolog, mirroring the hlog
+// helpers for net/http.
+//
+// The middlewares are meant to be used together, in this order:
+//
+//     e := echo.New()
+//     e.Use(
+//         echohlog.NewHandler(log.Logger),
+//         echohlog.RequestIDHandler(),
+//         echohlog.AccessHandler(),
+//     )
+package echohlog
+
+import (
+	"errors"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+)
+
+// requestIDKey is the echo context key of the request id.
+const requestIDKey = "github.com/rs/zerolog/contrib/echohlog.requestID"
+
+// FromContext returns the logger of the request of c.
+func FromContext(c echo.Context) zerolog.Logger {
+	return zerolog.Ctx(c.Request().Context())
+}
+
+// IDFromContext returns the request id of c, as set by RequestIDHandler.
+func IDFromContext(c echo.Context) (id string, ok bool) {
+	id, ok = c.Get(requestIDKey).(string)
+	return
+}
+
+// NewHandler returns a middleware injecting l in the context of the
+// requests, retrievable with FromContext or hlog.FromRequest.
+func NewHandler(l zerolog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			c.SetRequest(r.WithContext(l.WithContext(r.Context())))
+			return next(c)
+		}
+	}
+}
+
+// RequestIDHandler returns a middleware reading the request id from the
+// X-Request-Id header, or generating one, adding it to the request logger
+// and setting it in the response header.
+func RequestIDHandler() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			ctx := r.Context()
+			l, id := hlog.RequestIDLogger(zerolog.Ctx(ctx), r.Header.Get(echo.HeaderXRequestID))
+			c.Set(requestIDKey, id)
+			c.SetRequest(r.WithContext(l.WithContext(ctx)))
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			return next(c)
+		}
+	}
+}
+
+// AccessHandler returns a middleware logging the requests once handled with
+// hlog.AccessEvent, adding their route template and client IP.
+//
+// Errors returned by the handlers are sent to the echo error handler, so the
+// logged status is the status of the response, and logged. The message of an *echo.HTTPError is logged rather than the
+// error itself, unless it wraps an internal error.
+func AccessHandler() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			r, res := c.Request(), c.Response()
+			e := hlog.AccessEvent(FromContext(c), r.Method, r.URL.String(), c.Path(), res.Status, res.Size, start)
+			if !e.Enabled() {
+				return nil
+			}
+			e.Str(hlog.RemoteIPFieldName, c.RealIP())
+			var he *echo.HTTPError
+			if errors.As(err, &he) {
+				if he.Internal != nil {
+					e.Err(he.Internal)
+				} else {
+					e.Interface(zerolog.ErrorFieldName, he.Message)
+				}
+			} else {
+				e.Err(err)
+			}
+			e.Msg("")
+			return nil
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package echohlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+)
+
+func decodeLines(t *testing.T, b []byte) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, l := range bytes.Split(bytes.TrimSpace(b), []byte("\n")) {
+		var m map[string]interface{}
+		if err := json.Unmarshal(l, &m); err != nil {
+			t.Fatalf("invalid log line %q: %v", l, err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestHandlers(t *testing.T) {
+	out := &bytes.Buffer{}
+	e := echo.New()
+	e.Use(NewHandler(zerolog.New(out)), RequestIDHandler(), AccessHandler())
+	e.GET("/users/:id", func(c echo.Context) error {
+		if id, _ := IDFromContext(c); id != "abc" {
+			t.Errorf("IDFromContext() = %q, want abc", id)
+		}
+		FromContext(c).Info().Msg("handling")
+		return echo.NewHTTPError(http.StatusNotFound, "no such user")
+	})
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set(echo.HeaderXRequestID, "abc")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+	if got := w.Header().Get(echo.HeaderXRequestID); got != "abc" {
+		t.Errorf("response request id = %q, want abc", got)
+	}
+
+	lines := decodeLines(t, out.Bytes())
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2: %s", len(lines), out)
+	}
+	if lines[0]["message"] != "handling" || lines[0]["request_id"] != "abc" {
+		t.Errorf("invalid handler log: %v", lines[0])
+	}
+	for k, v := range map[string]interface{}{"level": "warn", "method": "GET", "url": "/users/42", "route": "/users/:id", "status": 404.0, "request_id": "abc", "error": "no such user"} {
+		if lines[1][k] != v {
+			t.Errorf("access log %s = %v, want %v", k, lines[1][k], v)
+		}
+	}
+}
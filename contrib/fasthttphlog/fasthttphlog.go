@@ -0,0 +1,87 @@
+// Package fasthttphlog provides fasthttp middlewares for zerolog, mirroring
+// the hlog helpers for net/http.
+//
+// The middlewares wrap a fasthttp.RequestHandler and are meant to be used
+// together, in this order:
+//
+//     h := fasthttphlog.NewHandler(log.Logger)(
+//         fasthttphlog.RequestIDHandler()(
+//             fasthttphlog.AccessHandler()(handler)))
+//     fasthttp.ListenAndServe(":8080", h)
+package fasthttphlog
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+	"github.com/valyala/fasthttp"
+)
+
+type loggerKey struct{}
+
+type idKey struct{}
+
+// Middleware wraps a fasthttp.RequestHandler.
+type Middleware func(next fasthttp.RequestHandler) fasthttp.RequestHandler
+
+// FromCtx returns the logger of the request of ctx, or a disabled logger if
+// none was injected with NewHandler.
+func FromCtx(ctx *fasthttp.RequestCtx) zerolog.Logger {
+	if l, ok := ctx.UserValue(loggerKey{}).(*zerolog.Logger); ok {
+		return *l
+	}
+	return zerolog.Nop()
+}
+
+// IDFromCtx returns the request id of ctx, as set by RequestIDHandler.
+func IDFromCtx(ctx *fasthttp.RequestCtx) (id string, ok bool) {
+	id, ok = ctx.UserValue(idKey{}).(string)
+	return
+}
+
+// NewHandler returns a middleware injecting l in the requests, retrievable
+// with FromCtx.
+func NewHandler(l zerolog.Logger) Middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			l := l
+			ctx.SetUserValue(loggerKey{}, &l)
+			next(ctx)
+		}
+	}
+}
+
+// RequestIDHandler returns a middleware reading the request id from the
+// hlog.RequestIDHeader header, or generating one, adding it to the request
+// logger and setting it in the response header.
+func RequestIDHandler() Middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			l, id := hlog.RequestIDLogger(FromCtx(ctx), string(ctx.Request.Header.Peek(hlog.RequestIDHeader)))
+			ctx.SetUserValue(idKey{}, id)
+			if lp, ok := ctx.UserValue(loggerKey{}).(*zerolog.Logger); ok {
+				*lp = l
+			}
+			ctx.Response.Header.Set(hlog.RequestIDHeader, id)
+			next(ctx)
+		}
+	}
+}
+
+// AccessHandler returns a middleware logging the requests once handled with
+// hlog.AccessEvent, adding their client IP.
+func AccessHandler() Middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			start := time.Now()
+			next(ctx)
+			e := hlog.AccessEvent(FromCtx(ctx), string(ctx.Method()), string(ctx.RequestURI()), "",
+				ctx.Response.StatusCode(), int64(len(ctx.Response.Body())), start)
+			if !e.Enabled() {
+				return
+			}
+			e.Str(hlog.RemoteIPFieldName, ctx.RemoteIP().String()).Msg("")
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package fasthttphlog
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+	"github.com/valyala/fasthttp"
+)
+
+func init() {
+	zerolog.DurationFieldUnit = time.Hour
+	zerolog.DurationFieldInteger = true
+}
+
+func TestHandlers(t *testing.T) {
+	out := &bytes.Buffer{}
+	h := NewHandler(zerolog.New(out))(RequestIDHandler()(AccessHandler()(func(ctx *fasthttp.RequestCtx) {
+		if id, _ := IDFromCtx(ctx); id != "abc" {
+			t.Errorf("IDFromCtx() = %q, want abc", id)
+		}
+		FromCtx(ctx).Info().Msg("handling")
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString("not found")
+	})))
+
+	req := &fasthttp.Request{}
+	req.SetRequestURI("/users/42?x=1")
+	req.Header.SetMethod("GET")
+	req.Header.Set(hlog.RequestIDHeader, "abc")
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234}, nil)
+	h(ctx)
+
+	if got := string(ctx.Response.Header.Peek(hlog.RequestIDHeader)); got != "abc" {
+		t.Errorf("response request id = %q, want abc", got)
+	}
+	want := `{"level":"info","request_id":"abc","message":"handling"}` + "\n" +
+		`{"level":"warn","request_id":"abc","method":"GET","url":"/users/42?x=1","status":404,"size":9,"duration":0,"remote_ip":"10.0.0.1"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
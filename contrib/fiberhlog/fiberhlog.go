@@ -0,0 +1,103 @@
+// Package fiberhlog provides fiber middlewares for zerolog, mirroring the
+// hlog helpers for net/http.
+//
+// The request logger is stored in the user context of the requests, so it
+// can be retrieved with FromContext or with zerolog.Ctx(c.UserContext()).
+// The middlewares are meant to be used together, in this order:
+//
+//     app := fiber.New()
+//     app.Use(
+//         fiberhlog.NewHandler(log.Logger),
+//         fiberhlog.RequestIDHandler(),
+//         fiberhlog.AccessHandler(),
+//         fiberhlog.RecoveryHandler(),
+//     )
+package fiberhlog
+
+import (
+	"runtime/debug"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+)
+
+// requestIDKey is the fiber locals key of the request id.
+const requestIDKey = "github.com/rs/zerolog/contrib/fiberhlog.requestID"
+
+// FromContext returns the logger of the request of c.
+func FromContext(c *fiber.Ctx) zerolog.Logger {
+	return zerolog.Ctx(c.UserContext())
+}
+
+// IDFromContext returns the request id of c, as set by RequestIDHandler.
+func IDFromContext(c *fiber.Ctx) (id string, ok bool) {
+	id, ok = c.Locals(requestIDKey).(string)
+	return
+}
+
+// NewHandler returns a middleware injecting l in the user context of the
+// requests, retrievable with FromContext.
+func NewHandler(l zerolog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.SetUserContext(l.WithContext(c.UserContext()))
+		return c.Next()
+	}
+}
+
+// RequestIDHandler returns a middleware reading the request id from the
+// hlog.RequestIDHeader header, or generating one, adding it to the request
+// logger and setting it in the response header.
+func RequestIDHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		l, id := hlog.RequestIDLogger(zerolog.Ctx(ctx), c.Get(hlog.RequestIDHeader))
+		c.Locals(requestIDKey, id)
+		c.SetUserContext(l.WithContext(ctx))
+		c.Set(hlog.RequestIDHeader, id)
+		return c.Next()
+	}
+}
+
+// AccessHandler returns a middleware logging the requests once handled with
+// hlog.AccessEvent, adding their client IP.
+//
+// Errors returned by the handlers are sent to the fiber error handler, so the
+// logged status is the status of the response, and logged.
+func AccessHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+		if err != nil {
+			if herr := c.App().Config().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+		res := c.Response()
+		hlog.AccessEvent(FromContext(c), c.Method(), c.OriginalURL(), c.Route().Path,
+			res.StatusCode(), int64(len(res.Body())), start).
+			Str(hlog.RemoteIPFieldName, c.IP()).
+			Err(err).
+			Msg("")
+		return nil
+	}
+}
+
+// RecoveryHandler returns a middleware recovering the panics of the
+// handlers, logging them at error level with their stack and returning a
+// fiber.ErrInternalServerError error.
+func RecoveryHandler() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				FromContext(c).Error().
+					Interface(hlog.PanicFieldName, p).
+					Str(hlog.StackFieldName, string(debug.Stack())).
+					Msg("panic serving request")
+				err = fiber.ErrInternalServerError
+			}
+		}()
+		return c.Next()
+	}
+}
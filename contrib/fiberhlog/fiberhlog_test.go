@@ -0,0 +1,78 @@
+package fiberhlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+)
+
+func decodeLines(t *testing.T, b []byte) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, l := range bytes.Split(bytes.TrimSpace(b), []byte("\n")) {
+		var m map[string]interface{}
+		if err := json.Unmarshal(l, &m); err != nil {
+			t.Fatalf("invalid log line %q: %v", l, err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestHandlers(t *testing.T) {
+	out := &bytes.Buffer{}
+	app := fiber.New()
+	app.Use(NewHandler(zerolog.New(out)), RequestIDHandler(), AccessHandler(), RecoveryHandler())
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		if id, _ := IDFromContext(c); id != "abc" {
+			t.Errorf("IDFromContext() = %q, want abc", id)
+		}
+		FromContext(c).Info().Msg("handling")
+		return fiber.NewError(fiber.StatusNotFound, "not found")
+	})
+	app.Get("/panic", func(c *fiber.Ctx) error {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest("GET", "/users/42?x=1", nil)
+	req.Header.Set(hlog.RequestIDHeader, "abc")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Header.Get(hlog.RequestIDHeader); got != "abc" {
+		t.Errorf("response request id = %q, want abc", got)
+	}
+	res, err = app.Test(httptest.NewRequest("GET", "/panic", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != fiber.StatusInternalServerError {
+		t.Errorf("panic status = %d, want 500", res.StatusCode)
+	}
+
+	lines := decodeLines(t, out.Bytes())
+	if len(lines) != 4 {
+		t.Fatalf("got %d log lines, want 4: %s", len(lines), out)
+	}
+	if lines[0]["message"] != "handling" || lines[0]["request_id"] != "abc" {
+		t.Errorf("invalid handler log: %v", lines[0])
+	}
+	access := lines[1]
+	for k, v := range map[string]interface{}{"level": "warn", "method": "GET", "url": "/users/42?x=1", "route": "/users/:id", "status": 404.0, "size": 9.0, "request_id": "abc", "error": "not found"} {
+		if access[k] != v {
+			t.Errorf("access log %s = %v, want %v", k, access[k], v)
+		}
+	}
+	if lines[2]["panic"] != "boom" || lines[2]["stack"] == nil {
+		t.Errorf("invalid panic log: %v", lines[2])
+	}
+	if lines[3]["level"] != "error" || lines[3]["status"] != 500.0 {
+		t.Errorf("invalid panic access log: %v", lines[3])
+	}
+}
@@ -0,0 +1,101 @@
+// Package ginhlog provides gin middlewares for zerolog, mirroring the hlog
+// helpers for net/http.
+//
+// The middlewares are meant to be used together, in this order:
+//
+//     r := gin.New()
+//     r.Use(
+//         ginhlog.NewHandler(log.Logger),
+//         ginhlog.RequestIDHandler(),
+//         ginhlog.AccessHandler(),
+//         ginhlog.RecoveryHandler(),
+//     )
+package ginhlog
+
+import (
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+)
+
+// requestIDKey is the gin context key of the request id.
+const requestIDKey = "github.com/rs/zerolog/contrib/ginhlog.requestID"
+
+// FromContext returns the logger of the request of c.
+func FromContext(c *gin.Context) zerolog.Logger {
+	return zerolog.Ctx(c.Request.Context())
+}
+
+// IDFromContext returns the request id of c, as set by RequestIDHandler.
+func IDFromContext(c *gin.Context) (id string, ok bool) {
+	id = c.GetString(requestIDKey)
+	return id, id != ""
+}
+
+// NewHandler returns a middleware injecting l in the context of the
+// requests, retrievable with FromContext or hlog.FromRequest.
+func NewHandler(l zerolog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(l.WithContext(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// RequestIDHandler returns a middleware reading the request id from the
+// hlog.RequestIDHeader header, or generating one, adding it to the request
+// logger and setting it in the response header.
+func RequestIDHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		l, id := hlog.RequestIDLogger(zerolog.Ctx(ctx), c.GetHeader(hlog.RequestIDHeader))
+		c.Set(requestIDKey, id)
+		c.Request = c.Request.WithContext(l.WithContext(ctx))
+		c.Header(hlog.RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// AccessHandler returns a middleware logging the requests once handled with
+// hlog.AccessEvent, adding their client IP. The last error attached to the
+// context, if any, is logged too.
+func AccessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		e := hlog.AccessEvent(FromContext(c), c.Request.Method, c.Request.URL.String(),
+			c.FullPath(), c.Writer.Status(), int64(c.Writer.Size()), start)
+		if !e.Enabled() {
+			return
+		}
+		e.Str(hlog.RemoteIPFieldName, c.ClientIP())
+		if err := c.Errors.Last(); err != nil {
+			e.Err(err.Err)
+		}
+		e.Msg("")
+	}
+}
+
+// RecoveryHandler returns a middleware recovering the panics of the
+// handlers, logging them at error level with their stack and responding
+// with a 500 status. http.ErrAbortHandler panics are not recovered.
+func RecoveryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if p := recover(); p != nil {
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				FromContext(c).Error().
+					Interface(hlog.PanicFieldName, p).
+					Str(hlog.StackFieldName, string(debug.Stack())).
+					Msg("panic serving request")
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}()
+		c.Next()
+	}
+}
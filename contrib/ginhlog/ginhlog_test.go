@@ -0,0 +1,79 @@
+package ginhlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func decodeLines(t *testing.T, b []byte) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, l := range bytes.Split(bytes.TrimSpace(b), []byte("\n")) {
+		var m map[string]interface{}
+		if err := json.Unmarshal(l, &m); err != nil {
+			t.Fatalf("invalid log line %q: %v", l, err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestHandlers(t *testing.T) {
+	out := &bytes.Buffer{}
+	r := gin.New()
+	r.Use(NewHandler(zerolog.New(out)), RequestIDHandler(), AccessHandler(), RecoveryHandler())
+	r.GET("/users/:id", func(c *gin.Context) {
+		if id, _ := IDFromContext(c); id != "abc" {
+			t.Errorf("IDFromContext() = %q, want abc", id)
+		}
+		FromContext(c).Info().Msg("handling")
+		c.String(http.StatusNotFound, "not found")
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest("GET", "/users/42?x=1", nil)
+	req.Header.Set(hlog.RequestIDHeader, "abc")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get(hlog.RequestIDHeader); got != "abc" {
+		t.Errorf("response request id = %q, want abc", got)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("panic status = %d, want 500", w.Code)
+	}
+
+	lines := decodeLines(t, out.Bytes())
+	if len(lines) != 4 {
+		t.Fatalf("got %d log lines, want 4: %s", len(lines), out)
+	}
+	if lines[0]["message"] != "handling" || lines[0]["request_id"] != "abc" {
+		t.Errorf("invalid handler log: %v", lines[0])
+	}
+	access := lines[1]
+	for k, v := range map[string]interface{}{"level": "warn", "method": "GET", "url": "/users/42?x=1", "route": "/users/:id", "status": 404.0, "size": 9.0, "request_id": "abc"} {
+		if access[k] != v {
+			t.Errorf("access log %s = %v, want %v", k, access[k], v)
+		}
+	}
+	if lines[2]["panic"] != "boom" || lines[2]["stack"] == nil {
+		t.Errorf("invalid panic log: %v", lines[2])
+	}
+	if lines[3]["level"] != "error" || lines[3]["status"] != 500.0 {
+		t.Errorf("invalid panic access log: %v", lines[3])
+	}
+}
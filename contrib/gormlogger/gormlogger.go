@@ -0,0 +1,126 @@
+// Package gormlogger provides a GORM logger.Interface backed by a zerolog
+// logger.
+//
+//     db, err := gorm.Open(dialector, &gorm.Config{
+//         Logger: gormlogger.New(log.Logger),
+//     })
+package gormlogger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+var (
+	// QueryFieldName is the field name used for the SQL query.
+	QueryFieldName = "query"
+
+	// RowsAffectedFieldName is the field name used for the number of rows
+	// affected by the query.
+	RowsAffectedFieldName = "rows_affected"
+
+	// DurationFieldName is the field name used for the duration of the query.
+	DurationFieldName = "duration"
+)
+
+// Logger is a GORM logger.Interface writing to a zerolog logger.
+//
+// Queries are logged at debug level, slow queries at warn level and failed
+// queries at error level, with the source location of the GORM call in the
+// zerolog.CallerFieldName field. The messages of GORM are logged at the
+// level of the method called. The context of the calls is passed to the
+// events, so hooks can read request scoped values from it.
+//
+// The log level of GORM, as set with LogMode, filters the events before the
+// level of the zerolog logger: at logger.Warn, only slow and failed queries
+// are logged, and at logger.Error only failed queries.
+type Logger struct {
+	// SlowThreshold is the duration above which queries are logged at warn
+	// level. Slow queries are not reported if it is 0.
+	SlowThreshold time.Duration
+
+	// IgnoreRecordNotFoundError disables the logging of the queries failing
+	// with logger.ErrRecordNotFound at error level. They are logged as
+	// successful queries.
+	IgnoreRecordNotFoundError bool
+
+	l     zerolog.Logger
+	level logger.LogLevel
+}
+
+var _ logger.Interface = Logger{}
+
+// New returns a Logger writing to l, reporting the queries taking more than
+// 200ms as slow.
+func New(l zerolog.Logger) Logger {
+	return Logger{
+		SlowThreshold: 200 * time.Millisecond,
+		l:             l,
+		level:         logger.Info,
+	}
+}
+
+// LogMode implements the logger.Interface interface.
+func (g Logger) LogMode(level logger.LogLevel) logger.Interface {
+	g.level = level
+	return g
+}
+
+// Info implements the logger.Interface interface.
+func (g Logger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= logger.Info {
+		g.l.Info().Ctx(ctx).Str(zerolog.CallerFieldName, utils.FileWithLineNum()).Msgf(msg, args...)
+	}
+}
+
+// Warn implements the logger.Interface interface.
+func (g Logger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= logger.Warn {
+		g.l.Warn().Ctx(ctx).Str(zerolog.CallerFieldName, utils.FileWithLineNum()).Msgf(msg, args...)
+	}
+}
+
+// Error implements the logger.Interface interface.
+func (g Logger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= logger.Error {
+		g.l.Error().Ctx(ctx).Str(zerolog.CallerFieldName, utils.FileWithLineNum()).Msgf(msg, args...)
+	}
+}
+
+// Trace implements the logger.Interface interface.
+func (g Logger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if g.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	var e *zerolog.Event
+	var msg string
+	switch {
+	case err != nil && g.level >= logger.Error && !(g.IgnoreRecordNotFoundError && errors.Is(err, logger.ErrRecordNotFound)):
+		e = g.l.Error().Err(err)
+	case g.SlowThreshold > 0 && elapsed > g.SlowThreshold && g.level >= logger.Warn:
+		e = g.l.Warn()
+		msg = "slow query"
+	case g.level >= logger.Info:
+		e = g.l.Debug()
+	default:
+		return
+	}
+	if !e.Enabled() {
+		return
+	}
+	sql, rows := fc()
+	e.Ctx(ctx).
+		Str(zerolog.CallerFieldName, utils.FileWithLineNum()).
+		Str(QueryFieldName, sql).
+		Dur(DurationFieldName, elapsed)
+	if rows >= 0 {
+		e.Int64(RowsAffectedFieldName, rows)
+	}
+	e.Msg(msg)
+}
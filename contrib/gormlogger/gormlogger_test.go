@@ -0,0 +1,78 @@
+package gormlogger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm/logger"
+)
+
+func TestTrace(t *testing.T) {
+	query := func() (string, int64) { return "SELECT * FROM users", 3 }
+	tests := []struct {
+		name    string
+		mode    logger.LogLevel
+		elapsed time.Duration
+		err     error
+		want    map[string]interface{}
+	}{
+		{"success", logger.Info, 0, nil, map[string]interface{}{"level": "debug", "query": "SELECT * FROM users", "rows_affected": 3.0}},
+		{"slow", logger.Info, time.Second, nil, map[string]interface{}{"level": "warn", "message": "slow query"}},
+		{"error", logger.Info, 0, errors.New("boom"), map[string]interface{}{"level": "error", "error": "boom"}},
+		{"not found ignored", logger.Info, 0, logger.ErrRecordNotFound, map[string]interface{}{"level": "debug", "error": nil}},
+		{"success at warn", logger.Warn, 0, nil, nil},
+		{"slow at error", logger.Error, time.Second, nil, nil},
+		{"error at silent", logger.Silent, 0, errors.New("boom"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			l := New(zerolog.New(out))
+			l.IgnoreRecordNotFoundError = true
+			l.LogMode(tt.mode).Trace(context.Background(), time.Now().Add(-tt.elapsed), query, tt.err)
+			if tt.want == nil {
+				if out.Len() != 0 {
+					t.Errorf("unexpected output: %s", out)
+				}
+				return
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("invalid output %q: %v", out, err)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %v, want %v", k, got[k], v)
+				}
+			}
+			if got[zerolog.CallerFieldName] == nil {
+				t.Errorf("missing caller: %s", out)
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	out := &bytes.Buffer{}
+	l := New(zerolog.New(out)).LogMode(logger.Warn)
+	l.Info(context.Background(), "ignored %d", 1)
+	l.Warn(context.Background(), "warned %d", 2)
+	l.Error(context.Background(), "failed %d", 3)
+	var levels, messages []string
+	for _, line := range bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n")) {
+		var m map[string]interface{}
+		if err := json.Unmarshal(line, &m); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		levels = append(levels, m["level"].(string))
+		messages = append(messages, m["message"].(string))
+	}
+	if len(levels) != 2 || levels[0] != "warn" || levels[1] != "error" || messages[0] != "warned 2" || messages[1] != "failed 3" {
+		t.Errorf("invalid log output: %s", out)
+	}
+}
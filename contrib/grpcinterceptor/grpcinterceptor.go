@@ -0,0 +1,175 @@
+// Package grpcinterceptor provides gRPC interceptors for zerolog, mirroring
+// the hlog helpers for net/http.
+//
+// The server interceptors inject a request scoped logger in the context of
+// the calls, retrievable with zerolog.Ctx, and log the method, peer, status
+// code and duration of each call. The request id of the calls is read from,
+// or set in, the RequestIDMetadataKey metadata and propagated to outgoing
+// calls by the client interceptors. For trace propagation, use the
+// OpenTelemetry gRPC instrumentation together with contrib/otelhook.
+package grpcinterceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// MethodFieldName is the field name used for the full method name.
+	MethodFieldName = "grpc_method"
+
+	// PeerFieldName is the field name used for the address of the peer.
+	PeerFieldName = "peer"
+
+	// CodeFieldName is the field name used for the status code.
+	CodeFieldName = "grpc_code"
+
+	// DurationFieldName is the field name used for the duration of the call.
+	DurationFieldName = "duration"
+
+	// RequestIDFieldName is the field name used for the request id.
+	RequestIDFieldName = "request_id"
+
+	// RequestIDMetadataKey is the metadata key used to propagate the request
+	// id.
+	RequestIDMetadataKey = "x-request-id"
+
+	// CodeToLevel returns the level of the events logged for calls ending
+	// with code.
+	CodeToLevel = DefaultCodeToLevel
+)
+
+// DefaultCodeToLevel logs successful calls at info level, client errors at
+// warn level and server errors at error level.
+func DefaultCodeToLevel(code codes.Code) zerolog.Level {
+	switch code {
+	case codes.OK:
+		return zerolog.InfoLevel
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return zerolog.WarnLevel
+	default:
+		return zerolog.ErrorLevel
+	}
+}
+
+type idKey struct{}
+
+// IDFromContext returns the request id of the call if any.
+func IDFromContext(ctx context.Context) (id string, ok bool) {
+	id, ok = ctx.Value(idKey{}).(string)
+	return
+}
+
+// newContext returns the context of a call to method, with its request id
+// and a logger derived from l.
+func newContext(ctx context.Context, l zerolog.Logger, method string) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(RequestIDMetadataKey); len(v) > 0 {
+			id = v[0]
+		}
+	}
+	if id == "" {
+		id = xid.New().String()
+	}
+	ctx = context.WithValue(ctx, idKey{}, id)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadataKey, id))
+
+	c := l.With().Str(MethodFieldName, method).Str(RequestIDFieldName, id)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		c = c.Str(PeerFieldName, p.Addr.String())
+	}
+	return c.Logger().WithContext(ctx)
+}
+
+// logCall logs the end of the call of ctx.
+func logCall(ctx context.Context, start time.Time, err error, msg string) {
+	code := status.Code(err)
+	zerolog.Ctx(ctx).WithLevel(CodeToLevel(code)).
+		Ctx(ctx).
+		Str(CodeFieldName, code.String()).
+		Dur(DurationFieldName, time.Since(start)).
+		Err(err).
+		Msg(msg)
+}
+
+// UnaryServerInterceptor returns a server interceptor injecting a logger
+// derived from l in the context of the unary calls and logging them.
+//
+//     srv := grpc.NewServer(
+//         grpc.UnaryInterceptor(grpcinterceptor.UnaryServerInterceptor(log.Logger)),
+//         grpc.StreamInterceptor(grpcinterceptor.StreamServerInterceptor(log.Logger)),
+//     )
+func UnaryServerInterceptor(l zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx = newContext(ctx, l, info.FullMethod)
+		resp, err := handler(ctx, req)
+		logCall(ctx, start, err, "finished unary call")
+		return resp, err
+	}
+}
+
+// serverStream overrides the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements the grpc.ServerStream interface.
+func (s serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// StreamServerInterceptor returns a server interceptor injecting a logger
+// derived from l in the context of the streaming calls and logging them.
+func StreamServerInterceptor(l zerolog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := newContext(ss.Context(), l, info.FullMethod)
+		err := handler(srv, serverStream{ServerStream: ss, ctx: ctx})
+		logCall(ctx, start, err, "finished streaming call")
+		return err
+	}
+}
+
+// outgoingContext adds the request id of ctx, if any, to its outgoing
+// metadata.
+func outgoingContext(ctx context.Context) context.Context {
+	if id, ok := IDFromContext(ctx); ok {
+		ctx = metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, id)
+	}
+	return ctx
+}
+
+// UnaryClientInterceptor returns a client interceptor propagating the
+// request id of the current call to the unary calls made while handling it.
+//
+//     conn, err := grpc.NewClient(target,
+//         grpc.WithUnaryInterceptor(grpcinterceptor.UnaryClientInterceptor()),
+//         grpc.WithStreamInterceptor(grpcinterceptor.StreamClientInterceptor()),
+//     )
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor returns a client interceptor propagating the
+// request id of the current call to the streaming calls made while handling
+// it.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
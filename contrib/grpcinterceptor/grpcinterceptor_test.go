@@ -0,0 +1,87 @@
+package grpcinterceptor
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func init() {
+	zerolog.DurationFieldUnit = time.Hour
+	zerolog.DurationFieldInteger = true
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	out := &bytes.Buffer{}
+	i := UnaryServerInterceptor(zerolog.New(out))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDMetadataKey, "abc"))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234}})
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Get"}
+	_, err := i(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if id, _ := IDFromContext(ctx); id != "abc" {
+			t.Errorf("IDFromContext() = %q, want abc", id)
+		}
+		zerolog.Ctx(ctx).Info().Msg("handling")
+		return nil, status.Error(codes.NotFound, "no such key")
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+	want := `{"level":"info","grpc_method":"/svc/Get","request_id":"abc","peer":"10.0.0.1:1234","message":"handling"}` + "\n" +
+		`{"level":"warn","grpc_method":"/svc/Get","request_id":"abc","peer":"10.0.0.1:1234","grpc_code":"NotFound","duration":0,"error":"rpc error: code = NotFound desc = no such key","message":"finished unary call"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+}
+
+func (testServerStream) Context() context.Context {
+	return context.Background()
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	out := &bytes.Buffer{}
+	i := StreamServerInterceptor(zerolog.New(out))
+	info := &grpc.StreamServerInfo{FullMethod: "/svc/Watch"}
+	var id string
+	err := i(nil, testServerStream{}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		id, _ = IDFromContext(ss.Context())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == "" {
+		t.Fatal("missing generated request id")
+	}
+	want := `{"level":"info","grpc_method":"/svc/Watch","request_id":"` + id + `","grpc_code":"OK","duration":0,"message":"finished streaming call"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	ctx := context.WithValue(context.Background(), idKey{}, "abc")
+	err := UnaryClientInterceptor()(ctx, "/svc/Get", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		if got := md.Get(RequestIDMetadataKey); len(got) != 1 || got[0] != "abc" {
+			t.Errorf("outgoing request id = %v, want [abc]", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
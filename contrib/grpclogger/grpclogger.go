@@ -0,0 +1,135 @@
+// Package grpclogger provides a grpclog.LoggerV2 backed by a zerolog logger,
+// so the internal logs of gRPC are structured and leveled like the logs of
+// the application.
+package grpclogger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/grpclog"
+)
+
+var (
+	// ComponentFieldName is the field name used for the gRPC component
+	// logging the message, like "core" or "transport".
+	ComponentFieldName = "grpc_component"
+
+	// ChannelFieldName is the field name used for the channel or
+	// subchannel the message is about.
+	ChannelFieldName = "grpc_channel"
+)
+
+// prefixRe matches the component and channel prefixes added by gRPC, like
+// "[core] [Channel #1 SubChannel #2] ".
+var prefixRe = regexp.MustCompile(`^\[([\w-]+)\] (?:\[([^\]]*Channel #\d+[^\]]*)\] ?)?`)
+
+// Logger is a grpclog.LoggerV2 writing to a zerolog logger.
+//
+// Messages are logged at the level of the method called, fatal messages
+// exiting the program like zerolog.Logger.Fatal. The component and channel
+// prefixes added by gRPC are removed from the messages and logged in the
+// ComponentFieldName and ChannelFieldName fields.
+type Logger struct {
+	l zerolog.Logger
+}
+
+var _ grpclog.LoggerV2 = Logger{}
+
+// New returns a grpclog.LoggerV2 writing to l.
+//
+//     grpclog.SetLoggerV2(grpclogger.New(log.With().Str("component", "grpc").Logger()))
+func New(l zerolog.Logger) Logger {
+	return Logger{l: l}
+}
+
+func (g Logger) msg(e *zerolog.Event, msg string) {
+	if !e.Enabled() {
+		return
+	}
+	msg = strings.TrimSuffix(msg, "\n")
+	if m := prefixRe.FindStringSubmatchIndex(msg); m != nil {
+		e.Str(ComponentFieldName, msg[m[2]:m[3]])
+		if m[4] >= 0 {
+			e.Str(ChannelFieldName, msg[m[4]:m[5]])
+		}
+		msg = msg[m[1]:]
+	}
+	e.Msg(msg)
+}
+
+// Info implements the grpclog.LoggerV2 interface.
+func (g Logger) Info(args ...interface{}) {
+	g.msg(g.l.Info(), fmt.Sprint(args...))
+}
+
+// Infoln implements the grpclog.LoggerV2 interface.
+func (g Logger) Infoln(args ...interface{}) {
+	g.msg(g.l.Info(), fmt.Sprintln(args...))
+}
+
+// Infof implements the grpclog.LoggerV2 interface.
+func (g Logger) Infof(format string, args ...interface{}) {
+	g.msg(g.l.Info(), fmt.Sprintf(format, args...))
+}
+
+// Warning implements the grpclog.LoggerV2 interface.
+func (g Logger) Warning(args ...interface{}) {
+	g.msg(g.l.Warn(), fmt.Sprint(args...))
+}
+
+// Warningln implements the grpclog.LoggerV2 interface.
+func (g Logger) Warningln(args ...interface{}) {
+	g.msg(g.l.Warn(), fmt.Sprintln(args...))
+}
+
+// Warningf implements the grpclog.LoggerV2 interface.
+func (g Logger) Warningf(format string, args ...interface{}) {
+	g.msg(g.l.Warn(), fmt.Sprintf(format, args...))
+}
+
+// Error implements the grpclog.LoggerV2 interface.
+func (g Logger) Error(args ...interface{}) {
+	g.msg(g.l.Error(), fmt.Sprint(args...))
+}
+
+// Errorln implements the grpclog.LoggerV2 interface.
+func (g Logger) Errorln(args ...interface{}) {
+	g.msg(g.l.Error(), fmt.Sprintln(args...))
+}
+
+// Errorf implements the grpclog.LoggerV2 interface.
+func (g Logger) Errorf(format string, args ...interface{}) {
+	g.msg(g.l.Error(), fmt.Sprintf(format, args...))
+}
+
+// Fatal implements the grpclog.LoggerV2 interface.
+func (g Logger) Fatal(args ...interface{}) {
+	g.msg(g.l.Fatal(), fmt.Sprint(args...))
+}
+
+// Fatalln implements the grpclog.LoggerV2 interface.
+func (g Logger) Fatalln(args ...interface{}) {
+	g.msg(g.l.Fatal(), fmt.Sprintln(args...))
+}
+
+// Fatalf implements the grpclog.LoggerV2 interface.
+func (g Logger) Fatalf(format string, args ...interface{}) {
+	g.msg(g.l.Fatal(), fmt.Sprintf(format, args...))
+}
+
+// V implements the grpclog.LoggerV2 interface. Verbosity 0 is enabled at
+// info level, 1 at debug level and above at trace level. The verbose
+// messages are still logged at info level.
+func (g Logger) V(l int) bool {
+	lvl := zerolog.InfoLevel
+	switch {
+	case l == 1:
+		lvl = zerolog.DebugLevel
+	case l > 1:
+		lvl = zerolog.TraceLevel
+	}
+	return lvl >= g.l.EffectiveLevel()
+}
@@ -0,0 +1,26 @@
+package grpclogger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/grpclog"
+)
+
+func TestLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	grpclog.SetLoggerV2(New(zerolog.New(out).Level(zerolog.DebugLevel)))
+	grpclog.Component("core").Infof("[Channel #1 SubChannel #2]Subchannel Connectivity change to %v", "READY")
+	grpclog.Component("transport").Warningf("failed to write status: %v", "connection reset by peer")
+	grpclog.Errorln("plain", 1)
+	want := `{"level":"info","grpc_component":"core","grpc_channel":"Channel #1 SubChannel #2","message":"Subchannel Connectivity change to READY"}` + "\n" +
+		`{"level":"warn","grpc_component":"transport","message":"failed to write status: connection reset by peer"}` + "\n" +
+		`{"level":"error","message":"plain 1"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if !grpclog.V(1) || grpclog.V(2) {
+		t.Errorf("V(1) = %v, V(2) = %v, want true, false", grpclog.V(1), grpclog.V(2))
+	}
+}
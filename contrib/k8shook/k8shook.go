@@ -0,0 +1,99 @@
+// Package k8shook provides a zerolog hook adding the Kubernetes metadata of
+// the pod running the process, as exposed by the Downward API.
+package k8shook
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	// PodFieldName is the field name used for the pod name.
+	PodFieldName = "k8s_pod"
+
+	// NamespaceFieldName is the field name used for the pod namespace.
+	NamespaceFieldName = "k8s_namespace"
+
+	// NodeFieldName is the field name used for the node name.
+	NodeFieldName = "k8s_node"
+)
+
+// Environment variables and Downward API volume files read by New.
+const (
+	PodEnv       = "POD_NAME"
+	NamespaceEnv = "POD_NAMESPACE"
+	NodeEnv      = "NODE_NAME"
+
+	// PodInfoDir is the conventional mount path of the Downward API volume,
+	// holding the name, namespace and node files.
+	PodInfoDir = "/etc/podinfo"
+
+	// serviceAccountNamespace is mounted in pods with a service account.
+	serviceAccountNamespace = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// Hook adds the pod name, namespace and node to every event. The metadata
+// is read once by New.
+//
+//     log := zerolog.New(os.Stderr).Hook(k8shook.New())
+//     log.Info().Msg("started")
+//     // Output: {"level":"info","k8s_pod":"api-7d9f","k8s_namespace":"prod","k8s_node":"node-1","message":"started"}
+type Hook struct {
+	Pod       string
+	Namespace string
+	Node      string
+}
+
+// New returns a Hook with the metadata read from the POD_NAME,
+// POD_NAMESPACE and NODE_NAME environment variables, falling back to the
+// name, namespace and node files of the PodInfoDir Downward API volume and
+// to the namespace of the service account. Missing metadata are not added to
+// events.
+//
+// The environment variables are set with a pod spec like:
+//
+//     env:
+//     - name: POD_NAME
+//       valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//     - name: POD_NAMESPACE
+//       valueFrom: {fieldRef: {fieldPath: metadata.namespace}}
+//     - name: NODE_NAME
+//       valueFrom: {fieldRef: {fieldPath: spec.nodeName}}
+func New() Hook {
+	return Hook{
+		Pod:       lookup(PodEnv, filepath.Join(PodInfoDir, "name")),
+		Namespace: lookup(NamespaceEnv, filepath.Join(PodInfoDir, "namespace"), serviceAccountNamespace),
+		Node:      lookup(NodeEnv, filepath.Join(PodInfoDir, "node")),
+	}
+}
+
+// lookup returns the value of the env variable or the content of the first
+// existing file.
+func lookup(env string, files ...string) string {
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	for _, f := range files {
+		if b, err := ioutil.ReadFile(f); err == nil {
+			return strings.TrimSpace(string(b))
+		}
+	}
+	return ""
+}
+
+// Run implements the zerolog.Hook interface.
+func (h Hook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if h.Pod != "" {
+		e.Str(PodFieldName, h.Pod)
+	}
+	if h.Namespace != "" {
+		e.Str(NamespaceFieldName, h.Namespace)
+	}
+	if h.Node != "" {
+		e.Str(NodeFieldName, h.Node)
+	}
+}
@@ -0,0 +1,31 @@
+package k8shook
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestNew(t *testing.T) {
+	for _, env := range []string{PodEnv, NamespaceEnv, NodeEnv} {
+		defer os.Setenv(env, os.Getenv(env))
+	}
+	os.Setenv(PodEnv, "api-7d9f")
+	os.Setenv(NamespaceEnv, "prod")
+	os.Setenv(NodeEnv, "node-1")
+	if got, want := New(), (Hook{Pod: "api-7d9f", Namespace: "prod", Node: "node-1"}); got != want {
+		t.Errorf("New() = %+v, want %+v", got, want)
+	}
+}
+
+func TestHook(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := zerolog.New(out).Hook(Hook{Pod: "api-7d9f", Namespace: "prod"})
+	log.Info().Msg("started")
+	want := `{"level":"info","k8s_pod":"api-7d9f","k8s_namespace":"prod","message":"started"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
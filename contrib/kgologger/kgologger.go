@@ -0,0 +1,92 @@
+// Package kgologger provides a franz-go kgo.Logger backed by a zerolog
+// logger, so the logs of the franz-go Kafka client are structured and leveled
+// like the logs of the application.
+package kgologger
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Logger is a kgo.Logger writing to a zerolog logger.
+//
+// Messages are logged at the zerolog level matching their kgo level, and
+// their key/value pairs are added as fields. The kgo level reported to the
+// client follows the level of the zerolog logger, so the client does not
+// build messages which would be discarded.
+type Logger struct {
+	l zerolog.Logger
+}
+
+var _ kgo.Logger = Logger{}
+
+// New returns a kgo.Logger writing to l.
+//
+//     cl, err := kgo.NewClient(
+//         kgo.SeedBrokers("localhost:9092"),
+//         kgo.WithLogger(kgologger.New(log.Logger)),
+//     )
+func New(l zerolog.Logger) Logger {
+	return Logger{l: l}
+}
+
+// Level implements the kgo.Logger interface.
+func (k Logger) Level() kgo.LogLevel {
+	switch lvl := k.l.EffectiveLevel(); {
+	case lvl <= zerolog.DebugLevel:
+		return kgo.LogLevelDebug
+	case lvl == zerolog.InfoLevel:
+		return kgo.LogLevelInfo
+	case lvl == zerolog.WarnLevel:
+		return kgo.LogLevelWarn
+	case lvl == zerolog.ErrorLevel:
+		return kgo.LogLevelError
+	default:
+		return kgo.LogLevelNone
+	}
+}
+
+// Log implements the kgo.Logger interface.
+func (k Logger) Log(level kgo.LogLevel, msg string, keyvals ...interface{}) {
+	e := k.l.WithLevel(zerologLevel(level))
+	if !e.Enabled() {
+		return
+	}
+	for i := 0; i < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+		var val interface{}
+		if i+1 < len(keyvals) {
+			val = keyvals[i+1]
+		}
+		switch v := val.(type) {
+		case string:
+			e.Str(key, v)
+		case error:
+			e.AnErr(key, v)
+		default:
+			e.Interface(key, v)
+		}
+	}
+	e.Msg(msg)
+}
+
+// zerologLevel returns the zerolog level of the kgo level.
+func zerologLevel(level kgo.LogLevel) zerolog.Level {
+	switch level {
+	case kgo.LogLevelError:
+		return zerolog.ErrorLevel
+	case kgo.LogLevelWarn:
+		return zerolog.WarnLevel
+	case kgo.LogLevelInfo:
+		return zerolog.InfoLevel
+	case kgo.LogLevelDebug:
+		return zerolog.DebugLevel
+	default:
+		return zerolog.Disabled
+	}
+}
@@ -0,0 +1,26 @@
+package kgologger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	l := New(zerolog.New(out).Level(zerolog.InfoLevel))
+	if got := l.Level(); got != kgo.LogLevelInfo {
+		t.Errorf("Level() = %v, want INFO", got)
+	}
+	l.Log(kgo.LogLevelDebug, "ignored")
+	l.Log(kgo.LogLevelInfo, "connection opened to broker", "addr", "localhost:9092", "broker", 1)
+	l.Log(kgo.LogLevelWarn, "unable to open connection to broker", "addr", "localhost:9092", "err", errors.New("connection refused"))
+	want := `{"level":"info","addr":"localhost:9092","broker":1,"message":"connection opened to broker"}` + "\n" +
+		`{"level":"warn","addr":"localhost:9092","err":"connection refused","message":"unable to open connection to broker"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+// Package kubelog installs a zerolog logger as the backend of klog and
+// controller-runtime, so the logs of Kubernetes clients and controllers are
+// written like the logs of the application.
+//
+//     verbosity := flag.Int("v", 0, "log verbosity")
+//     flag.Parse()
+//     kubelog.Install(log.Logger, *verbosity)
+package kubelog
+
+import (
+	"flag"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/contrib/logrsink"
+	"k8s.io/klog/v2"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// VerbosityLevel returns the zerolog level of the -v verbosity of klog:
+// verbosity 0 logs at info level, 1 at debug level and above at trace
+// level, like logrsink.
+func VerbosityLevel(verbosity int) zerolog.Level {
+	switch {
+	case verbosity <= 0:
+		return zerolog.InfoLevel
+	case verbosity == 1:
+		return zerolog.DebugLevel
+	default:
+		return zerolog.TraceLevel
+	}
+}
+
+// Install sets l, with the level of verbosity, as the logger of klog and
+// controller-runtime, and sets the verbosity of klog so its V(n) messages
+// up to verbosity are logged. It returns the logr.Logger installed.
+//
+// Install must be called before the klog flags are parsed, if they are, as
+// it overrides their verbosity.
+func Install(l zerolog.Logger, verbosity int) logr.Logger {
+	lg := logrsink.New(l.Level(VerbosityLevel(verbosity)))
+	klog.SetLogger(lg)
+	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(fs)
+	_ = fs.Set("v", strconv.Itoa(verbosity))
+	ctrllog.SetLogger(lg)
+	return lg
+}
@@ -0,0 +1,42 @@
+package kubelog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"k8s.io/klog/v2"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestVerbosityLevel(t *testing.T) {
+	tests := []struct {
+		verbosity int
+		want      zerolog.Level
+	}{
+		{-1, zerolog.InfoLevel},
+		{0, zerolog.InfoLevel},
+		{1, zerolog.DebugLevel},
+		{2, zerolog.TraceLevel},
+		{5, zerolog.TraceLevel},
+	}
+	for _, tt := range tests {
+		if got := VerbosityLevel(tt.verbosity); got != tt.want {
+			t.Errorf("VerbosityLevel(%d) = %v, want %v", tt.verbosity, got, tt.want)
+		}
+	}
+}
+
+func TestInstall(t *testing.T) {
+	out := &bytes.Buffer{}
+	Install(zerolog.New(out), 1)
+	defer klog.ClearLogger()
+	klog.V(2).InfoS("ignored")
+	klog.V(1).InfoS("watching", "kind", "Pod")
+	ctrllog.Log.WithName("manager").Info("starting")
+	want := `{"level":"debug","kind":"Pod","message":"watching"}` + "\n" +
+		`{"level":"info","logger":"manager","message":"starting"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,87 @@
+// Package lambdalog provides an AWS Lambda handler wrapper injecting a logger
+// in the context of the invocations.
+//
+//     func handle(ctx context.Context, e events.SQSEvent) error {
+//         zerolog.Ctx(ctx).Info().Int("records", len(e.Records)).Msg("handling")
+//         return nil
+//     }
+//
+//     func main() {
+//         lambda.StartHandler(lambdalog.Wrap(log.Logger, lambda.NewHandler(handle)))
+//     }
+package lambdalog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/rs/zerolog"
+)
+
+var (
+	// RequestIDFieldName is the field name used for the request id of the
+	// invocation.
+	RequestIDFieldName = "aws_request_id"
+
+	// FunctionNameFieldName is the field name used for the name of the
+	// function.
+	FunctionNameFieldName = "function_name"
+
+	// FunctionVersionFieldName is the field name used for the version of the
+	// function.
+	FunctionVersionFieldName = "function_version"
+
+	// ColdStartFieldName is the field name used for the cold start flag, true
+	// for the first invocation of the execution environment.
+	ColdStartFieldName = "cold_start"
+)
+
+// invoked is set once the first invocation started.
+var invoked int32
+
+type handler struct {
+	l     zerolog.Logger
+	h     lambda.Handler
+	flush []func(ctx context.Context) error
+}
+
+// Wrap returns a lambda.Handler invoking h with a context carrying a logger
+// derived from l, retrievable with zerolog.Ctx. The logger has the request
+// id of the invocation, the name and version of the function and the cold
+// start flag as fields.
+//
+// Once h returns, the flush functions are called with the context of the
+// invocation, so buffered writers are flushed before the execution
+// environment is frozen. Their errors are reported to zerolog.ErrorHandler,
+// or printed on os.Stderr if it is nil.
+func Wrap(l zerolog.Logger, h lambda.Handler, flush ...func(ctx context.Context) error) lambda.Handler {
+	return handler{l: l, h: h, flush: flush}
+}
+
+// Invoke implements the lambda.Handler interface.
+func (h handler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	c := h.l.With().
+		Str(FunctionNameFieldName, lambdacontext.FunctionName).
+		Str(FunctionVersionFieldName, lambdacontext.FunctionVersion).
+		Bool(ColdStartFieldName, atomic.CompareAndSwapInt32(&invoked, 0, 1))
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		c = c.Str(RequestIDFieldName, lc.AwsRequestID)
+	}
+	ctx = c.Logger().WithContext(ctx)
+	defer func() {
+		for _, f := range h.flush {
+			if err := f(ctx); err != nil {
+				if zerolog.ErrorHandler != nil {
+					zerolog.ErrorHandler(err)
+				} else {
+					fmt.Fprintf(os.Stderr, "lambdalog: could not flush: %v\n", err)
+				}
+			}
+		}
+	}()
+	return h.h.Invoke(ctx, payload)
+}
@@ -0,0 +1,39 @@
+package lambdalog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/rs/zerolog"
+)
+
+func TestWrap(t *testing.T) {
+	lambdacontext.FunctionName = "orders"
+	lambdacontext.FunctionVersion = "$LATEST"
+	out := &bytes.Buffer{}
+	var flushed int
+	h := Wrap(zerolog.New(out), lambda.NewHandler(func(ctx context.Context) error {
+		zerolog.Ctx(ctx).Info().Msg("handling")
+		return nil
+	}), func(ctx context.Context) error {
+		flushed++
+		return nil
+	})
+	for _, id := range []string{"req-1", "req-2"} {
+		ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: id})
+		if _, err := h.Invoke(ctx, []byte("{}")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := `{"level":"info","function_name":"orders","function_version":"$LATEST","cold_start":true,"aws_request_id":"req-1","message":"handling"}` + "\n" +
+		`{"level":"info","function_name":"orders","function_version":"$LATEST","cold_start":false,"aws_request_id":"req-2","message":"handling"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if flushed != 2 {
+		t.Errorf("flushed %d times, want 2", flushed)
+	}
+}
@@ -0,0 +1,119 @@
+// Package logrsink provides a logr.LogSink backed by a zerolog logger, so
+// libraries logging through github.com/go-logr/logr, such as
+// controller-runtime, log through the configured zerolog logger.
+package logrsink
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/rs/zerolog"
+)
+
+// NameFieldName is the field name used for the name of the logr logger, as
+// set with logr.Logger.WithName.
+var NameFieldName = "logger"
+
+// Sink is a logr.LogSink writing to a zerolog logger.
+//
+// Info messages of verbosity 0 are logged at zerolog.InfoLevel, of
+// verbosity 1 at zerolog.DebugLevel and above at zerolog.TraceLevel. Error
+// messages are logged at zerolog.ErrorLevel. Key/value pairs are added as
+// fields of the events.
+type Sink struct {
+	l    zerolog.Logger
+	name string
+}
+
+var _ logr.LogSink = (*Sink)(nil)
+
+// New returns a logr.Logger writing to l.
+//
+//     ctrl.SetLogger(logrsink.New(log.Logger))
+func New(l zerolog.Logger) logr.Logger {
+	return logr.New(&Sink{l: l})
+}
+
+// Init implements the logr.LogSink interface.
+func (s *Sink) Init(info logr.RuntimeInfo) {}
+
+// Enabled implements the logr.LogSink interface.
+func (s *Sink) Enabled(level int) bool {
+	return vLevel(level) >= s.l.EffectiveLevel()
+}
+
+// Info implements the logr.LogSink interface.
+func (s *Sink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.msg(s.l.WithLevel(vLevel(level)), msg, keysAndValues)
+}
+
+// Error implements the logr.LogSink interface.
+func (s *Sink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.msg(s.l.Error().Err(err), msg, keysAndValues)
+}
+
+// WithValues implements the logr.LogSink interface.
+func (s *Sink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	c := s.l.With()
+	for i := 0; i < len(keysAndValues); i += 2 {
+		k, v := keyValue(keysAndValues, i)
+		c = c.Interface(k, v)
+	}
+	return &Sink{l: c.Logger(), name: s.name}
+}
+
+// WithName implements the logr.LogSink interface. Names are joined with a
+// slash.
+func (s *Sink) WithName(name string) logr.LogSink {
+	if s.name != "" {
+		name = s.name + "/" + name
+	}
+	return &Sink{l: s.l, name: name}
+}
+
+func (s *Sink) msg(e *zerolog.Event, msg string, keysAndValues []interface{}) {
+	if !e.Enabled() {
+		return
+	}
+	if s.name != "" {
+		e.Str(NameFieldName, s.name)
+	}
+	for i := 0; i < len(keysAndValues); i += 2 {
+		k, v := keyValue(keysAndValues, i)
+		switch v := v.(type) {
+		case string:
+			e.Str(k, v)
+		case error:
+			e.AnErr(k, v)
+		default:
+			e.Interface(k, v)
+		}
+	}
+	e.Msg(msg)
+}
+
+// vLevel returns the zerolog level of the logr verbosity level.
+func vLevel(level int) zerolog.Level {
+	switch {
+	case level <= 0:
+		return zerolog.InfoLevel
+	case level == 1:
+		return zerolog.DebugLevel
+	default:
+		return zerolog.TraceLevel
+	}
+}
+
+// keyValue returns the key/value pair starting at index i of keysAndValues.
+// Keys which are not strings are formatted and a missing value is replaced
+// with nil.
+func keyValue(keysAndValues []interface{}, i int) (string, interface{}) {
+	k, ok := keysAndValues[i].(string)
+	if !ok {
+		k = fmt.Sprint(keysAndValues[i])
+	}
+	if i+1 == len(keysAndValues) {
+		return k, nil
+	}
+	return k, keysAndValues[i+1]
+}
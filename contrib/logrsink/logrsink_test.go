@@ -0,0 +1,30 @@
+package logrsink
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSink(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(zerolog.New(out).Level(zerolog.DebugLevel)).WithName("ctrl").WithValues("ns", "default")
+	log.WithName("reconciler").Info("reconciling", "object", "foo", "gen", 2)
+	log.V(1).Info("debug", "odd")
+	log.V(2).Info("trace")
+	log.Error(errors.New("boom"), "failed", 42, true)
+	want := `{"level":"info","ns":"default","logger":"ctrl/reconciler","object":"foo","gen":2,"message":"reconciling"}` + "\n" +
+		`{"level":"debug","ns":"default","logger":"ctrl","odd":null,"message":"debug"}` + "\n" +
+		`{"level":"error","ns":"default","error":"boom","logger":"ctrl","42":true,"message":"failed"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if log.V(2).Enabled() {
+		t.Error("V(2) should be disabled at debug level")
+	}
+	if !log.V(1).Enabled() {
+		t.Error("V(1) should be enabled at debug level")
+	}
+}
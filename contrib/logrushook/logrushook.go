@@ -0,0 +1,96 @@
+// Package logrushook provides a logrus hook re-emitting the logrus entries
+// thru a zerolog logger, so code bases can migrate from logrus
+// incrementally while producing a single output stream.
+//
+// The output of logrus is meant to be discarded, and its level set to trace
+// so the entries are filtered by the level of the zerolog logger:
+//
+//     logrus.SetOutput(ioutil.Discard)
+//     logrus.SetLevel(logrus.TraceLevel)
+//     logrus.AddHook(logrushook.New(log.Logger))
+package logrushook
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/rs/zerolog"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook is a logrus.Hook writing the entries to a zerolog logger.
+//
+// Entries are logged at the zerolog level of their logrus level, with their
+// fields sorted by key, and with the caller if logrus reports it. Error
+// values are added with AnErr, so the logrus.ErrorKey field is the error
+// field of zerolog with the default field names. The context of the
+// entries, as set with logrus.WithContext, is passed to the events.
+//
+// Exiting the program for fatal entries and panicking for panic entries are
+// left to logrus.
+type Hook struct {
+	l zerolog.Logger
+}
+
+var _ logrus.Hook = Hook{}
+
+// New returns a Hook writing to l.
+func New(l zerolog.Logger) Hook {
+	return Hook{l: l}
+}
+
+// Levels implements the logrus.Hook interface.
+func (h Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements the logrus.Hook interface.
+func (h Hook) Fire(entry *logrus.Entry) error {
+	e := h.l.WithLevel(zerologLevel(entry.Level))
+	if !e.Enabled() {
+		return nil
+	}
+	if entry.Context != nil {
+		e.Ctx(entry.Context)
+	}
+	if entry.HasCaller() {
+		e.Str(zerolog.CallerFieldName, entry.Caller.File+":"+strconv.Itoa(entry.Caller.Line))
+	}
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch v := entry.Data[k].(type) {
+		case string:
+			e.Str(k, v)
+		case error:
+			e.AnErr(k, v)
+		default:
+			e.Interface(k, v)
+		}
+	}
+	e.Msg(entry.Message)
+	return nil
+}
+
+// zerologLevel returns the zerolog level of the logrus level.
+func zerologLevel(level logrus.Level) zerolog.Level {
+	switch level {
+	case logrus.PanicLevel:
+		return zerolog.PanicLevel
+	case logrus.FatalLevel:
+		return zerolog.FatalLevel
+	case logrus.ErrorLevel:
+		return zerolog.ErrorLevel
+	case logrus.WarnLevel:
+		return zerolog.WarnLevel
+	case logrus.InfoLevel:
+		return zerolog.InfoLevel
+	case logrus.DebugLevel:
+		return zerolog.DebugLevel
+	default:
+		return zerolog.TraceLevel
+	}
+}
@@ -0,0 +1,27 @@
+package logrushook
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/sirupsen/logrus"
+)
+
+func TestHook(t *testing.T) {
+	out := &bytes.Buffer{}
+	l := logrus.New()
+	l.SetOutput(ioutil.Discard)
+	l.SetLevel(logrus.TraceLevel)
+	l.AddHook(New(zerolog.New(out).Level(zerolog.DebugLevel)))
+	l.Trace("ignored")
+	l.WithFields(logrus.Fields{"user": "bob", "attempt": 2}).Info("login")
+	l.WithError(errors.New("timeout")).Warn("retrying")
+	want := `{"level":"info","attempt":2,"user":"bob","message":"login"}` + "\n" +
+		`{"level":"warn","error":"timeout","message":"retrying"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
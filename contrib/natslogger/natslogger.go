@@ -0,0 +1,76 @@
+// Package natslogger logs the connection events of the NATS client with
+// zerolog.
+//
+//     nc, err := nats.Connect(nats.DefaultURL, natslogger.Handlers(log.Logger))
+package natslogger
+
+import (
+	"sync/atomic"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog"
+)
+
+var (
+	// ServerFieldName is the field name used for the URL of the server,
+	// with its password redacted.
+	ServerFieldName = "nats_server"
+
+	// SubjectFieldName is the field name used for the subject of the
+	// subscription of an asynchronous error.
+	SubjectFieldName = "nats_subject"
+
+	// ReconnectsFieldName is the field name used for the number of
+	// reconnections of the connection.
+	ReconnectsFieldName = "nats_reconnects"
+)
+
+// Handlers returns a nats.Option setting the error, disconnect, reconnect
+// and closed handlers of the connection to log the events with l.
+//
+// Asynchronous errors, like slow consumers, are logged at error level,
+// disconnections at warn level, or at info level when the connection is
+// closed by the client, and reconnections and closings at info level. As
+// the client is no longer connected when the disconnect and closed handlers
+// run, the server of these events is the last one known to the handlers.
+//
+// Handlers replaces the handlers set by previous options, and is replaced by
+// the handlers set by the following ones.
+func Handlers(l zerolog.Logger) nats.Option {
+	return func(o *nats.Options) error {
+		var lastURL atomic.Value
+		event := func(e *zerolog.Event, nc *nats.Conn) *zerolog.Event {
+			url := nc.ConnectedUrlRedacted()
+			if url != "" {
+				lastURL.Store(url)
+			} else {
+				url, _ = lastURL.Load().(string)
+			}
+			if url != "" {
+				e.Str(ServerFieldName, url)
+			}
+			return e.Uint64(ReconnectsFieldName, nc.Stats().Reconnects)
+		}
+		o.AsyncErrorCB = func(nc *nats.Conn, sub *nats.Subscription, err error) {
+			e := event(l.Error(), nc)
+			if sub != nil {
+				e.Str(SubjectFieldName, sub.Subject)
+			}
+			e.Err(err).Msg("nats async error")
+		}
+		o.DisconnectedErrCB = func(nc *nats.Conn, err error) {
+			e := l.Warn()
+			if err == nil {
+				e = l.Info()
+			}
+			event(e, nc).Err(err).Msg("nats disconnected")
+		}
+		o.ReconnectedCB = func(nc *nats.Conn) {
+			event(l.Info(), nc).Msg("nats reconnected")
+		}
+		o.ClosedCB = func(nc *nats.Conn) {
+			event(l.Info(), nc).Err(nc.LastError()).Msg("nats connection closed")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,31 @@
+package natslogger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog"
+)
+
+func TestHandlers(t *testing.T) {
+	out := &bytes.Buffer{}
+	o := nats.GetDefaultOptions()
+	if err := Handlers(zerolog.New(out))(&o); err != nil {
+		t.Fatal(err)
+	}
+	nc := &nats.Conn{}
+	o.AsyncErrorCB(nc, &nats.Subscription{Subject: "orders"}, nats.ErrSlowConsumer)
+	o.DisconnectedErrCB(nc, nats.ErrConnectionClosed)
+	o.DisconnectedErrCB(nc, nil)
+	o.ReconnectedCB(nc)
+	o.ClosedCB(nc)
+	want := `{"level":"error","nats_reconnects":0,"nats_subject":"orders","error":"nats: slow consumer, messages dropped","message":"nats async error"}` + "\n" +
+		`{"level":"warn","nats_reconnects":0,"error":"nats: connection closed","message":"nats disconnected"}` + "\n" +
+		`{"level":"info","nats_reconnects":0,"message":"nats disconnected"}` + "\n" +
+		`{"level":"info","nats_reconnects":0,"message":"nats reconnected"}` + "\n" +
+		`{"level":"info","nats_reconnects":0,"message":"nats connection closed"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,35 @@
+// Package otelhook provides a zerolog hook correlating events with
+// OpenTelemetry traces.
+package otelhook
+
+import (
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// TraceIDFieldName is the field name used for the trace id.
+	TraceIDFieldName = "trace_id"
+
+	// SpanIDFieldName is the field name used for the span id.
+	SpanIDFieldName = "span_id"
+)
+
+// Hook adds the W3C hex encoded trace and span ids of the span active in the
+// context of the event, as set with Event.Ctx or Context.Ctx. Events without
+// a valid span context are left untouched.
+//
+//     log := zerolog.New(os.Stderr).Hook(otelhook.Hook{})
+//     log.Info().Ctx(ctx).Msg("handled")
+//     // Output: {"level":"info","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","message":"handled"}
+type Hook struct{}
+
+// Run implements the zerolog.Hook interface.
+func (h Hook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	sc := trace.SpanContextFromContext(e.GetCtx())
+	if !sc.IsValid() {
+		return
+	}
+	e.Str(TraceIDFieldName, sc.TraceID().String()).
+		Str(SpanIDFieldName, sc.SpanID().String())
+}
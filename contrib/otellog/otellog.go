@@ -0,0 +1,151 @@
+// Package otellog provides a bridge emitting zerolog events as OpenTelemetry
+// log records, so they can be exported with the OpenTelemetry SDK.
+//
+// The bridge is a zerolog.BufferHook: the events are still written to the
+// writer of the logger, which can be ioutil.Discard if they are only meant
+// to be exported. As it decodes the serialized events, the bridge requires
+// the default JSON encoding.
+//
+//     provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+//     log := zerolog.New(ioutil.Discard).With().Timestamp().Logger().
+//         BufferHook(otellog.New(provider, "github.com/acme/app"))
+package otellog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+)
+
+// Severities maps levels to the numbers of the OpenTelemetry severities of
+// the records.
+var Severities = zerolog.SeverityMap{
+	zerolog.TraceLevel: strconv.Itoa(int(log.SeverityTrace)),
+	zerolog.DebugLevel: strconv.Itoa(int(log.SeverityDebug)),
+	zerolog.InfoLevel:  strconv.Itoa(int(log.SeverityInfo)),
+	zerolog.WarnLevel:  strconv.Itoa(int(log.SeverityWarn)),
+	zerolog.ErrorLevel: strconv.Itoa(int(log.SeverityError)),
+	zerolog.FatalLevel: strconv.Itoa(int(log.SeverityFatal)),
+	zerolog.PanicLevel: strconv.Itoa(int(log.SeverityFatal2)),
+	zerolog.AuditLevel: strconv.Itoa(int(log.SeverityInfo4)),
+}
+
+// Hook emits the events of the loggers it is attached to as log records.
+//
+// The message field is used as the body of the records, the level as their
+// severity, the timestamp field, if any, as their timestamp and the error
+// field as their error. The other fields are added as attributes. The
+// context of the events, as set with Event.Ctx or Context.Ctx, is passed to
+// the OpenTelemetry logger so the records are correlated with the active
+// span.
+type Hook struct {
+	l log.Logger
+}
+
+var _ zerolog.BufferHook = Hook{}
+
+// New returns a Hook emitting the records with the logger named name of p.
+// name is the instrumentation scope of the records, usually the import path
+// of the application or library using zerolog.
+func New(p log.LoggerProvider, name string, opts ...log.LoggerOption) Hook {
+	return Hook{l: p.Logger(name, opts...)}
+}
+
+// RunBuffer implements the zerolog.BufferHook interface.
+func (h Hook) RunBuffer(b *zerolog.EventBuffer) {
+	ctx := b.GetCtx()
+	level := b.Level()
+	sev := severity(level)
+	if !h.l.Enabled(ctx, log.EnabledParameters{Severity: sev}) {
+		return
+	}
+	var fields map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(append(append([]byte{'{'}, b.Fields()...), '}')))
+	d.UseNumber()
+	if d.Decode(&fields) != nil {
+		return
+	}
+	h.l.Emit(ctx, newRecord(level, sev, fields))
+}
+
+// severity returns the OpenTelemetry severity of level.
+func severity(level zerolog.Level) log.Severity {
+	n, _ := strconv.Atoi(Severities.Severity(level))
+	return log.Severity(n)
+}
+
+func newRecord(level zerolog.Level, sev log.Severity, fields map[string]interface{}) log.Record {
+	var r log.Record
+	now := time.Now()
+	r.SetObservedTimestamp(now)
+	r.SetTimestamp(timestamp(fields[zerolog.TimestampFieldName], now))
+	r.SetSeverity(sev)
+	if level != zerolog.NoLevel {
+		r.SetSeverityText(level.String())
+	}
+	if msg, ok := fields[zerolog.MessageFieldName].(string); ok {
+		r.SetBody(attribute.StringValue(msg))
+	}
+	if err, ok := fields[zerolog.ErrorFieldName].(string); ok {
+		r.SetErr(errors.New(err))
+	}
+	for _, name := range []string{zerolog.MessageFieldName, zerolog.ErrorFieldName, zerolog.LevelFieldName, zerolog.TimestampFieldName} {
+		delete(fields, name)
+	}
+	for k, v := range fields {
+		r.AddAttributes(attribute.KeyValue{Key: attribute.Key(k), Value: value(v)})
+	}
+	return r
+}
+
+// timestamp returns the time of the timestamp field v, or def if it can't
+// be parsed with zerolog.TimeFieldFormat.
+func timestamp(v interface{}, def time.Time) time.Time {
+	switch v := v.(type) {
+	case string:
+		if t, err := time.Parse(zerolog.TimeFieldFormat, v); err == nil {
+			return t
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil && zerolog.TimeFieldFormat == "" {
+			return time.Unix(i, 0)
+		}
+	}
+	return def
+}
+
+// value converts a decoded JSON value to an attribute value.
+func value(v interface{}) attribute.Value {
+	switch v := v.(type) {
+	case string:
+		return attribute.StringValue(v)
+	case bool:
+		return attribute.BoolValue(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return attribute.Int64Value(i)
+		}
+		f, _ := v.Float64()
+		return attribute.Float64Value(f)
+	case []interface{}:
+		vals := make([]attribute.Value, len(v))
+		for i, e := range v {
+			vals[i] = value(e)
+		}
+		return attribute.SliceValue(vals...)
+	case map[string]interface{}:
+		kvs := make([]attribute.KeyValue, 0, len(v))
+		for k, e := range v {
+			kvs = append(kvs, attribute.KeyValue{Key: attribute.Key(k), Value: value(e)})
+		}
+		return attribute.MapValue(kvs...)
+	default:
+		return attribute.Value{}
+	}
+}
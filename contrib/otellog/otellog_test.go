@@ -0,0 +1,96 @@
+package otellog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/noop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type recordingProvider struct {
+	noop.LoggerProvider
+	l *recordingLogger
+}
+
+func (p recordingProvider) Logger(name string, opts ...log.LoggerOption) log.Logger {
+	return p.l
+}
+
+type recordingLogger struct {
+	noop.Logger
+	ctxs    []context.Context
+	records []log.Record
+}
+
+func (l *recordingLogger) Enabled(ctx context.Context, param log.EnabledParameters) bool {
+	return param.Severity >= log.SeverityInfo
+}
+
+func (l *recordingLogger) Emit(ctx context.Context, r log.Record) {
+	l.ctxs = append(l.ctxs, ctx)
+	l.records = append(l.records, r)
+}
+
+func TestHook(t *testing.T) {
+	rl := &recordingLogger{}
+	out := &bytes.Buffer{}
+	logger := zerolog.New(out).BufferHook(New(recordingProvider{l: rl}, "test"))
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	logger.Debug().Msg("not enabled")
+	logger.Warn().Ctx(ctx).
+		Time(zerolog.TimestampFieldName, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)).
+		Str("user", "bob").
+		Int("attempt", 2).
+		Dict("req", zerolog.Dict().Bool("retry", true)).
+		Err(errors.New("failed")).
+		Msg("request failed")
+
+	if len(rl.records) != 1 {
+		t.Fatalf("got %d records, want 1", len(rl.records))
+	}
+	if got := trace.SpanContextFromContext(rl.ctxs[0]); !got.Equal(sc) {
+		t.Errorf("invalid span context: %v", got)
+	}
+	r := rl.records[0]
+	if got := r.Severity(); got != log.SeverityWarn {
+		t.Errorf("severity = %v, want WARN", got)
+	}
+	if got := r.SeverityText(); got != "warn" {
+		t.Errorf("severity text = %q, want warn", got)
+	}
+	if got := r.Body().AsString(); got != "request failed" {
+		t.Errorf("body = %q, want request failed", got)
+	}
+	if got := r.Timestamp(); !got.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("timestamp = %v", got)
+	}
+	if r.Err() == nil || r.Err().Error() != "failed" {
+		t.Errorf("err = %v, want failed", r.Err())
+	}
+	attrs := map[string]string{}
+	r.WalkAttributes(func(kv attribute.KeyValue) bool {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+		return true
+	})
+	want := map[string]string{"user": "bob", "attempt": "2", "req": `{"retry":true}`}
+	if len(attrs) != len(want) {
+		t.Errorf("attributes = %v, want %v", attrs, want)
+	}
+	for k, v := range want {
+		if attrs[k] != v {
+			t.Errorf("attribute %s = %q, want %q", k, attrs[k], v)
+		}
+	}
+	if out.Len() == 0 {
+		t.Error("events must still be written")
+	}
+}
@@ -0,0 +1,185 @@
+// Package pgxlog provides a pgx v5 query tracer and a tracelog.Logger
+// logging with zerolog.
+//
+// The queries are logged with the logger of their context, as returned by
+// zerolog.Ctx, so queries made with a context not carrying a logger are not
+// logged:
+//
+//     config, _ := pgx.ParseConfig(dsn)
+//     config.Tracer = pgxlog.Tracer{}
+//     conn, _ := pgx.ConnectConfig(ctx, config)
+//     conn.Exec(log.Logger.WithContext(ctx), "DELETE FROM users WHERE id = $1", id)
+package pgxlog
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/tracelog"
+	"github.com/rs/zerolog"
+)
+
+var (
+	// QueryFieldName is the field name used for the query.
+	QueryFieldName = "query"
+
+	// ArgsFieldName is the field name used for the query arguments.
+	ArgsFieldName = "args"
+
+	// DurationFieldName is the field name used for the duration of the query.
+	DurationFieldName = "duration"
+
+	// RowsAffectedFieldName is the field name used for the number of rows
+	// affected by the query.
+	RowsAffectedFieldName = "rows_affected"
+
+	// PgCodeFieldName is the field name used for the SQLSTATE code of the
+	// PostgreSQL errors.
+	PgCodeFieldName = "pg_code"
+)
+
+// Tracer is a pgx.QueryTracer logging the queries.
+//
+// Successful queries are logged at Level, failed queries at error level
+// with the SQLSTATE code of the error if it is a *pgconn.PgError.
+type Tracer struct {
+	// Level is the level of the successful queries.
+	Level zerolog.Level
+
+	// RedactArgs replaces the values of the query arguments with
+	// zerolog.RedactedValue.
+	RedactArgs bool
+}
+
+var _ pgx.QueryTracer = Tracer{}
+
+type queryKey struct{}
+
+type query struct {
+	start time.Time
+	sql   string
+	args  []interface{}
+}
+
+// TraceQueryStart implements the pgx.QueryTracer interface.
+func (t Tracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryKey{}, query{start: time.Now(), sql: data.SQL, args: data.Args})
+}
+
+// TraceQueryEnd implements the pgx.QueryTracer interface.
+func (t Tracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	q, ok := ctx.Value(queryKey{}).(query)
+	if !ok {
+		return
+	}
+	level := t.Level
+	if data.Err != nil {
+		level = zerolog.ErrorLevel
+	}
+	e := zerolog.Ctx(ctx).WithLevel(level)
+	if !e.Enabled() {
+		return
+	}
+	e.Ctx(ctx).Str(QueryFieldName, q.sql)
+	if len(q.args) > 0 {
+		e.Interface(ArgsFieldName, args(q.args, t.RedactArgs))
+	}
+	e.Dur(DurationFieldName, time.Since(q.start))
+	if data.Err == nil {
+		e.Int64(RowsAffectedFieldName, data.CommandTag.RowsAffected())
+	}
+	logErr(e, data.Err).Msg("query")
+}
+
+// Logger is a tracelog.Logger, for use with a tracelog.TraceLog, writing
+// the messages with the logger of their context.
+//
+// The data of the messages are added as fields sorted by key, with the
+// "args" values replaced with zerolog.RedactedValue if RedactArgs is set.
+// The "err" data are added as the error field, with the SQLSTATE code of
+// the error if it is a *pgconn.PgError.
+type Logger struct {
+	// RedactArgs replaces the values of the query arguments with
+	// zerolog.RedactedValue.
+	RedactArgs bool
+}
+
+var _ tracelog.Logger = Logger{}
+
+// Log implements the tracelog.Logger interface.
+func (l Logger) Log(ctx context.Context, level tracelog.LogLevel, msg string, data map[string]interface{}) {
+	e := zerolog.Ctx(ctx).WithLevel(zerologLevel(level))
+	if !e.Enabled() {
+		return
+	}
+	e.Ctx(ctx)
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch v := data[k].(type) {
+		case error:
+			if k == "err" {
+				logErr(e, v)
+			} else {
+				e.AnErr(k, v)
+			}
+		case []interface{}:
+			if k == "args" {
+				e.Interface(k, args(v, l.RedactArgs))
+			} else {
+				e.Interface(k, v)
+			}
+		case time.Duration:
+			e.Dur(k, v)
+		default:
+			e.Interface(k, v)
+		}
+	}
+	e.Msg(msg)
+}
+
+// zerologLevel returns the zerolog level of the tracelog level.
+func zerologLevel(level tracelog.LogLevel) zerolog.Level {
+	switch level {
+	case tracelog.LogLevelTrace:
+		return zerolog.TraceLevel
+	case tracelog.LogLevelDebug:
+		return zerolog.DebugLevel
+	case tracelog.LogLevelInfo:
+		return zerolog.InfoLevel
+	case tracelog.LogLevelWarn:
+		return zerolog.WarnLevel
+	case tracelog.LogLevelError:
+		return zerolog.ErrorLevel
+	default:
+		return zerolog.Disabled
+	}
+}
+
+// args returns the query arguments to log, redacted if redact is set.
+func args(a []interface{}, redact bool) []interface{} {
+	if !redact {
+		return a
+	}
+	values := make([]interface{}, len(a))
+	for i := range values {
+		values[i] = zerolog.RedactedValue
+	}
+	return values
+}
+
+// logErr adds err, and its SQLSTATE code if it is a *pgconn.PgError, to e.
+func logErr(e *zerolog.Event, err error) *zerolog.Event {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		e.Str(PgCodeFieldName, pgErr.Code)
+	}
+	return e.Err(err)
+}
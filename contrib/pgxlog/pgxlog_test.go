@@ -0,0 +1,69 @@
+package pgxlog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/tracelog"
+	"github.com/rs/zerolog"
+)
+
+func init() {
+	zerolog.DurationFieldUnit = time.Hour
+	zerolog.DurationFieldInteger = true
+}
+
+func TestTracer(t *testing.T) {
+	tests := []struct {
+		tracer Tracer
+		end    pgx.TraceQueryEndData
+		want   string
+	}{
+		{Tracer{}, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("DELETE 2")},
+			`{"level":"debug","query":"DELETE FROM users WHERE id = $1","args":[42],"duration":0,"rows_affected":2,"message":"query"}` + "\n"},
+		{Tracer{Level: zerolog.InfoLevel, RedactArgs: true}, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("DELETE 0")},
+			`{"level":"info","query":"DELETE FROM users WHERE id = $1","args":["[REDACTED]"],"duration":0,"rows_affected":0,"message":"query"}` + "\n"},
+		{Tracer{}, pgx.TraceQueryEndData{Err: &pgconn.PgError{Severity: "ERROR", Code: "42P01", Message: `relation "users" does not exist`}},
+			`{"level":"error","query":"DELETE FROM users WHERE id = $1","args":[42],"duration":0,"pg_code":"42P01","error":"ERROR: relation \"users\" does not exist (SQLSTATE 42P01)","message":"query"}` + "\n"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		ctx := zerolog.New(out).WithContext(context.Background())
+		ctx = tt.tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "DELETE FROM users WHERE id = $1", Args: []interface{}{42}})
+		tt.tracer.TraceQueryEnd(ctx, nil, tt.end)
+		if got := out.String(); got != tt.want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, tt.want)
+		}
+	}
+}
+
+func TestTracerNoLogger(t *testing.T) {
+	ctx := Tracer{}.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	Tracer{}.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+}
+
+func TestLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	ctx := zerolog.New(out).Level(zerolog.InfoLevel).WithContext(context.Background())
+	l := Logger{RedactArgs: true}
+	l.Log(ctx, tracelog.LogLevelDebug, "ignored", nil)
+	l.Log(ctx, tracelog.LogLevelInfo, "Query", map[string]interface{}{
+		"sql":  "SELECT name FROM users WHERE id = $1",
+		"args": []interface{}{42},
+		"time": time.Hour,
+		"pid":  uint32(7),
+	})
+	l.Log(ctx, tracelog.LogLevelError, "Query", map[string]interface{}{
+		"sql": "SELECT 1",
+		"err": &pgconn.PgError{Severity: "ERROR", Code: "57014", Message: "canceling statement due to user request"},
+	})
+	want := `{"level":"info","args":["[REDACTED]"],"pid":7,"sql":"SELECT name FROM users WHERE id = $1","time":1,"message":"Query"}` + "\n" +
+		`{"level":"error","pg_code":"57014","error":"ERROR: canceling statement due to user request (SQLSTATE 57014)","sql":"SELECT 1","message":"Query"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
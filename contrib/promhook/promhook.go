@@ -0,0 +1,37 @@
+// Package promhook provides a zerolog hook exposing the number of logged
+// events as Prometheus metrics.
+package promhook
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+// Hook counts the events written by the loggers it is attached to in the
+// log_messages_total counter, labeled by level and component.
+//
+// The component label is set to the component of the logger, as set with
+// Context.Component.
+//
+//     h, err := promhook.New(prometheus.DefaultRegisterer)
+//     log := zerolog.New(os.Stderr).Hook(h)
+type Hook struct {
+	messages *prometheus.CounterVec
+}
+
+// New creates a Hook and registers its counter with reg.
+func New(reg prometheus.Registerer) (Hook, error) {
+	messages := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "log_messages_total",
+		Help: "Number of log messages by level and component.",
+	}, []string{"level", "component"})
+	if err := reg.Register(messages); err != nil {
+		return Hook{}, err
+	}
+	return Hook{messages: messages}, nil
+}
+
+// Run implements the zerolog.Hook interface.
+func (h Hook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	h.messages.WithLabelValues(level.String(), e.GetComponent()).Inc()
+}
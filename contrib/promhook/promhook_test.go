@@ -0,0 +1,40 @@
+package promhook
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+)
+
+func TestHook(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	h, err := New(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := zerolog.New(nil).Level(zerolog.InfoLevel).Hook(h)
+	dbLog := log.With().Component("db").Logger()
+	log.Debug().Msg("")
+	log.Info().Msg("")
+	log.Error().Msg("")
+	log.Error().Msg("")
+	dbLog.Error().Msg("")
+	for _, tt := range []struct {
+		level, component string
+		want             float64
+	}{
+		{"debug", "", 0},
+		{"info", "", 1},
+		{"error", "", 2},
+		{"error", "db", 1},
+	} {
+		if got := testutil.ToFloat64(h.messages.WithLabelValues(tt.level, tt.component)); got != tt.want {
+			t.Errorf("log_messages_total{level=%q,component=%q}: got %v, want %v", tt.level, tt.component, got, tt.want)
+		}
+	}
+	if _, err := New(reg); err == nil {
+		t.Error("expected an error registering twice")
+	}
+}
@@ -0,0 +1,59 @@
+// Package saramalogger provides a sarama.StdLogger backed by a zerolog
+// logger, so the connection management events of the sarama Kafka client are
+// structured and leveled like the logs of the application.
+package saramalogger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/rs/zerolog"
+)
+
+// warnRe matches the sarama messages reporting errors, logged at warn level.
+var warnRe = regexp.MustCompile(`(?i)\b(?:err(?:or)?|fail(?:ed|ure)?|unable|cannot|can't|warning|because)\b`)
+
+// Logger is a sarama.StdLogger writing to a zerolog logger.
+//
+// As sarama does not level its messages, they are logged at the level given
+// to New, except for the messages reporting errors, like "Failed refreshing
+// metadata", which are logged at warn level if it is higher.
+type Logger struct {
+	l     zerolog.Logger
+	level zerolog.Level
+}
+
+var _ sarama.StdLogger = Logger{}
+
+// New returns a sarama.StdLogger writing to l at level.
+//
+//     sarama.Logger = saramalogger.New(log.Logger, zerolog.InfoLevel)
+//     sarama.DebugLogger = saramalogger.New(log.Logger, zerolog.DebugLevel)
+func New(l zerolog.Logger, level zerolog.Level) Logger {
+	return Logger{l: l, level: level}
+}
+
+func (s Logger) msg(msg string) {
+	level := s.level
+	if level < zerolog.WarnLevel && warnRe.MatchString(msg) {
+		level = zerolog.WarnLevel
+	}
+	s.l.WithLevel(level).Msg(strings.TrimSuffix(msg, "\n"))
+}
+
+// Print implements the sarama.StdLogger interface.
+func (s Logger) Print(v ...interface{}) {
+	s.msg(fmt.Sprint(v...))
+}
+
+// Printf implements the sarama.StdLogger interface.
+func (s Logger) Printf(format string, v ...interface{}) {
+	s.msg(fmt.Sprintf(format, v...))
+}
+
+// Println implements the sarama.StdLogger interface.
+func (s Logger) Println(v ...interface{}) {
+	s.msg(fmt.Sprintln(v...))
+}
@@ -0,0 +1,25 @@
+package saramalogger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	l := New(zerolog.New(out), zerolog.InfoLevel)
+	l.Printf("producer/broker/%d starting up\n", 1)
+	l.Printf("Failed refreshing metadata because of %v\n", errors.New("EOF"))
+	l.Println("Producer shutting down.")
+	New(zerolog.New(out), zerolog.ErrorLevel).Print("client/metadata got error from broker")
+	want := `{"level":"info","message":"producer/broker/1 starting up"}` + "\n" +
+		`{"level":"warn","message":"Failed refreshing metadata because of EOF"}` + "\n" +
+		`{"level":"info","message":"Producer shutting down."}` + "\n" +
+		`{"level":"error","message":"client/metadata got error from broker"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
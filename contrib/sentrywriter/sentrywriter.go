@@ -0,0 +1,146 @@
+// Package sentrywriter provides a zerolog writer forwarding error events to
+// Sentry.
+//
+// As it is a zerolog.LevelWriter, it receives the complete events, fields
+// included, and is meant to be combined with the main output using
+// zerolog.MultiLevelWriter:
+//
+//     sw := sentrywriter.New(sentry.CurrentHub(), 10, time.Minute)
+//     log := zerolog.New(zerolog.MultiLevelWriter(os.Stderr, sw))
+package sentrywriter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+)
+
+// Writer forwards the events of MinLevel and above to Sentry. The message
+// field is used as the Sentry message, the error field as the exception
+// value, and the other fields are sent as extra data. The stack trace of the
+// logging call is attached to the exception.
+//
+// Write errors are never returned so the main output is not affected by
+// Sentry.
+type Writer struct {
+	// MinLevel is the minimum level of forwarded events.
+	MinLevel zerolog.Level
+
+	hub    *sentry.Hub
+	limit  int
+	period time.Duration
+
+	mu          sync.Mutex
+	windowStart time.Time
+	count       int
+}
+
+// New creates a Writer capturing error, fatal and panic events with hub. At
+// most limit events are forwarded per period; if limit is 0, events are not
+// rate limited.
+func New(hub *sentry.Hub, limit int, period time.Duration) *Writer {
+	return &Writer{
+		MinLevel: zerolog.ErrorLevel,
+		hub:      hub,
+		limit:    limit,
+		period:   period,
+	}
+}
+
+// Write implements the io.Writer interface. Events without level are not
+// forwarded.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	return len(p), nil
+}
+
+// WriteLevel implements the zerolog.LevelWriter interface.
+func (w *Writer) WriteLevel(l zerolog.Level, p []byte) (n int, err error) {
+	n = len(p)
+	if l < w.MinLevel || l == zerolog.NoLevel || l == zerolog.AuditLevel || !w.allow() {
+		return
+	}
+	var fields map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if d.Decode(&fields) != nil {
+		return
+	}
+	w.hub.CaptureEvent(newEvent(l, fields))
+	return
+}
+
+// allow returns true if an event can be forwarded under the rate limit.
+func (w *Writer) allow() bool {
+	if w.limit <= 0 {
+		return true
+	}
+	now := time.Now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if now.Sub(w.windowStart) >= w.period {
+		w.windowStart = now
+		w.count = 0
+	}
+	w.count++
+	return w.count <= w.limit
+}
+
+func newEvent(l zerolog.Level, fields map[string]interface{}) *sentry.Event {
+	e := sentry.NewEvent()
+	e.Logger = "zerolog"
+	e.Level = sentry.LevelError
+	if l >= zerolog.FatalLevel {
+		e.Level = sentry.LevelFatal
+	}
+	e.Message, _ = fields[zerolog.MessageFieldName].(string)
+	errMsg, _ := fields[zerolog.ErrorFieldName].(string)
+	for _, name := range []string{zerolog.MessageFieldName, zerolog.ErrorFieldName, zerolog.LevelFieldName, zerolog.TimestampFieldName} {
+		delete(fields, name)
+	}
+	e.Extra = fields
+	exc := sentry.Exception{
+		Type:       l.String(),
+		Value:      errMsg,
+		Stacktrace: stacktrace(),
+	}
+	if exc.Value == "" {
+		exc.Value = e.Message
+	}
+	e.Exception = []sentry.Exception{exc}
+	return e
+}
+
+// stacktrace returns the stack trace of the logging call, without the frames
+// of zerolog.
+func stacktrace() *sentry.Stacktrace {
+	st := sentry.NewStacktrace()
+	if st == nil {
+		return nil
+	}
+	frames := st.Frames[:0]
+	for _, f := range st.Frames {
+		if isZerologFrame(f.Module) && !strings.HasSuffix(f.AbsPath, "_test.go") {
+			continue
+		}
+		frames = append(frames, f)
+	}
+	st.Frames = frames
+	return st
+}
+
+func isZerologFrame(module string) bool {
+	const pkg = "github.com/rs/zerolog"
+	if !strings.HasPrefix(module, pkg) {
+		return false
+	}
+	switch module[len(pkg):] {
+	case "", "/log", "/contrib/sentrywriter":
+		return true
+	}
+	return false
+}
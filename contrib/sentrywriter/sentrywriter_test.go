@@ -0,0 +1,61 @@
+package sentrywriter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+)
+
+func newTestHub(t *testing.T, events *[]*sentry.Event) *sentry.Hub {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		BeforeSend: func(e *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+			*events = append(*events, e)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sentry.NewHub(client, sentry.NewScope())
+}
+
+func TestWriter(t *testing.T) {
+	var events []*sentry.Event
+	log := zerolog.New(New(newTestHub(t, &events), 0, 0))
+	log.Info().Msg("ignored")
+	log.Error().Err(errors.New("boom")).Str("foo", "bar").Msg("failed")
+	if len(events) != 1 {
+		t.Fatalf("invalid number of events: %d", len(events))
+	}
+	e := events[0]
+	if e.Message != "failed" || e.Level != sentry.LevelError {
+		t.Errorf("invalid event: %q, %v", e.Message, e.Level)
+	}
+	if e.Extra["foo"] != "bar" || len(e.Extra) != 1 {
+		t.Errorf("invalid extra: %v", e.Extra)
+	}
+	if len(e.Exception) != 1 || e.Exception[0].Value != "boom" {
+		t.Fatalf("invalid exception: %v", e.Exception)
+	}
+	st := e.Exception[0].Stacktrace
+	if st == nil || len(st.Frames) == 0 {
+		t.Fatal("missing stack trace")
+	}
+	if f := st.Frames[len(st.Frames)-1]; f.Function != "TestWriter" {
+		t.Errorf("invalid last frame: %s.%s", f.Module, f.Function)
+	}
+}
+
+func TestWriterRateLimit(t *testing.T) {
+	var events []*sentry.Event
+	log := zerolog.New(New(newTestHub(t, &events), 2, time.Minute))
+	for i := 0; i < 5; i++ {
+		log.Error().Msg("failed")
+	}
+	if len(events) != 2 {
+		t.Errorf("invalid number of events: %d", len(events))
+	}
+}
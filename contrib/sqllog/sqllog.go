@@ -0,0 +1,308 @@
+// Package sqllog provides database/sql driver wrappers logging the queries
+// with zerolog.
+//
+// The queries are logged with the logger of their context, as returned by
+// zerolog.Ctx, so calls made without a context carrying a logger, like
+// db.Exec, are not logged:
+//
+//     db := sql.OpenDB(sqllog.WrapConnector(connector, sqllog.Options{}))
+//     db.ExecContext(log.Logger.WithContext(ctx), "DELETE FROM users WHERE id = ?", id)
+package sqllog
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	// QueryFieldName is the field name used for the query.
+	QueryFieldName = "query"
+
+	// ArgsFieldName is the field name used for the query arguments.
+	ArgsFieldName = "args"
+
+	// DurationFieldName is the field name used for the duration of the call.
+	DurationFieldName = "duration"
+
+	// RowsAffectedFieldName is the field name used for the number of rows
+	// affected by an exec.
+	RowsAffectedFieldName = "rows_affected"
+)
+
+// Options configures the logging of the wrapped drivers.
+type Options struct {
+	// Level is the level of the successful calls. Failed calls are logged at
+	// error level.
+	Level zerolog.Level
+
+	// RedactArgs replaces the values of the query arguments with
+	// zerolog.RedactedValue.
+	RedactArgs bool
+}
+
+// WrapDriver returns a driver.Driver logging the queries of the connections
+// opened by d. The returned driver implements driver.DriverContext if d
+// does.
+func WrapDriver(d driver.Driver, o Options) driver.Driver {
+	if _, ok := d.(driver.DriverContext); ok {
+		return wrappedDriverContext{wrappedDriver{Driver: d, o: o}}
+	}
+	return wrappedDriver{Driver: d, o: o}
+}
+
+// WrapConnector returns a driver.Connector logging the queries of the
+// connections opened by c, for use with sql.OpenDB.
+func WrapConnector(c driver.Connector, o Options) driver.Connector {
+	return wrappedConnector{Connector: c, o: o}
+}
+
+type wrappedDriver struct {
+	driver.Driver
+	o Options
+}
+
+// Open implements the driver.Driver interface.
+func (d wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, o: d.o}, nil
+}
+
+type wrappedDriverContext struct {
+	wrappedDriver
+}
+
+// OpenConnector implements the driver.DriverContext interface.
+func (d wrappedDriverContext) OpenConnector(name string) (driver.Connector, error) {
+	c, err := d.Driver.(driver.DriverContext).OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return wrappedConnector{Connector: c, o: d.o, d: d}, nil
+}
+
+type wrappedConnector struct {
+	driver.Connector
+	o Options
+	d driver.Driver
+}
+
+// Connect implements the driver.Connector interface.
+func (c wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: cn, o: c.o}, nil
+}
+
+// Driver implements the driver.Connector interface.
+func (c wrappedConnector) Driver() driver.Driver {
+	if c.d != nil {
+		return c.d
+	}
+	return wrappedDriver{Driver: c.Connector.Driver(), o: c.o}
+}
+
+// conn wraps a driver.Conn. The optional interfaces of the wrapped
+// connection are always implemented, falling back to the behavior of
+// database/sql when the wrapped connection does not implement them.
+type conn struct {
+	driver.Conn
+	o Options
+}
+
+// PrepareContext implements the driver.ConnPrepareContext interface.
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	start := time.Now()
+	var s driver.Stmt
+	var err error
+	if cp, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = cp.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		c.o.log(ctx, start, query, nil, err).Msg("prepare")
+		return nil, err
+	}
+	return &stmt{Stmt: s, query: query, o: c.o}, nil
+}
+
+// BeginTx implements the driver.ConnBeginTx interface.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if cb, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return cb.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errors.New("sqllog: driver does not support non-default transaction options")
+	}
+	return c.Conn.Begin()
+}
+
+// ExecContext implements the driver.ExecerContext interface.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := ec.ExecContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		return nil, err
+	}
+	logExec(c.o.log(ctx, start, query, args, err), res).Msg("exec")
+	return res, err
+}
+
+// QueryContext implements the driver.QueryerContext interface.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		return nil, err
+	}
+	c.o.log(ctx, start, query, args, err).Msg("query")
+	return rows, err
+}
+
+// Ping implements the driver.Pinger interface.
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implements the driver.SessionResetter interface.
+func (c *conn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.Conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid implements the driver.Validator interface.
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue implements the driver.NamedValueChecker interface.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// stmt wraps a driver.Stmt prepared for query.
+type stmt struct {
+	driver.Stmt
+	query string
+	o     Options
+}
+
+// ExecContext implements the driver.StmtExecContext interface.
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var res driver.Result
+	var err error
+	if se, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = se.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			res, err = s.Stmt.Exec(values)
+		}
+	}
+	logExec(s.o.log(ctx, start, s.query, args, err), res).Msg("exec")
+	return res, err
+}
+
+// QueryContext implements the driver.StmtQueryContext interface.
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if sq, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = sq.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			rows, err = s.Stmt.Query(values)
+		}
+	}
+	s.o.log(ctx, start, s.query, args, err).Msg("query")
+	return rows, err
+}
+
+// CheckNamedValue implements the driver.NamedValueChecker interface.
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// log returns an event, from the logger of ctx, for a call with query and
+// args started at start and ending with err.
+func (o Options) log(ctx context.Context, start time.Time, query string, args []driver.NamedValue, err error) *zerolog.Event {
+	level := o.Level
+	if err != nil {
+		level = zerolog.ErrorLevel
+	}
+	e := zerolog.Ctx(ctx).WithLevel(level)
+	if !e.Enabled() {
+		return e
+	}
+	e.Ctx(ctx).Str(QueryFieldName, query)
+	if len(args) > 0 {
+		values := make([]interface{}, len(args))
+		for i, a := range args {
+			if o.RedactArgs {
+				values[i] = zerolog.RedactedValue
+			} else {
+				values[i] = a.Value
+			}
+		}
+		e.Interface(ArgsFieldName, values)
+	}
+	return e.Dur(DurationFieldName, time.Since(start)).Err(err)
+}
+
+// logExec adds the number of rows affected by res, if known, to e.
+func logExec(e *zerolog.Event, res driver.Result) *zerolog.Event {
+	if res == nil || !e.Enabled() {
+		return e
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		e.Int64(RowsAffectedFieldName, n)
+	}
+	return e
+}
+
+// namedValuesToValues converts args for the driver.Stmt methods not taking
+// a context, which do not support named arguments.
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		if a.Name != "" {
+			return nil, errors.New("sqllog: driver does not support the use of Named Parameters")
+		}
+		values[i] = a.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,94 @@
+package sqllog
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func init() {
+	zerolog.DurationFieldUnit = time.Hour
+	zerolog.DurationFieldInteger = true
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if query == "FAIL" {
+		return nil, errors.New("syntax error")
+	}
+	return driver.RowsAffected(2), nil
+}
+
+func (fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return fakeRows{}, nil
+}
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string              { return nil }
+func (fakeRows) Close() error                   { return nil }
+func (fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestWrapDriver(t *testing.T) {
+	sql.Register("sqllog-test", WrapDriver(fakeDriver{}, Options{}))
+	sql.Register("sqllog-test-redact", WrapDriver(fakeDriver{}, Options{Level: zerolog.InfoLevel, RedactArgs: true}))
+
+	tests := []struct {
+		driver string
+		query  func(ctx context.Context, db *sql.DB) error
+		want   string
+	}{
+		{"sqllog-test", func(ctx context.Context, db *sql.DB) error {
+			_, err := db.ExecContext(ctx, "DELETE FROM users WHERE id = ?", 42)
+			return err
+		}, `{"level":"debug","query":"DELETE FROM users WHERE id = ?","args":[42],"duration":0,"rows_affected":2,"message":"exec"}` + "\n"},
+		{"sqllog-test", func(ctx context.Context, db *sql.DB) error {
+			_, err := db.ExecContext(ctx, "FAIL")
+			if err == nil {
+				t.Error("expected an error")
+			}
+			return nil
+		}, `{"level":"error","query":"FAIL","duration":0,"error":"syntax error","message":"exec"}` + "\n"},
+		{"sqllog-test-redact", func(ctx context.Context, db *sql.DB) error {
+			rows, err := db.QueryContext(ctx, "SELECT * FROM users WHERE password = ?", "secret")
+			if err == nil {
+				rows.Close()
+			}
+			return err
+		}, `{"level":"info","query":"SELECT * FROM users WHERE password = ?","args":["[REDACTED]"],"duration":0,"message":"query"}` + "\n"},
+		{"sqllog-test", func(ctx context.Context, db *sql.DB) error {
+			_, err := db.Exec("DELETE FROM users")
+			return err
+		}, ""},
+	}
+	for _, tt := range tests {
+		db, err := sql.Open(tt.driver, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := &bytes.Buffer{}
+		if err := tt.query(zerolog.New(out).WithContext(context.Background()), db); err != nil {
+			t.Fatal(err)
+		}
+		db.Close()
+		if got := out.String(); got != tt.want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Package temporallog provides a Temporal Go SDK log.Logger writing to a
+// zerolog logger, so workflow workers log with the configuration of the
+// application:
+//
+//     c, err := client.Dial(client.Options{Logger: temporallog.New(log.Logger)})
+package temporallog
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"go.temporal.io/sdk/log"
+)
+
+// FieldNames maps the keys of the Temporal SDK, such as the workflow and
+// activity IDs the SDK adds to the loggers returned by workflow.GetLogger
+// and activity.GetLogger, to field names. Keys not in the map are used as
+// field names.
+var FieldNames = map[string]string{
+	"Namespace":    "namespace",
+	"TaskQueue":    "task_queue",
+	"WorkflowID":   "workflow_id",
+	"RunID":        "run_id",
+	"WorkflowType": "workflow_type",
+	"ActivityID":   "activity_id",
+	"ActivityType": "activity_type",
+	"Attempt":      "attempt",
+}
+
+// Logger is a log.Logger writing to a zerolog logger.
+//
+// Key/value pairs are added as fields of the events, with the keys mapped
+// by FieldNames. Errors logged with the "Error" key of the SDK are added as
+// the error field.
+type Logger struct {
+	l zerolog.Logger
+}
+
+var (
+	_ log.Logger     = Logger{}
+	_ log.WithLogger = Logger{}
+)
+
+// New returns a Logger writing to l.
+func New(l zerolog.Logger) Logger {
+	return Logger{l: l}
+}
+
+// Debug implements the log.Logger interface.
+func (l Logger) Debug(msg string, keyvals ...interface{}) {
+	msgKeyvals(l.l.Debug(), msg, keyvals)
+}
+
+// Info implements the log.Logger interface.
+func (l Logger) Info(msg string, keyvals ...interface{}) {
+	msgKeyvals(l.l.Info(), msg, keyvals)
+}
+
+// Warn implements the log.Logger interface.
+func (l Logger) Warn(msg string, keyvals ...interface{}) {
+	msgKeyvals(l.l.Warn(), msg, keyvals)
+}
+
+// Error implements the log.Logger interface.
+func (l Logger) Error(msg string, keyvals ...interface{}) {
+	msgKeyvals(l.l.Error(), msg, keyvals)
+}
+
+// With implements the log.WithLogger interface.
+func (l Logger) With(keyvals ...interface{}) log.Logger {
+	c := l.l.With()
+	for i := 0; i < len(keyvals); i += 2 {
+		k, v := keyValue(keyvals, i)
+		if err, ok := v.(error); ok {
+			if k == zerolog.ErrorFieldName {
+				c = c.Err(err)
+			} else {
+				c = c.AnErr(k, err)
+			}
+			continue
+		}
+		c = c.Interface(k, v)
+	}
+	return Logger{l: c.Logger()}
+}
+
+func msgKeyvals(e *zerolog.Event, msg string, keyvals []interface{}) {
+	if !e.Enabled() {
+		return
+	}
+	for i := 0; i < len(keyvals); i += 2 {
+		k, v := keyValue(keyvals, i)
+		if err, ok := v.(error); ok {
+			if k == zerolog.ErrorFieldName {
+				e.Err(err)
+			} else {
+				e.AnErr(k, err)
+			}
+			continue
+		}
+		e.Interface(k, v)
+	}
+	e.Msg(msg)
+}
+
+// keyValue returns the field name and the value of the i-th key/value pair
+// of keyvals. The "Error" key of the SDK is returned as
+// zerolog.ErrorFieldName.
+func keyValue(keyvals []interface{}, i int) (string, interface{}) {
+	k, ok := keyvals[i].(string)
+	if !ok {
+		k = fmt.Sprint(keyvals[i])
+	}
+	if k == "Error" {
+		k = zerolog.ErrorFieldName
+	} else if name, ok := FieldNames[k]; ok {
+		k = name
+	}
+	if i+1 == len(keyvals) {
+		return k, nil
+	}
+	return k, keyvals[i+1]
+}
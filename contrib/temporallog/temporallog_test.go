@@ -0,0 +1,23 @@
+package temporallog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	l := New(zerolog.New(out).Level(zerolog.InfoLevel)).
+		With("Namespace", "default", "WorkflowID", "order-42", "RunID", "abc")
+	l.Debug("ignored")
+	l.Info("Activity started", "ActivityID", "5", "Attempt", 2)
+	l.Error("Activity error", "Error", errors.New("timeout"), "retry")
+	want := `{"level":"info","namespace":"default","workflow_id":"order-42","run_id":"abc","activity_id":"5","attempt":2,"message":"Activity started"}` + "\n" +
+		`{"level":"error","namespace":"default","workflow_id":"order-42","run_id":"abc","error":"timeout","retry":null,"message":"Activity error"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
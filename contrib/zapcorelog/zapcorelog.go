@@ -0,0 +1,203 @@
+// Package zapcorelog provides a zapcore.Core backed by a zerolog logger, so
+// code logging with zap writes thru the outputs, hooks, samplers and levels
+// configured on zerolog while it is migrated:
+//
+//     logger := zap.New(zapcorelog.New(log.Logger), zap.AddCaller())
+package zapcorelog
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	// LoggerFieldName is the field name used for the name of the zap logger,
+	// as set with zap.Logger.Named.
+	LoggerFieldName = "logger"
+
+	// StackFieldName is the field name used for the stack trace of the
+	// entries, as added with zap.AddStacktrace.
+	StackFieldName = "stack"
+)
+
+// Core is a zapcore.Core writing to a zerolog logger.
+//
+// Entries are logged at the zerolog level of their zap level, DPanic
+// entries at error level, and their fields are added to the events in
+// order. Typed fields are added with the matching zerolog type; other
+// fields, like objects and arrays, are added with Interface. Namespaces are
+// not supported: the fields following a zap.Namespace are added at the top
+// level of the events.
+//
+// The time of the entries is ignored: use the timestamp of the zerolog
+// logger instead. Exiting the program for fatal entries and panicking for
+// panic entries are left to zap.
+type Core struct {
+	l zerolog.Logger
+}
+
+var _ zapcore.Core = Core{}
+
+// New returns a zapcore.Core writing to l.
+func New(l zerolog.Logger) Core {
+	return Core{l: l}
+}
+
+// Enabled implements the zapcore.LevelEnabler interface.
+func (c Core) Enabled(level zapcore.Level) bool {
+	return zerologLevel(level) >= c.l.EffectiveLevel()
+}
+
+// With implements the zapcore.Core interface.
+func (c Core) With(fields []zapcore.Field) zapcore.Core {
+	a := &contextAdder{c: c.l.With()}
+	for _, f := range fields {
+		addField(a, f)
+	}
+	return Core{l: a.c.Logger()}
+}
+
+// Check implements the zapcore.Core interface.
+func (c Core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write implements the zapcore.Core interface.
+func (c Core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	e := c.l.WithLevel(zerologLevel(ent.Level))
+	if !e.Enabled() {
+		return nil
+	}
+	if ent.LoggerName != "" {
+		e.Str(LoggerFieldName, ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		e.Str(zerolog.CallerFieldName, ent.Caller.TrimmedPath())
+	}
+	a := eventAdder{e: e}
+	for _, f := range fields {
+		addField(a, f)
+	}
+	if ent.Stack != "" {
+		e.Str(StackFieldName, ent.Stack)
+	}
+	e.Msg(ent.Message)
+	return nil
+}
+
+// Sync implements the zapcore.Core interface. Events are written
+// synchronously by the zerolog logger so there is nothing to flush.
+func (c Core) Sync() error {
+	return nil
+}
+
+// zerologLevel returns the zerolog level of the zap level.
+func zerologLevel(level zapcore.Level) zerolog.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return zerolog.DebugLevel
+	case zapcore.InfoLevel:
+		return zerolog.InfoLevel
+	case zapcore.WarnLevel:
+		return zerolog.WarnLevel
+	case zapcore.ErrorLevel, zapcore.DPanicLevel:
+		return zerolog.ErrorLevel
+	case zapcore.PanicLevel:
+		return zerolog.PanicLevel
+	case zapcore.FatalLevel:
+		return zerolog.FatalLevel
+	default:
+		if level < zapcore.DebugLevel {
+			return zerolog.TraceLevel
+		}
+		return zerolog.FatalLevel
+	}
+}
+
+// fieldAdder adds fields to a zerolog.Event or zerolog.Context.
+type fieldAdder interface {
+	Str(key, val string)
+	Int64(key string, i int64)
+	Uint64(key string, i uint64)
+	Float64(key string, f float64)
+	Bool(key string, b bool)
+	Dur(key string, d time.Duration)
+	Time(key string, t time.Time)
+	AnErr(key string, err error)
+	Interface(key string, i interface{})
+}
+
+type eventAdder struct {
+	e *zerolog.Event
+}
+
+func (a eventAdder) Str(key, val string)                 { a.e.Str(key, val) }
+func (a eventAdder) Int64(key string, i int64)           { a.e.Int64(key, i) }
+func (a eventAdder) Uint64(key string, i uint64)         { a.e.Uint64(key, i) }
+func (a eventAdder) Float64(key string, f float64)       { a.e.Float64(key, f) }
+func (a eventAdder) Bool(key string, b bool)             { a.e.Bool(key, b) }
+func (a eventAdder) Dur(key string, d time.Duration)     { a.e.Dur(key, d) }
+func (a eventAdder) Time(key string, t time.Time)        { a.e.Time(key, t) }
+func (a eventAdder) AnErr(key string, err error)         { a.e.AnErr(key, err) }
+func (a eventAdder) Interface(key string, i interface{}) { a.e.Interface(key, i) }
+
+type contextAdder struct {
+	c zerolog.Context
+}
+
+func (a *contextAdder) Str(key, val string)                 { a.c = a.c.Str(key, val) }
+func (a *contextAdder) Int64(key string, i int64)           { a.c = a.c.Int64(key, i) }
+func (a *contextAdder) Uint64(key string, i uint64)         { a.c = a.c.Uint64(key, i) }
+func (a *contextAdder) Float64(key string, f float64)       { a.c = a.c.Float64(key, f) }
+func (a *contextAdder) Bool(key string, b bool)             { a.c = a.c.Bool(key, b) }
+func (a *contextAdder) Dur(key string, d time.Duration)     { a.c = a.c.Dur(key, d) }
+func (a *contextAdder) Time(key string, t time.Time)        { a.c = a.c.Time(key, t) }
+func (a *contextAdder) AnErr(key string, err error)         { a.c = a.c.AnErr(key, err) }
+func (a *contextAdder) Interface(key string, i interface{}) { a.c = a.c.Interface(key, i) }
+
+// addField adds the zap field f to a.
+func addField(a fieldAdder, f zapcore.Field) {
+	switch f.Type {
+	case zapcore.StringType:
+		a.Str(f.Key, f.String)
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		a.Int64(f.Key, f.Integer)
+	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		a.Uint64(f.Key, uint64(f.Integer))
+	case zapcore.Float64Type:
+		a.Float64(f.Key, math.Float64frombits(uint64(f.Integer)))
+	case zapcore.Float32Type:
+		a.Float64(f.Key, float64(math.Float32frombits(uint32(f.Integer))))
+	case zapcore.BoolType:
+		a.Bool(f.Key, f.Integer == 1)
+	case zapcore.DurationType:
+		a.Dur(f.Key, time.Duration(f.Integer))
+	case zapcore.TimeType:
+		t := time.Unix(0, f.Integer)
+		if loc, ok := f.Interface.(*time.Location); ok {
+			t = t.In(loc)
+		}
+		a.Time(f.Key, t)
+	case zapcore.TimeFullType:
+		a.Time(f.Key, f.Interface.(time.Time))
+	case zapcore.ErrorType:
+		err, _ := f.Interface.(error)
+		a.AnErr(f.Key, err)
+	case zapcore.StringerType:
+		a.Str(f.Key, fmt.Sprint(f.Interface))
+	case zapcore.SkipType, zapcore.NamespaceType:
+	default:
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		for k, v := range enc.Fields {
+			a.Interface(k, v)
+		}
+	}
+}
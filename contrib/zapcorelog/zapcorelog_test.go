@@ -0,0 +1,40 @@
+package zapcorelog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/zap"
+)
+
+func init() {
+	zerolog.DurationFieldUnit = time.Second
+	zerolog.DurationFieldInteger = true
+}
+
+func TestCore(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := zap.New(New(zerolog.New(out).Level(zerolog.InfoLevel))).
+		Named("db").
+		With(zap.String("component", "pool"))
+	logger.Debug("ignored")
+	logger.Info("connected",
+		zap.Int("conns", 3),
+		zap.Uint8("retries", 1),
+		zap.Float64("ratio", 0.5),
+		zap.Bool("tls", true),
+		zap.Duration("took", 2*time.Second),
+		zap.Time("at", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+		zap.Strings("hosts", []string{"a", "b"}),
+		zap.Error(nil),
+	)
+	logger.Error("query failed", zap.Error(errors.New("timeout")))
+	want := `{"level":"info","component":"pool","logger":"db","conns":3,"retries":1,"ratio":0.5,"tls":true,"took":2,"at":"2020-01-02T03:04:05Z","hosts":["a","b"],"message":"connected"}` + "\n" +
+		`{"level":"error","component":"pool","logger":"db","error":"timeout","message":"query failed"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -9,6 +9,10 @@ var disabledLogger = New(ioutil.Discard).Level(Disabled)
 
 type ctxKey struct{}
 
+type levelOverrideKey struct{}
+
+type ctxFieldsKey struct{}
+
 // WithContext returns a copy of ctx with l associated.
 func (l Logger) WithContext(ctx context.Context) context.Context {
 	if lp, ok := ctx.Value(ctxKey{}).(*Logger); ok {
@@ -21,9 +25,72 @@ func (l Logger) WithContext(ctx context.Context) context.Context {
 
 // Ctx returns the Logger associated with the ctx. If no logger
 // is associated, a disabled logger is returned.
+//
+// If ctx holds a level override set by WithLevelOverride, the returned logger
+// honors it.
 func Ctx(ctx context.Context) Logger {
 	if l, ok := ctx.Value(ctxKey{}).(*Logger); ok {
+		if lvl, ok := ctx.Value(levelOverrideKey{}).(*Level); ok {
+			ll := *l
+			ll.override = lvl
+			return ll
+		}
 		return *l
 	}
 	return disabledLogger
 }
+
+// WithLevelOverride returns a copy of ctx with the minimum level override l.
+// Loggers returned by Ctx for this context accept events of level l and
+// above, regardless of their own level, the global level and component
+// levels, except when the global level or the level of the logger is
+// Disabled. The override does not lower the level set with Context.Level
+// either.
+//
+// This is useful to make a single request or tenant verbose without changing
+// the global level.
+func WithLevelOverride(ctx context.Context, l Level) context.Context {
+	return context.WithValue(ctx, levelOverrideKey{}, &l)
+}
+
+// LevelOverride returns the level override stored in ctx by WithLevelOverride
+// and true, or false if ctx has no override.
+func LevelOverride(ctx context.Context) (Level, bool) {
+	if l, ok := ctx.Value(levelOverrideKey{}).(*Level); ok {
+		return *l, true
+	}
+	return 0, false
+}
+
+// WithFields returns a copy of ctx carrying the fields added by f, in
+// addition to the fields already carried by ctx. The fields are added to the
+// events having ctx as context by CtxFieldsHook, so a unit of work, such as
+// a request or a job, can enrich all its events without passing a logger
+// around:
+//
+//     ctx = zerolog.WithFields(ctx, func(c zerolog.Context) zerolog.Context {
+//         return c.Str("job_id", id)
+//     })
+//     log.Info().Ctx(ctx).Msg("done") // {"level":"info","job_id":"42","message":"done"}
+func WithFields(ctx context.Context, f func(c Context) Context) context.Context {
+	var l Logger
+	if fields, ok := ctx.Value(ctxFieldsKey{}).([]byte); ok {
+		l.context = fields
+	}
+	return context.WithValue(ctx, ctxFieldsKey{}, f(l.With()).l.context)
+}
+
+// CtxFieldsHook is a hook adding the fields carried by the context of the
+// event, as set with WithFields.
+type CtxFieldsHook struct{}
+
+// Run implements the Hook interface.
+func (h CtxFieldsHook) Run(e *Event, level Level, msg string) {
+	if e.ctx == nil {
+		return
+	}
+	if fields, ok := e.ctx.Value(ctxFieldsKey{}).([]byte); ok && len(fields) > 1 {
+		// Skip the timestamp flag.
+		e.buf = appendObjectData(e.buf, fields[1:])
+	}
+}
@@ -1,6 +1,7 @@
 package zerolog
 
 import (
+	"bytes"
 	"context"
 	"io/ioutil"
 	"reflect"
@@ -28,3 +29,72 @@ func TestCtx(t *testing.T) {
 		t.Error("Ctx did not return the expected logger")
 	}
 }
+
+func TestCtxLevelOverride(t *testing.T) {
+	skipIfNoDebug(t)
+	defer SetGlobalLevel(GlobalLevel())
+	SetGlobalLevel(WarnLevel)
+	out := &bytes.Buffer{}
+	ctx := New(out).Level(ErrorLevel).WithContext(context.Background())
+	Ctx(ctx).Debug().Msg("filtered")
+
+	ctx = WithLevelOverride(ctx, DebugLevel)
+	if l, ok := LevelOverride(ctx); !ok || l != DebugLevel {
+		t.Errorf("invalid level override: %v, %v", l, ok)
+	}
+	Ctx(ctx).Trace().Msg("filtered")
+	Ctx(ctx).Debug().Msg("kept")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"debug","message":"kept"}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+
+	out.Reset()
+	SetGlobalLevel(Disabled)
+	Ctx(ctx).Error().Msg("filtered")
+	if got, want := decodeIfBinaryToString(out.Bytes()), ""; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+
+	if _, ok := LevelOverride(context.Background()); ok {
+		t.Error("unexpected level override")
+	}
+}
+
+func TestCtxLevelOverrideMuted(t *testing.T) {
+	out := &bytes.Buffer{}
+	ctx := WithLevelOverride(context.Background(), DebugLevel)
+	loggers := []Logger{
+		New(out).Level(Disabled),
+		New(out).With().Level(Disabled).Logger(),
+		New(out).With().Level(ErrorLevel).Logger(),
+	}
+	for _, l := range loggers {
+		Ctx(l.WithContext(ctx)).Warn().Msg("filtered")
+	}
+	if got, want := decodeIfBinaryToString(out.Bytes()), ""; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+	if got := Ctx(Nop().WithContext(ctx)).EffectiveLevel(); got != Disabled {
+		t.Errorf("EffectiveLevel() = %v, want %v", got, Disabled)
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().Str("svc", "api").Logger().Hook(CtxFieldsHook{})
+	ctx := WithFields(context.Background(), func(c Context) Context {
+		return c.Str("req_id", "1")
+	})
+	child := WithFields(ctx, func(c Context) Context {
+		return c.Int("attempt", 2)
+	})
+	log.Log().Msg("none")
+	log.Log().Ctx(ctx).Msg("parent")
+	log.With().Ctx(child).Logger().Log().Msg("child")
+	want := `{"svc":"api","message":"none"}` + "\n" +
+		`{"svc":"api","req_id":"1","message":"parent"}` + "\n" +
+		`{"svc":"api","req_id":"1","attempt":2,"message":"child"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,14 @@
+// +build !zerolog_nodebug
+
+package zerolog
+
+// DebugEnabled is false when built with the zerolog_nodebug build tag, in
+// which case Debug and Trace events are never logged.
+//
+// As Go evaluates the arguments of the Event methods, guard expensive
+// arguments with this constant so the compiler removes them altogether:
+//
+//     if zerolog.DebugEnabled {
+//         log.Debug().Str("state", dump()).Msg("")
+//     }
+const DebugEnabled = true
@@ -0,0 +1,135 @@
+package zerolog
+
+import (
+	"math"
+	"time"
+)
+
+// fieldKind is the type of a field staged by an event of a logger created
+// with DeferFields.
+type fieldKind uint8
+
+const (
+	kindStr fieldKind = iota
+	kindStrUnsafe
+	kindBytes
+	kindRawJSON
+	kindBase64
+	kindErr
+	kindBool
+	kindInt
+	kindInt8
+	kindInt16
+	kindInt32
+	kindInt64
+	kindUint
+	kindUint8
+	kindUint16
+	kindUint32
+	kindUint64
+	kindFloat32
+	kindFloat64
+	kindTime
+	kindDur
+	kindInterface
+)
+
+// stagedField is a field kept as a typed value until its event is known to
+// be written.
+type stagedField struct {
+	key  string
+	kind fieldKind
+	// n holds the integers, booleans, durations and the bits of the
+	// floats, s the strings, b the byte slices, t the times and v the errors
+	// and the values marshaled with reflection.
+	n uint64
+	s string
+	b []byte
+	t time.Time
+	v interface{}
+}
+
+// stage adds f to the fields of e to serialize before its hooks run.
+func (e *Event) stage(f stagedField) *Event {
+	e.staged = append(e.staged, f)
+	return e
+}
+
+// stageBytes is like stage for the fields with a byte slice value. The value
+// is copied to a buffer of e, so the caller can reuse it as soon as the
+// field is added, as with the events serializing their fields immediately.
+func (e *Event) stageBytes(key string, kind fieldKind, b []byte) *Event {
+	start := len(e.stagedBytes)
+	e.stagedBytes = append(e.stagedBytes, b...)
+	end := len(e.stagedBytes)
+	return e.stage(stagedField{key: key, kind: kind, b: e.stagedBytes[start:end:end]})
+}
+
+// releaseStaged clears the fields staged by e without serializing them, so
+// a dropped event does not keep their values alive in the pool.
+func (e *Event) releaseStaged() {
+	for i := range e.staged {
+		e.staged[i] = stagedField{}
+	}
+	e.staged = e.staged[:0]
+}
+
+// serializeStaged appends the fields staged by e to its buffer, in order.
+// It must be called before appending fields directly to the buffer of an
+// event of a logger created with DeferFields, to keep the order of the
+// fields.
+func (e *Event) serializeStaged() {
+	for i := range e.staged {
+		f := &e.staged[i]
+		switch f.kind {
+		case kindStr:
+			e.buf = appendString(e.buf, f.key, f.s)
+		case kindStrUnsafe:
+			e.buf = appendStringUnsafe(e.buf, f.key, f.s)
+		case kindBytes:
+			e.buf = appendBytes(e.buf, f.key, f.b)
+		case kindRawJSON:
+			e.buf = appendRawJSON(e.buf, f.key, f.b)
+		case kindBase64:
+			e.buf = appendBase64(e.buf, f.key, f.b)
+		case kindErr:
+			err, _ := f.v.(error)
+			e.buf = appendErrorKey(e.buf, f.key, err)
+		case kindBool:
+			e.buf = appendBool(e.buf, f.key, f.n != 0)
+		case kindInt:
+			e.buf = appendInt(e.buf, f.key, int(f.n))
+		case kindInt8:
+			e.buf = appendInt8(e.buf, f.key, int8(f.n))
+		case kindInt16:
+			e.buf = appendInt16(e.buf, f.key, int16(f.n))
+		case kindInt32:
+			e.buf = appendInt32(e.buf, f.key, int32(f.n))
+		case kindInt64:
+			e.buf = appendInt64(e.buf, f.key, int64(f.n))
+		case kindUint:
+			e.buf = appendUint(e.buf, f.key, uint(f.n))
+		case kindUint8:
+			e.buf = appendUint8(e.buf, f.key, uint8(f.n))
+		case kindUint16:
+			e.buf = appendUint16(e.buf, f.key, uint16(f.n))
+		case kindUint32:
+			e.buf = appendUint32(e.buf, f.key, uint32(f.n))
+		case kindUint64:
+			e.buf = appendUint64(e.buf, f.key, f.n)
+		case kindFloat32:
+			e.buf = appendFloat32(e.buf, f.key, math.Float32frombits(uint32(f.n)))
+		case kindFloat64:
+			e.buf = appendFloat64(e.buf, f.key, math.Float64frombits(f.n))
+		case kindTime:
+			e.buf = appendTime(e.buf, f.key, f.t)
+		case kindDur:
+			e.buf = appendDuration(e.buf, f.key, time.Duration(f.n))
+		case kindInterface:
+			e.buf = appendInterface(e.buf, f.key, f.v)
+		}
+		// Release the values as the event goes back to the pool.
+		*f = stagedField{}
+	}
+	e.staged = e.staged[:0]
+}
@@ -0,0 +1,106 @@
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingMarshaler counts the times it is marshaled.
+type countingMarshaler struct {
+	n *int
+}
+
+func (m countingMarshaler) MarshalJSON() ([]byte, error) {
+	*m.n++
+	return []byte(`"marshaled"`), nil
+}
+
+func TestDeferFields(t *testing.T) {
+	fields := func(e *Event) *Event {
+		return e.Str("str", "foo").
+			StrUnsafe("unsafe", "bar").
+			Bytes("bytes", []byte("a\tb")).
+			RawJSON("raw", []byte(`{"a":[1,2]}`)).
+			Base64("b64", []byte{0xde, 0xad, 0xbe, 0xef}).
+			AnErr("nil", nil).
+			Err(errors.New("some error")).
+			Bool("bool", true).
+			Int("int", -1).
+			Int8("int8", -2).
+			Int16("int16", -3).
+			Int32("int32", -4).
+			Int64("int64", -5).
+			Uint("uint", 6).
+			Uint8("uint8", 7).
+			Uint16("uint16", 8).
+			Uint32("uint32", 9).
+			Uint64("uint64", 10).
+			Float32("float32", 11.5).
+			Float64("float64", -12.5).
+			Dict("dict", Dict().Str("a", "b")).
+			Time("time", time.Time{}).
+			Dur("dur", time.Second).
+			TimeDiff("diff", time.Unix(10, 0), time.Unix(0, 0)).
+			Interface("obj", map[string]int{"a": 1})
+	}
+	want := &bytes.Buffer{}
+	fields(New(want).Info()).Msg("msg")
+	got := &bytes.Buffer{}
+	fields(New(got).DeferFields().Info()).Msg("msg")
+	if got.String() != want.String() {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestDeferFieldsDropped(t *testing.T) {
+	var n int
+	out := &bytes.Buffer{}
+	log := New(out).DeferFields().
+		Filter(FieldEquals("path", "/health")).
+		Sample(&FirstNSampler{N: 1})
+	for i := 0; i < 3; i++ {
+		log.Info().Interface("obj", countingMarshaler{&n}).Msg("sampled")
+	}
+	log.Info().Interface("obj", countingMarshaler{&n}).Str("path", "/health").Msg("filtered")
+	if n != 1 {
+		t.Errorf("marshaled %d times, want 1", n)
+	}
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","obj":"marshaled","message":"sampled"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestDeferFieldsReusedBytes(t *testing.T) {
+	out := &bytes.Buffer{}
+	b := []byte(`"a"`)
+	e := New(out).DeferFields().Info().Bytes("bytes", b).RawJSON("raw", b).Base64("b64", b)
+	// The caller can reuse b once the fields are added, as with the events
+	// serializing their fields immediately.
+	copy(b, `"b"`)
+	e.Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","bytes":"\"a\"","raw":"a","b64":"ImEi"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestDeferFieldsDroppedRelease(t *testing.T) {
+	log := New(&bytes.Buffer{}).DeferFields().Sample(&FirstNSampler{N: 0})
+	e := log.Info().Interface("obj", map[string]int{"a": 1})
+	e.Msg("sampled")
+	for _, f := range e.staged[:cap(e.staged)] {
+		if f.v != nil {
+			t.Errorf("staged value kept by a dropped event: %v", f.v)
+		}
+	}
+}
+
+func TestDeferFieldsHooks(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).DeferFields().Hook(NewRedactHook("password"))
+	log.Info().Str("user", "bob").Str("password", "secret").Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","user":"bob","password":"`+RedactedValue+`"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,25 @@
+package zerolog
+
+import "strings"
+
+// DemoteFunc returns the new level of an event of level lvl with the error
+// err and true, or false if the level should not be changed.
+type DemoteFunc func(lvl Level, err error) (Level, bool)
+
+// DemoteErrorEquals returns a rule demoting events above level to with the
+// error target to level to.
+//
+//     log = log.Demote(zerolog.DemoteErrorEquals(io.ErrUnexpectedEOF, zerolog.WarnLevel))
+func DemoteErrorEquals(target error, to Level) DemoteFunc {
+	return func(lvl Level, err error) (Level, bool) {
+		return to, lvl > to && lvl != NoLevel && err == target
+	}
+}
+
+// DemoteErrorContains returns a rule demoting events above level to with an
+// error message containing substr to level to.
+func DemoteErrorContains(substr string, to Level) DemoteFunc {
+	return func(lvl Level, err error) (Level, bool) {
+		return to, lvl > to && lvl != NoLevel && strings.Contains(err.Error(), substr)
+	}
+}
@@ -0,0 +1,42 @@
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestDemote(t *testing.T) {
+	skipIfNoDebug(t)
+	TimestampFunc = func() time.Time {
+		return time.Date(2001, time.February, 3, 4, 5, 6, 7, time.UTC)
+	}
+	defer func() {
+		TimestampFunc = time.Now
+	}()
+	out := &bytes.Buffer{}
+	lw := &levelWriter{}
+	log := New(MultiLevelWriter(out, lw)).
+		Demote(DemoteErrorEquals(io.ErrUnexpectedEOF, WarnLevel)).
+		Demote(DemoteErrorContains("connection reset", InfoLevel)).
+		With().Timestamp().Str("foo", "bar").Logger()
+	log.Error().Err(io.ErrUnexpectedEOF).Msg("demoted")
+	log.Error().AnErr("cause", errors.New("read: connection reset by peer")).Msg("demoted")
+	log.Error().Err(errors.New("boom")).Msg("kept")
+	log.Debug().Err(io.ErrUnexpectedEOF).Msg("not promoted")
+	want := `{"time":"2001-02-03T04:05:06Z","level":"warn","foo":"bar","error":"unexpected EOF","message":"demoted"}` + "\n" +
+		`{"time":"2001-02-03T04:05:06Z","level":"info","foo":"bar","cause":"read: connection reset by peer","message":"demoted"}` + "\n" +
+		`{"time":"2001-02-03T04:05:06Z","level":"error","foo":"bar","error":"boom","message":"kept"}` + "\n" +
+		`{"time":"2001-02-03T04:05:06Z","level":"debug","foo":"bar","error":"unexpected EOF","message":"not promoted"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, want)
+	}
+	levels := []Level{WarnLevel, InfoLevel, ErrorLevel, DebugLevel}
+	for i, op := range lw.ops {
+		if op.l != levels[i] {
+			t.Errorf("write %d: got level %v, want %v", i, op.l, levels[i])
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package zerolog
+
+// An Emitter creates the events of a logger reusing a single event and its
+// buffer, without the sync.Pool round-trips of the Logger methods. It is
+// meant for the tight loops of a dedicated goroutine emitting millions of
+// events.
+//
+// An Emitter is NOT safe for concurrent use: it must be used by a single
+// goroutine, and each event must be sent with Msg or Msgf before the next
+// one is started. The writer must not retain the written slice, as required
+// by io.Writer.
+type Emitter struct {
+	l Logger
+	e *Event
+}
+
+// Emitter returns an Emitter creating the events of l.
+func (l Logger) Emitter() *Emitter {
+	return &Emitter{
+		l: l,
+		e: &Event{buf: make([]byte, 0, EventBufferSize), pinned: true},
+	}
+}
+
+// Trace starts a new message with trace level.
+//
+// You must call Msg on the returned event in order to send the event.
+func (em *Emitter) Trace() *Event {
+	if !DebugEnabled {
+		return disabledEvent
+	}
+	return em.newEvent(TraceLevel)
+}
+
+// Debug starts a new message with debug level.
+//
+// You must call Msg on the returned event in order to send the event.
+func (em *Emitter) Debug() *Event {
+	if !DebugEnabled {
+		return disabledEvent
+	}
+	return em.newEvent(DebugLevel)
+}
+
+// Info starts a new message with info level.
+//
+// You must call Msg on the returned event in order to send the event.
+func (em *Emitter) Info() *Event {
+	return em.newEvent(InfoLevel)
+}
+
+// Warn starts a new message with warn level.
+//
+// You must call Msg on the returned event in order to send the event.
+func (em *Emitter) Warn() *Event {
+	return em.newEvent(WarnLevel)
+}
+
+// Error starts a new message with error level.
+//
+// You must call Msg on the returned event in order to send the event.
+func (em *Emitter) Error() *Event {
+	return em.newEvent(ErrorLevel)
+}
+
+// WithLevel starts a new message with level. Like Logger.WithLevel, it does
+// not terminate the program or stop the goroutine with the fatal and panic
+// levels.
+//
+// You must call Msg on the returned event in order to send the event.
+func (em *Emitter) WithLevel(level Level) *Event {
+	if level == Disabled {
+		return disabledEvent
+	}
+	return em.newEvent(level)
+}
+
+// Log starts a new message with no level.
+//
+// You must call Msg on the returned event in order to send the event.
+func (em *Emitter) Log() *Event {
+	return em.newEvent(NoLevel)
+}
+
+func (em *Emitter) newEvent(level Level) *Event {
+	if !em.l.should(level) {
+		return disabledEvent
+	}
+	em.e.reset(em.l.w, level)
+	em.l.initEvent(em.e, level, nil)
+	return em.e
+}
@@ -0,0 +1,39 @@
+package zerolog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestEmitter(t *testing.T) {
+	out := &bytes.Buffer{}
+	em := New(out).Level(InfoLevel).With().Str("app", "a").Logger().Emitter()
+	em.Info().Int("n", 1).Msg("first")
+	em.Debug().Int("n", 2).Msg("dropped")
+	em.Warn().Int("n", 3).Msg("")
+	want := `{"level":"info","app":"a","n":1,"message":"first"}` + "\n" +
+		`{"level":"warn","app":"a","n":3}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestEmitterNoPool(t *testing.T) {
+	em := New(&bytes.Buffer{}).Emitter()
+	e := em.Info()
+	e.Msg("")
+	if got := em.Info(); got != e {
+		t.Error("Emitter did not reuse its event")
+	}
+}
+
+func TestEmitterAllocs(t *testing.T) {
+	em := New(ioutil.Discard).With().Str("app", "a").Logger().Emitter()
+	allocs := testing.AllocsPerRun(100, func() {
+		em.Info().Int("n", 1).Msg("msg")
+	})
+	if allocs != 0 {
+		t.Errorf("allocs = %v, want 0", allocs)
+	}
+}
@@ -0,0 +1,143 @@
+package zerolog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by ConfigureFromEnv.
+const (
+	// EnvLevel sets the global level (see ParseLevel).
+	EnvLevel = "ZEROLOG_LEVEL"
+	// EnvFormat sets the output format: json (default) or console.
+	EnvFormat = "ZEROLOG_FORMAT"
+	// EnvTimeFormat sets TimeFieldFormat. The unix value renders times as
+	// UNIX timestamps, rfc3339, rfc3339nano and kitchen select the matching
+	// layouts, other values are used as layouts.
+	EnvTimeFormat = "ZEROLOG_TIME_FORMAT"
+	// EnvNoColor disables colors of the console format when set to a true
+	// value (see strconv.ParseBool). The NO_COLOR convention is also honored.
+	EnvNoColor = "ZEROLOG_NO_COLOR"
+	// EnvDisableSampling disables sampling when set to a true value.
+	EnvDisableSampling = "ZEROLOG_DISABLE_SAMPLING"
+	// EnvComponentLevels sets component levels as a comma separated list of
+	// name=level pairs (e.g. db=debug,http=warn).
+	EnvComponentLevels = "ZEROLOG_COMPONENT_LEVELS"
+)
+
+// ConfigureFromEnv configures the global settings from the ZEROLOG_*
+// environment variables and returns a timestamped logger writing to
+// os.Stderr in the configured format. Unset or empty variables keep their
+// current setting.
+//
+// If a variable is invalid, the global settings are left untouched and a JSON
+// logger is returned with the error.
+//
+//     log.Logger, err = zerolog.ConfigureFromEnv()
+func ConfigureFromEnv() (Logger, error) {
+	return configureFromEnv(os.LookupEnv, os.Stderr)
+}
+
+type envConfig struct {
+	level           *Level
+	console         bool
+	timeFormat      *string
+	noColor         bool
+	disableSampling *bool
+	components      map[string]Level
+}
+
+func configureFromEnv(lookup func(string) (string, bool), w io.Writer) (Logger, error) {
+	c, err := parseEnv(lookup)
+	if err != nil {
+		return New(w).With().Timestamp().Logger(), err
+	}
+	if c.level != nil {
+		SetGlobalLevel(*c.level)
+	}
+	if c.timeFormat != nil {
+		TimeFieldFormat = *c.timeFormat
+	}
+	if c.disableSampling != nil {
+		DisableSampling(*c.disableSampling)
+	}
+	for name, l := range c.components {
+		SetComponentLevel(name, l)
+	}
+	if c.console {
+		w = ConsoleWriter{Out: w, NoColor: c.noColor}
+	}
+	return New(w).With().Timestamp().Logger(), nil
+}
+
+func parseEnv(env func(string) (string, bool)) (c envConfig, err error) {
+	// Empty variables, common in container templates, are treated as unset.
+	lookup := func(key string) (string, bool) {
+		v, ok := env(key)
+		v = strings.TrimSpace(v)
+		return v, ok && v != ""
+	}
+	if v, ok := lookup(EnvLevel); ok {
+		l, err := ParseLevel(v)
+		if err != nil {
+			return c, fmt.Errorf("%s: %v", EnvLevel, err)
+		}
+		c.level = &l
+	}
+	if v, ok := lookup(EnvFormat); ok {
+		switch strings.ToLower(v) {
+		case "json":
+		case "console":
+			c.console = true
+		default:
+			return c, fmt.Errorf("%s: unknown format: %q", EnvFormat, v)
+		}
+	}
+	if v, ok := lookup(EnvTimeFormat); ok {
+		switch strings.ToLower(v) {
+		case "unix":
+			v = ""
+		case "rfc3339":
+			v = time.RFC3339
+		case "rfc3339nano":
+			v = time.RFC3339Nano
+		case "kitchen":
+			v = time.Kitchen
+		}
+		c.timeFormat = &v
+	}
+	if _, ok := lookup("NO_COLOR"); ok {
+		c.noColor = true
+	}
+	if v, ok := lookup(EnvNoColor); ok {
+		if c.noColor, err = strconv.ParseBool(v); err != nil {
+			return c, fmt.Errorf("%s: %v", EnvNoColor, err)
+		}
+	}
+	if v, ok := lookup(EnvDisableSampling); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return c, fmt.Errorf("%s: %v", EnvDisableSampling, err)
+		}
+		c.disableSampling = &b
+	}
+	if v, ok := lookup(EnvComponentLevels); ok {
+		c.components = map[string]Level{}
+		for _, pair := range strings.Split(v, ",") {
+			i := strings.IndexByte(pair, '=')
+			if i == -1 || strings.TrimSpace(pair[i+1:]) == "" {
+				return c, fmt.Errorf("%s: invalid component level: %q", EnvComponentLevels, pair)
+			}
+			l, err := ParseLevel(strings.TrimSpace(pair[i+1:]))
+			if err != nil {
+				return c, fmt.Errorf("%s: %v", EnvComponentLevels, err)
+			}
+			c.components[strings.TrimSpace(pair[:i])] = l
+		}
+	}
+	return c, nil
+}
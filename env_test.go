@@ -0,0 +1,92 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func TestConfigureFromEnv(t *testing.T) {
+	defer SetGlobalLevel(GlobalLevel())
+	defer func(f string) { TimeFieldFormat = f }(TimeFieldFormat)
+	defer func(f func() time.Time) { TimestampFunc = f }(TimestampFunc)
+	defer UnsetComponentLevel("envdb")
+	TimestampFunc = func() time.Time { return time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC) }
+
+	out := &bytes.Buffer{}
+	log, err := configureFromEnv(envLookup(map[string]string{
+		EnvLevel:           "WARN",
+		EnvFormat:          "console",
+		EnvTimeFormat:      "unix",
+		EnvNoColor:         "true",
+		EnvComponentLevels: "envdb=debug",
+	}), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := GlobalLevel(); got != WarnLevel {
+		t.Errorf("invalid global level: %v", got)
+	}
+	if TimeFieldFormat != "" {
+		t.Errorf("invalid time format: %q", TimeFieldFormat)
+	}
+	if l, ok := ComponentLevel("envdb"); !ok || l != DebugLevel {
+		t.Errorf("invalid component level: %v, %v", l, ok)
+	}
+	log.Info().Msg("filtered")
+	log.Warn().Str("foo", "bar").Msg("kept")
+	want := time.Unix(981173106, 0).Format(time.Kitchen) + " WRN kept foo=bar\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+}
+
+func TestConfigureFromEnvEmpty(t *testing.T) {
+	defer SetGlobalLevel(GlobalLevel())
+	defer func(f string) { TimeFieldFormat = f }(TimeFieldFormat)
+	SetGlobalLevel(InfoLevel)
+	TimeFieldFormat = time.RFC3339
+	_, err := configureFromEnv(envLookup(map[string]string{
+		EnvLevel:           "",
+		EnvFormat:          "",
+		EnvTimeFormat:      " ",
+		EnvNoColor:         "",
+		EnvDisableSampling: "",
+		EnvComponentLevels: "",
+	}), &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := GlobalLevel(); got != InfoLevel {
+		t.Errorf("global level changed to %v", got)
+	}
+	if TimeFieldFormat != time.RFC3339 {
+		t.Errorf("time format changed to %q", TimeFieldFormat)
+	}
+}
+
+func TestConfigureFromEnvError(t *testing.T) {
+	defer SetGlobalLevel(GlobalLevel())
+	SetGlobalLevel(TraceLevel)
+	for _, env := range []map[string]string{
+		{EnvLevel: "info", EnvFormat: "xml"},
+		{EnvLevel: "info", EnvComponentLevels: "db"},
+		{EnvLevel: "info", EnvDisableSampling: "maybe"},
+		{EnvLevel: "loud"},
+		{EnvComponentLevels: "db="},
+	} {
+		if _, err := configureFromEnv(envLookup(env), &bytes.Buffer{}); err == nil {
+			t.Errorf("%v: expected an error", env)
+		}
+		if got := GlobalLevel(); got != TraceLevel {
+			t.Errorf("%v: global level changed to %v", env, got)
+		}
+	}
+}
@@ -1,21 +1,16 @@
 package zerolog
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
-	"sync"
 	"time"
 )
 
-var eventPool = &sync.Pool{
-	New: func() interface{} {
-		return &Event{
-			buf: make([]byte, 0, 500),
-		}
-	},
-}
-
 // Event represents a log event. It is instanced by one of the level method of
 // Logger and finalized by the Msg or Msgf method.
 type Event struct {
@@ -24,6 +19,30 @@ type Event struct {
 	level   Level
 	enabled bool
 	done    func(msg string)
+	filters []FilterFunc
+	sampler MessageSampler
+	once    *onceKey
+	// demoters and the position of the level field in buf, used to rewrite
+	// the level of demoted events.
+	demoters []DemoteFunc
+	levelPos int
+	levelEnd int
+	hooks     []boundHook
+	ctx       context.Context
+	component *componentLevel
+	onError   func(err error)
+	// bufHooks and the transformations of the serialized event they
+	// registered with EventBuffer.Transform.
+	bufHooks   []BufferHook
+	transforms []func(p []byte) []byte
+	// deferred is set for the events of loggers created with DeferFields,
+	// staging their fields until they are known to be written.
+	deferred    bool
+	staged      []stagedField
+	stagedBytes []byte
+	// pinned is set for the event of an Emitter, which is never returned
+	// to the pool.
+	pinned bool
 }
 
 func newEvent(w LevelWriter, level Level, enabled bool) *Event {
@@ -31,26 +50,48 @@ func newEvent(w LevelWriter, level Level, enabled bool) *Event {
 		return &Event{}
 	}
 	e := eventPool.Get().(*Event)
-	e.buf = e.buf[:1]
-	e.buf[0] = '{'
+	e.reset(w, level)
+	return e
+}
+
+// reset prepares e, a pooled or an Emitter event, for a new enabled event.
+func (e *Event) reset(w LevelWriter, level Level) {
+	e.buf = appendBeginMarker(e.buf[:0])
 	e.w = w
 	e.level = level
 	e.enabled = true
-	return e
+	e.filters = nil
+	e.sampler = nil
+	e.once = nil
+	e.demoters = nil
+	e.levelPos, e.levelEnd = 0, 0
+	e.hooks = nil
+	e.ctx = nil
+	e.component = nil
+	e.onError = nil
+	e.bufHooks = nil
+	e.transforms = e.transforms[:0]
+	e.deferred = false
+	e.staged = e.staged[:0]
+	e.stagedBytes = e.stagedBytes[:0]
 }
 
 func (e *Event) write() (err error) {
 	if !e.enabled {
 		return nil
 	}
-	e.buf = append(e.buf, '}', '\n')
-	_, err = e.w.WriteLevel(e.level, e.buf)
-	eventPool.Put(e)
+	e.buf = appendLineBreak(appendEndMarker(e.buf))
+	p := e.buf
+	for _, f := range e.transforms {
+		p = f(p)
+	}
+	_, err = e.w.WriteLevel(e.level, p)
+	putEvent(e)
 	return
 }
 
 // Enabled return false if the *Event is going to be filtered out by
-// log level or sampling.
+// log level, sampling or a filter.
 func (e *Event) Enabled() bool {
 	return e.enabled
 }
@@ -60,17 +101,68 @@ func (e *Event) Enabled() bool {
 // NOTICE: once this method is called, the *Event should be disposed.
 // Calling Msg twice can have unexpected result.
 func (e *Event) Msg(msg string) {
+	e.msg(msg)
+}
+
+// msg implements Msg. Msg and Msgf both call it so the hooks of the events
+// are run at the same stack depth, as expected by the caller hook.
+func (e *Event) msg(msg string) {
 	if !e.enabled {
+		if e.done != nil {
+			// The event has been filtered out after its creation.
+			e.done(msg)
+		}
 		return
 	}
+	if msg != "" && e.filtered(MessageFieldName, msg) {
+		e.msg(msg)
+		// The event is disposed once its message is set.
+		e.releaseStaged()
+		putEvent(e)
+		return
+	}
+	if (e.sampler != nil && !e.sampler.SampleMessage(e.level, msg)) ||
+		(e.once != nil && !e.once.take()) {
+		e.enabled = false
+		e.msg(msg)
+		e.releaseStaged()
+		putEvent(e)
+		return
+	}
+	// The event is written: the staged fields are serialized before the
+	// hooks run, so the hooks see and can rewrite all the fields.
+	e.serializeStaged()
+	runHooks(e, msg)
 	if msg != "" {
 		e.buf = appendString(e.buf, MessageFieldName, msg)
 	}
+	for _, h := range e.bufHooks {
+		h.RunBuffer((*EventBuffer)(e))
+	}
 	if e.done != nil {
 		defer e.done(msg)
 	}
+	// Keep the handler as write puts e back in the pool.
+	onError := e.onError
 	if err := e.write(); err != nil {
-		fmt.Fprintf(os.Stderr, "zerolog: could not write event: %v", err)
+		handleError(onError, err)
+	}
+}
+
+// handleError reports err, a write error or a *HookError, with onError, the
+// error handler of a logger, or ErrorHandler. If both are nil, err is printed
+// on os.Stderr.
+func handleError(onError func(err error), err error) {
+	if onError == nil {
+		onError = ErrorHandler
+	}
+	switch {
+	case onError != nil:
+		onError(err)
+	case isHookError(err):
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	default:
+		fmt.Fprintf(os.Stderr, "zerolog: could not write event: %v\n", err)
 	}
 }
 
@@ -79,19 +171,10 @@ func (e *Event) Msg(msg string) {
 // NOTICE: once this methid is called, the *Event should be disposed.
 // Calling Msg twice can have unexpected result.
 func (e *Event) Msgf(format string, v ...interface{}) {
-	if !e.enabled {
+	if !e.enabled && e.done == nil {
 		return
 	}
-	msg := fmt.Sprintf(format, v...)
-	if msg != "" {
-		e.buf = appendString(e.buf, MessageFieldName, msg)
-	}
-	if e.done != nil {
-		defer e.done(msg)
-	}
-	if err := e.write(); err != nil {
-		fmt.Fprintf(os.Stderr, "zerolog: could not write event: %v", err)
-	}
+	e.msg(fmt.Sprintf(format, v...))
 }
 
 // Dict adds the field key with a dict to the event context.
@@ -100,8 +183,36 @@ func (e *Event) Dict(key string, dict *Event) *Event {
 	if !e.enabled {
 		return e
 	}
-	e.buf = append(append(appendKey(e.buf, key), dict.buf...), '}')
-	eventPool.Put(dict)
+	e.serializeStaged()
+	e.buf = appendObject(e.buf, key, dict.buf)
+	putEvent(dict)
+	return e
+}
+
+// LogObjectMarshaler is implemented by types logging themselves as a set of
+// fields, without reflection. The zerologgen tool generates implementations
+// for struct types.
+type LogObjectMarshaler interface {
+	MarshalZerologObject(e *Event)
+}
+
+// Object adds the field key with the fields of obj as a dict to the *Event
+// context. If obj is nil, no field is added.
+func (e *Event) Object(key string, obj LogObjectMarshaler) *Event {
+	if !e.enabled || obj == nil {
+		return e
+	}
+	d := Dict()
+	obj.MarshalZerologObject(d)
+	return e.Dict(key, d)
+}
+
+// EmbedObject adds the fields of obj to the *Event context.
+func (e *Event) EmbedObject(obj LogObjectMarshaler) *Event {
+	if !e.enabled || obj == nil {
+		return e
+	}
+	obj.MarshalZerologObject(e)
 	return e
 }
 
@@ -112,22 +223,166 @@ func Dict() *Event {
 	return newEvent(levelWriterAdapter{ioutil.Discard}, 0, true)
 }
 
+// Ctx adds the context.Context ctx to the *Event, overriding the one of the
+// logger set with Context.Ctx. The context is not serialized: it is made
+// available to hooks with GetCtx so they can extract per-request values, such
+// as trace ids, at write time.
+func (e *Event) Ctx(ctx context.Context) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.ctx = ctx
+	return e
+}
+
+// GetCtx returns the context.Context of the *Event set with Ctx or
+// Context.Ctx, or context.Background() if none.
+func (e *Event) GetCtx() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
+// GetComponent returns the name of the component of the logger which created
+// the *Event, as set with Context.Component, or an empty string.
+func (e *Event) GetComponent() string {
+	if e.component == nil {
+		return ""
+	}
+	return e.component.name
+}
+
 // Str adds the field key with val as a string to the *Event context.
+//
+// If the field matches one of the logger's filters, the event is dropped and
+// subsequent fields are ignored.
 func (e *Event) Str(key, val string) *Event {
-	if !e.enabled {
+	if !e.enabled || e.filtered(key, val) {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindStr, s: val})
+	}
 	e.buf = appendString(e.buf, key, val)
 	return e
 }
 
+// StrUnsafe adds the field key with val as a string to the *Event context,
+// like Str, but without escaping nor validating val. It saves the cost of
+// the validation for high frequency strings known to be clean, such as
+// internal identifiers: val must be valid UTF-8 and must not contain
+// control characters, double quotes nor backslashes, or the event is
+// invalid JSON.
+func (e *Event) StrUnsafe(key, val string) *Event {
+	if !e.enabled || e.filtered(key, val) {
+		return e
+	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindStrUnsafe, s: val})
+	}
+	e.buf = appendStringUnsafe(e.buf, key, val)
+	return e
+}
+
+// Bytes adds the field key with val as a string to the *Event context, like
+// Str. val is escaped as it is appended to the event, without being copied
+// to a string first.
+func (e *Event) Bytes(key string, val []byte) *Event {
+	if !e.enabled || len(e.filters) > 0 && e.filtered(key, string(val)) {
+		return e
+	}
+	if e.deferred {
+		return e.stageBytes(key, kindBytes, val)
+	}
+	e.buf = appendBytes(e.buf, key, val)
+	return e
+}
+
+// Base64 adds the field key with val encoded with Base64Encoding as a string
+// to the *Event context. val is encoded directly into the event, without an
+// intermediate string.
+func (e *Event) Base64(key string, val []byte) *Event {
+	if !e.enabled {
+		return e
+	}
+	if e.deferred {
+		return e.stageBytes(key, kindBase64, val)
+	}
+	e.buf = appendBase64(e.buf, key, val)
+	return e
+}
+
+// RawJSON adds the field key with b, an already encoded JSON value, to the
+// *Event context. b is appended as is, without copy nor escaping pass. If
+// RawJSONValidation is true, b is validated first, without allocation, and
+// added as a string like Bytes if invalid. With the zerolog_bson build tag,
+// b is transcoded to BSON.
+func (e *Event) RawJSON(key string, b []byte) *Event {
+	if !e.enabled {
+		return e
+	}
+	if RawJSONValidation && !json.Valid(b) {
+		return e.Bytes(key, b)
+	}
+	if e.deferred {
+		return e.stageBytes(key, kindRawJSON, b)
+	}
+	e.buf = appendRawJSON(e.buf, key, b)
+	return e
+}
+
+// demote changes the level of e if one of its demoters matches err.
+func (e *Event) demote(err error) {
+	if err == nil {
+		return
+	}
+	for _, f := range e.demoters {
+		if lvl, ok := f(e.level, err); ok && lvl != e.level {
+			e.setLevel(lvl)
+			return
+		}
+	}
+}
+
+// setLevel changes the level of e, rewriting its level field.
+func (e *Event) setLevel(lvl Level) {
+	e.level = lvl
+	if e.levelEnd == 0 {
+		return
+	}
+	rest := append([]byte(nil), e.buf[e.levelEnd:]...)
+	e.buf = appendString(e.buf[:e.levelPos], LevelFieldName, lvl.String())
+	e.levelEnd = len(e.buf)
+	e.buf = append(e.buf, rest...)
+}
+
+// filtered disables e and returns true if the field key with val matches
+// one of e's filters.
+func (e *Event) filtered(key, val string) bool {
+	for _, f := range e.filters {
+		if f(key, val) {
+			// The event is not put back in the pool as the caller still
+			// holds it.
+			e.enabled = false
+			return true
+		}
+	}
+	return false
+}
+
 // AnErr adds the field key with err as a string to the *Event context.
 // If err is nil, no field is added.
 func (e *Event) AnErr(key string, err error) *Event {
 	if !e.enabled {
 		return e
 	}
-	e.buf = appendErrorKey(e.buf, key, err)
+	if e.deferred {
+		e.stage(stagedField{key: key, kind: kindErr, v: err})
+	} else {
+		e.buf = appendErrorKey(e.buf, key, err)
+	}
+	e.demote(err)
 	return e
 }
 
@@ -138,7 +393,56 @@ func (e *Event) Err(err error) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		e.stage(stagedField{key: ErrorFieldName, kind: kindErr, v: err})
+	} else {
+		e.buf = appendError(e.buf, err)
+	}
+	e.demote(err)
+	return e
+}
+
+// ErrorFielder is implemented by errors carrying structured fields. They are
+// added to their entry of the error chain by ErrChain.
+type ErrorFielder interface {
+	ErrorFields(e *Event)
+}
+
+// maxErrorChain limits the number of errors of the chain added by ErrChain.
+const maxErrorChain = 32
+
+// ErrChain adds the field "error" with err as a string to the *Event context
+// like Err, and the field zerolog.ErrorChainFieldName with the chain of
+// errors wrapped by err, as returned by errors.Unwrap. Each error of the
+// chain is rendered as an object with its type, its message and the fields
+// added by its ErrorFields method if it implements ErrorFielder:
+//
+//     {"error":"query: timeout","error_chain":[{"type":"*fmt.wrapError","message":"query: timeout"},{"type":"*net.OpError","message":"timeout"}]}
+//
+// If err is nil, no field is added.
+func (e *Event) ErrChain(err error) *Event {
+	if !e.enabled || err == nil {
+		return e
+	}
+	e.serializeStaged()
 	e.buf = appendError(e.buf, err)
+	var chain []*Event
+	for c := err; c != nil && len(chain) < maxErrorChain; c = errors.Unwrap(c) {
+		d := Dict().Str("type", fmt.Sprintf("%T", c)).Str("message", c.Error())
+		if ef, ok := c.(ErrorFielder); ok {
+			ef.ErrorFields(d)
+		}
+		chain = append(chain, d)
+	}
+	objs := make([][]byte, len(chain))
+	for i, d := range chain {
+		objs[i] = d.buf
+	}
+	e.buf = appendObjectArray(e.buf, ErrorChainFieldName, objs)
+	for _, d := range chain {
+		putEvent(d)
+	}
+	e.demote(err)
 	return e
 }
 
@@ -147,6 +451,13 @@ func (e *Event) Bool(key string, b bool) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		var n uint64
+		if b {
+			n = 1
+		}
+		return e.stage(stagedField{key: key, kind: kindBool, n: n})
+	}
 	e.buf = appendBool(e.buf, key, b)
 	return e
 }
@@ -156,6 +467,9 @@ func (e *Event) Int(key string, i int) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindInt, n: uint64(i)})
+	}
 	e.buf = appendInt(e.buf, key, i)
 	return e
 }
@@ -165,6 +479,9 @@ func (e *Event) Int8(key string, i int8) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindInt8, n: uint64(i)})
+	}
 	e.buf = appendInt8(e.buf, key, i)
 	return e
 }
@@ -174,6 +491,9 @@ func (e *Event) Int16(key string, i int16) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindInt16, n: uint64(i)})
+	}
 	e.buf = appendInt16(e.buf, key, i)
 	return e
 }
@@ -183,6 +503,9 @@ func (e *Event) Int32(key string, i int32) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindInt32, n: uint64(i)})
+	}
 	e.buf = appendInt32(e.buf, key, i)
 	return e
 }
@@ -192,6 +515,9 @@ func (e *Event) Int64(key string, i int64) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindInt64, n: uint64(i)})
+	}
 	e.buf = appendInt64(e.buf, key, i)
 	return e
 }
@@ -201,6 +527,9 @@ func (e *Event) Uint(key string, i uint) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindUint, n: uint64(i)})
+	}
 	e.buf = appendUint(e.buf, key, i)
 	return e
 }
@@ -210,6 +539,9 @@ func (e *Event) Uint8(key string, i uint8) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindUint8, n: uint64(i)})
+	}
 	e.buf = appendUint8(e.buf, key, i)
 	return e
 }
@@ -219,6 +551,9 @@ func (e *Event) Uint16(key string, i uint16) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindUint16, n: uint64(i)})
+	}
 	e.buf = appendUint16(e.buf, key, i)
 	return e
 }
@@ -228,6 +563,9 @@ func (e *Event) Uint32(key string, i uint32) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindUint32, n: uint64(i)})
+	}
 	e.buf = appendUint32(e.buf, key, i)
 	return e
 }
@@ -237,6 +575,9 @@ func (e *Event) Uint64(key string, i uint64) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindUint64, n: uint64(i)})
+	}
 	e.buf = appendUint64(e.buf, key, i)
 	return e
 }
@@ -246,6 +587,9 @@ func (e *Event) Float32(key string, f float32) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindFloat32, n: uint64(math.Float32bits(f))})
+	}
 	e.buf = appendFloat32(e.buf, key, f)
 	return e
 }
@@ -255,6 +599,9 @@ func (e *Event) Float64(key string, f float64) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindFloat64, n: math.Float64bits(f)})
+	}
 	e.buf = appendFloat64(e.buf, key, f)
 	return e
 }
@@ -265,15 +612,42 @@ func (e *Event) Timestamp() *Event {
 	if !e.enabled {
 		return e
 	}
+	e.serializeStaged()
 	e.buf = appendTimestamp(e.buf)
 	return e
 }
 
+// Caller adds the file:line of the caller with the zerolog.CallerFieldName
+// key, as formatted by zerolog.CallerMarshalFunc. skip is the number of
+// additional stack frames to ascend, for helpers logging on behalf of their
+// callers.
+func (e *Event) Caller(skip ...int) *Event {
+	sk := CallerSkipFrameCount
+	if len(skip) > 0 {
+		sk += skip[0]
+	}
+	return e.caller(sk)
+}
+
+func (e *Event) caller(skip int) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.serializeStaged()
+	if loc, ok := caller(skip); ok {
+		e.buf = appendString(e.buf, CallerFieldName, loc)
+	}
+	return e
+}
+
 // Time adds the field key with t formated as string using zerolog.TimeFieldFormat.
 func (e *Event) Time(key string, t time.Time) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindTime, t: t})
+	}
 	e.buf = appendTime(e.buf, key, t)
 	return e
 }
@@ -285,6 +659,9 @@ func (e *Event) Dur(key string, d time.Duration) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindDur, n: uint64(d)})
+	}
 	e.buf = appendDuration(e.buf, key, d)
 	return e
 }
@@ -296,6 +673,7 @@ func (e *Event) TimeDiff(key string, t time.Time, start time.Time) *Event {
 	if !e.enabled {
 		return e
 	}
+	e.serializeStaged()
 	var d time.Duration
 	if t.After(start) {
 		d = t.Sub(start)
@@ -309,6 +687,9 @@ func (e *Event) Interface(key string, i interface{}) *Event {
 	if !e.enabled {
 		return e
 	}
+	if e.deferred {
+		return e.stage(stagedField{key: key, kind: kindInterface, v: i})
+	}
 	e.buf = appendInterface(e.buf, key, i)
 	return e
 }
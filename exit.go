@@ -0,0 +1,32 @@
+package zerolog
+
+import "sync"
+
+var (
+	exitHooksMu sync.Mutex
+	exitHooks   []func()
+)
+
+// RegisterExitHook registers f to be called by the Msg method of fatal
+// events before ExitFunc exits the process, so asynchronous and buffered
+// writers can be flushed and the message explaining why the process died is
+// not lost. Exit hooks are called once, in the reverse order of their
+// registration.
+//
+// Exit hooks are not called for panic events as the panic may be recovered.
+func RegisterExitHook(f func()) {
+	exitHooksMu.Lock()
+	defer exitHooksMu.Unlock()
+	exitHooks = append(exitHooks, f)
+}
+
+// runExitHooks calls and unregisters the exit hooks.
+func runExitHooks() {
+	exitHooksMu.Lock()
+	hooks := exitHooks
+	exitHooks = nil
+	exitHooksMu.Unlock()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+}
@@ -0,0 +1,30 @@
+package zerolog
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestExitHooks(t *testing.T) {
+	var calls []string
+	ExitFunc = func(code int) {
+		calls = append(calls, "exit")
+	}
+	defer func() {
+		ExitFunc = os.Exit
+	}()
+	RegisterExitHook(func() { calls = append(calls, "first") })
+	RegisterExitHook(func() { calls = append(calls, "second") })
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Fatal().Msg("boom")
+	log.Fatal().Msg("boom")
+	if want := []string{"second", "first", "exit", "exit"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("invalid calls: got %v, want %v", calls, want)
+	}
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"fatal","message":"boom"}`+"\n"+`{"level":"fatal","message":"boom"}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+}
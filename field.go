@@ -1,16 +1,78 @@
+// +build !zerolog_bson
+
 package zerolog
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"strconv"
 	"time"
 )
 
+// decodeIfBinaryToBytes returns p as is: JSON events need no transcoding.
+func decodeIfBinaryToBytes(p []byte) []byte {
+	return p
+}
+
+func appendBeginMarker(dst []byte) []byte {
+	return append(dst, '{')
+}
+
+func appendEndMarker(dst []byte) []byte {
+	return append(dst, '}')
+}
+
+func appendLineBreak(dst []byte) []byte {
+	return append(dst, '\n')
+}
+
+// appendObjectData appends the fields encoded in o, as found in a logger
+// context, to the object being built in dst.
+func appendObjectData(dst []byte, o []byte) []byte {
+	if len(o) == 0 {
+		return dst
+	}
+	if len(dst) > 1 {
+		dst = append(dst, ',')
+	}
+	return append(dst, o...)
+}
+
+// appendObject appends the object o started with appendBeginMarker as
+// the value of key.
+func appendObject(dst []byte, key string, o []byte) []byte {
+	return append(appendKey(dst, key), appendEndMarker(o)...)
+}
+
+// appendObjectArray appends the objects objs, started with
+// appendBeginMarker, as an array value of key.
+func appendObjectArray(dst []byte, key string, objs [][]byte) []byte {
+	dst = append(appendKey(dst, key), '[')
+	for i, o := range objs {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendEndMarker(append(dst, o...))
+	}
+	return append(dst, ']')
+}
+
+// encodeKey returns the encoding of key appended by appendKey, with its
+// quotes and the colon, for InternKeys.
+func encodeKey(key string) string {
+	return string(append(appendJSONString(nil, key), ':'))
+}
+
 func appendKey(dst []byte, key string) []byte {
 	if len(dst) > 1 {
 		dst = append(dst, ',')
 	}
+	if t := loadKeyTable(); t != nil {
+		if enc, ok := t.lookup(key); ok {
+			return append(dst, enc...)
+		}
+	}
 	dst = appendJSONString(dst, key)
 	return append(dst, ':')
 }
@@ -19,6 +81,32 @@ func appendString(dst []byte, key, val string) []byte {
 	return appendJSONString(appendKey(dst, key), val)
 }
 
+// appendStringUnsafe appends val without escaping it nor validating it.
+func appendStringUnsafe(dst []byte, key, val string) []byte {
+	return append(append(append(appendKey(dst, key), '"'), val...), '"')
+}
+
+// appendBytes appends val as a string, escaped like appendString, without
+// copying it.
+func appendBytes(dst []byte, key string, val []byte) []byte {
+	return appendJSONString(appendKey(dst, key), bytesString(val))
+}
+
+// appendBase64 appends val encoded with Base64Encoding as a string, encoding
+// it directly into dst.
+func appendBase64(dst []byte, key string, val []byte) []byte {
+	dst = append(appendKey(dst, key), '"')
+	n := len(dst)
+	dst = append(dst, make([]byte, Base64Encoding.EncodedLen(len(val)))...)
+	Base64Encoding.Encode(dst[n:], val)
+	return append(dst, '"')
+}
+
+// appendRawJSON appends the JSON value b as is.
+func appendRawJSON(dst []byte, key string, b []byte) []byte {
+	return append(appendKey(dst, key), b...)
+}
+
 func appendErrorKey(dst []byte, key string, err error) []byte {
 	if err == nil {
 		return dst
@@ -35,51 +123,51 @@ func appendBool(dst []byte, key string, val bool) []byte {
 }
 
 func appendInt(dst []byte, key string, val int) []byte {
-	return strconv.AppendInt(appendKey(dst, key), int64(val), 10)
+	return appendIntValue(appendKey(dst, key), int64(val))
 }
 
 func appendInt8(dst []byte, key string, val int8) []byte {
-	return strconv.AppendInt(appendKey(dst, key), int64(val), 10)
+	return appendIntValue(appendKey(dst, key), int64(val))
 }
 
 func appendInt16(dst []byte, key string, val int16) []byte {
-	return strconv.AppendInt(appendKey(dst, key), int64(val), 10)
+	return appendIntValue(appendKey(dst, key), int64(val))
 }
 
 func appendInt32(dst []byte, key string, val int32) []byte {
-	return strconv.AppendInt(appendKey(dst, key), int64(val), 10)
+	return appendIntValue(appendKey(dst, key), int64(val))
 }
 
 func appendInt64(dst []byte, key string, val int64) []byte {
-	return strconv.AppendInt(appendKey(dst, key), int64(val), 10)
+	return appendIntValue(appendKey(dst, key), int64(val))
 }
 
 func appendUint(dst []byte, key string, val uint) []byte {
-	return strconv.AppendUint(appendKey(dst, key), uint64(val), 10)
+	return appendUintValue(appendKey(dst, key), uint64(val))
 }
 
 func appendUint8(dst []byte, key string, val uint8) []byte {
-	return strconv.AppendUint(appendKey(dst, key), uint64(val), 10)
+	return appendUintValue(appendKey(dst, key), uint64(val))
 }
 
 func appendUint16(dst []byte, key string, val uint16) []byte {
-	return strconv.AppendUint(appendKey(dst, key), uint64(val), 10)
+	return appendUintValue(appendKey(dst, key), uint64(val))
 }
 
 func appendUint32(dst []byte, key string, val uint32) []byte {
-	return strconv.AppendUint(appendKey(dst, key), uint64(val), 10)
+	return appendUintValue(appendKey(dst, key), uint64(val))
 }
 
 func appendUint64(dst []byte, key string, val uint64) []byte {
-	return strconv.AppendUint(appendKey(dst, key), uint64(val), 10)
+	return appendUintValue(appendKey(dst, key), uint64(val))
 }
 
 func appendFloat32(dst []byte, key string, val float32) []byte {
-	return strconv.AppendFloat(appendKey(dst, key), float64(val), 'f', -1, 32)
+	return appendFloatValue(appendKey(dst, key), float64(val))
 }
 
 func appendFloat64(dst []byte, key string, val float64) []byte {
-	return strconv.AppendFloat(appendKey(dst, key), float64(val), 'f', -1, 32)
+	return appendFloatValue(appendKey(dst, key), float64(val))
 }
 
 func appendTime(dst []byte, key string, t time.Time) []byte {
@@ -90,6 +178,9 @@ func appendTime(dst []byte, key string, t time.Time) []byte {
 }
 
 func appendTimestamp(dst []byte) []byte {
+	if c, _ := cachedTimestamp.Load().(*timestampCache); c != nil && c.format == TimeFieldFormat && c.format != "" {
+		return append(append(append(appendKey(dst, TimestampFieldName), '"'), c.b...), '"')
+	}
 	return appendTime(dst, TimestampFieldName, TimestampFunc())
 }
 
@@ -107,3 +198,102 @@ func appendInterface(dst []byte, key string, i interface{}) []byte {
 	}
 	return append(appendKey(dst, key), marshaled...)
 }
+
+// rewriteFields returns a copy of the object o, started with
+// appendBeginMarker and not yet terminated, in which the values of the fields
+// f returns true for are replaced by the returned string. Nested objects are
+// rewritten recursively if nested is true. It returns nil if no field is
+// replaced.
+func rewriteFields(o []byte, f fieldRewriter, nested bool) []byte {
+	var dst []byte
+	for i := 1; i < len(o); {
+		start := i
+		if o[i] == ',' {
+			i++
+		}
+		keyEnd := skipJSONValue(o, i)
+		if keyEnd >= len(o) || o[keyEnd] != ':' {
+			return nil
+		}
+		valEnd := skipJSONValue(o, keyEnd+1)
+		if valEnd == keyEnd+1 {
+			return nil
+		}
+		key := unquoteJSONString(o[i:keyEnd])
+		val := o[keyEnd+1 : valEnd]
+		i = valEnd
+		var s []byte
+		isString := val[0] == '"'
+		if isString {
+			s = unquoteJSONString(val)
+		}
+		repl, ok := f(key, s, isString)
+		var obj []byte
+		if !ok && nested && val[0] == '{' {
+			obj = rewriteFields(val[:len(val)-1], f, true)
+		}
+		if !ok && obj == nil {
+			if dst != nil {
+				dst = append(dst, o[start:valEnd]...)
+			}
+			continue
+		}
+		if dst == nil {
+			dst = append(make([]byte, 0, len(o)+50), o[:start]...)
+		}
+		if ok {
+			dst = appendString(dst, string(key), repl)
+		} else {
+			dst = appendObject(dst, string(key), obj)
+		}
+	}
+	return dst
+}
+
+// skipJSONValue returns the offset following the JSON value starting at
+// o[i].
+func skipJSONValue(o []byte, i int) int {
+	depth := 0
+	for ; i < len(o); i++ {
+		switch o[i] {
+		case '"':
+			for i++; i < len(o) && o[i] != '"'; i++ {
+				if o[i] == '\\' {
+					i++
+				}
+			}
+			if depth == 0 {
+				return i + 1
+			}
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+			if depth < 0 {
+				return i
+			}
+		case ',', ':':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(o)
+}
+
+// unquoteJSONString decodes the JSON string s. The result shares the memory
+// of s if it contains no escape sequence.
+func unquoteJSONString(s []byte) []byte {
+	if len(s) < 2 {
+		return nil
+	}
+	if bytes.IndexByte(s, '\\') == -1 {
+		return s[1 : len(s)-1]
+	}
+	var str string
+	json.Unmarshal(s, &str)
+	return []byte(str)
+}
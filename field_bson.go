@@ -0,0 +1,490 @@
+// +build zerolog_bson
+
+package zerolog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// BSON element types used by the encoder.
+// See http://bsonspec.org/spec.html for details.
+const (
+	bsonDouble   = 0x01
+	bsonString   = 0x02
+	bsonDocument = 0x03
+	bsonArray    = 0x04
+	bsonBool     = 0x08
+	bsonDatetime = 0x09
+	bsonNull     = 0x0A
+	bsonInt32    = 0x10
+	bsonInt64    = 0x12
+)
+
+// appendBeginMarker reserves the int32 holding the document size. The size
+// is set by appendEndMarker once the document is complete.
+func appendBeginMarker(dst []byte) []byte {
+	return append(dst, 0, 0, 0, 0)
+}
+
+// appendEndMarker terminates the document starting at dst[0] and sets its
+// size.
+func appendEndMarker(dst []byte) []byte {
+	dst = append(dst, 0)
+	binary.LittleEndian.PutUint32(dst, uint32(len(dst)))
+	return dst
+}
+
+// appendLineBreak is a no-op as BSON documents are self-delimited.
+func appendLineBreak(dst []byte) []byte {
+	return dst
+}
+
+// appendObjectData appends the elements encoded in o, as found in a logger
+// context, to the document being built in dst.
+func appendObjectData(dst []byte, o []byte) []byte {
+	return append(dst, o...)
+}
+
+// appendObject appends the document o started with appendBeginMarker as
+// the value of key.
+func appendObject(dst []byte, key string, o []byte) []byte {
+	return append(appendKey(dst, bsonDocument, key), appendEndMarker(o)...)
+}
+
+// appendObjectArray appends the documents objs, started with
+// appendBeginMarker, as an array value of key.
+func appendObjectArray(dst []byte, key string, objs [][]byte) []byte {
+	a := appendBeginMarker(make([]byte, 0, 100))
+	for i, o := range objs {
+		a = appendObject(a, strconv.Itoa(i), o)
+	}
+	return append(appendKey(dst, bsonArray, key), appendEndMarker(a)...)
+}
+
+// encodeKey returns the encoding of key for InternKeys. The BSON keys are
+// not interned: appendKey does not use the encoding.
+func encodeKey(key string) string {
+	return key
+}
+
+// appendKey appends the element type and the key as a cstring. As cstrings
+// can't contain NUL bytes, those are removed from the key.
+func appendKey(dst []byte, typ byte, key string) []byte {
+	dst = append(dst, typ)
+	for i := 0; i < len(key); i++ {
+		if key[i] != 0 {
+			dst = append(dst, key[i])
+		}
+	}
+	return append(dst, 0)
+}
+
+func appendInt32Value(dst []byte, val int32) []byte {
+	return append(dst, byte(val), byte(val>>8), byte(val>>16), byte(val>>24))
+}
+
+func appendInt64Value(dst []byte, val int64) []byte {
+	return append(dst,
+		byte(val), byte(val>>8), byte(val>>16), byte(val>>24),
+		byte(val>>32), byte(val>>40), byte(val>>48), byte(val>>56))
+}
+
+// appendStringValue appends s as a BSON string, replacing invalid UTF-8
+// sequences by the unicode replacement character.
+func appendStringValue(dst []byte, s string) []byte {
+	start := len(dst)
+	dst = append(dst, 0, 0, 0, 0)
+	if utf8.ValidString(s) {
+		dst = append(dst, s...)
+	} else {
+		for i := 0; i < len(s); {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				dst = append(dst, "\ufffd"...)
+			} else {
+				dst = append(dst, s[i:i+size]...)
+			}
+			i += size
+		}
+	}
+	dst = append(dst, 0)
+	binary.LittleEndian.PutUint32(dst[start:], uint32(len(dst)-start-4))
+	return dst
+}
+
+func appendString(dst []byte, key, val string) []byte {
+	return appendStringValue(appendKey(dst, bsonString, key), val)
+}
+
+// appendStringUnsafe appends val without validating it.
+func appendStringUnsafe(dst []byte, key, val string) []byte {
+	dst = appendKey(dst, bsonString, key)
+	start := len(dst)
+	dst = append(append(dst, 0, 0, 0, 0), val...)
+	dst = append(dst, 0)
+	binary.LittleEndian.PutUint32(dst[start:], uint32(len(dst)-start-4))
+	return dst
+}
+
+// appendBytes appends val as a string, validated like appendString, without
+// copying it.
+func appendBytes(dst []byte, key string, val []byte) []byte {
+	return appendString(dst, key, bytesString(val))
+}
+
+// appendBase64 appends val encoded with Base64Encoding as a string, encoding
+// it directly into dst.
+func appendBase64(dst []byte, key string, val []byte) []byte {
+	dst = appendKey(dst, bsonString, key)
+	start := len(dst)
+	l := Base64Encoding.EncodedLen(len(val))
+	dst = append(dst, make([]byte, 4+l+1)...)
+	binary.LittleEndian.PutUint32(dst[start:], uint32(l+1))
+	Base64Encoding.Encode(dst[start+4:], val)
+	return dst
+}
+
+// appendRawJSON transcodes the JSON value b to BSON. An invalid value is
+// appended as a string.
+func appendRawJSON(dst []byte, key string, b []byte) []byte {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	out, err := appendJSONValue(dst, key, d)
+	if err != nil {
+		return appendBytes(dst, key, b)
+	}
+	return out
+}
+
+func appendErrorKey(dst []byte, key string, err error) []byte {
+	if err == nil {
+		return dst
+	}
+	return appendString(dst, key, err.Error())
+}
+
+func appendError(dst []byte, err error) []byte {
+	return appendErrorKey(dst, ErrorFieldName, err)
+}
+
+func appendBool(dst []byte, key string, val bool) []byte {
+	dst = appendKey(dst, bsonBool, key)
+	if val {
+		return append(dst, 1)
+	}
+	return append(dst, 0)
+}
+
+func appendInt(dst []byte, key string, val int) []byte {
+	return appendInt64(dst, key, int64(val))
+}
+
+func appendInt8(dst []byte, key string, val int8) []byte {
+	return appendInt32(dst, key, int32(val))
+}
+
+func appendInt16(dst []byte, key string, val int16) []byte {
+	return appendInt32(dst, key, int32(val))
+}
+
+func appendInt32(dst []byte, key string, val int32) []byte {
+	return appendInt32Value(appendKey(dst, bsonInt32, key), val)
+}
+
+func appendInt64(dst []byte, key string, val int64) []byte {
+	return appendInt64Value(appendKey(dst, bsonInt64, key), val)
+}
+
+func appendUint(dst []byte, key string, val uint) []byte {
+	return appendUint64(dst, key, uint64(val))
+}
+
+func appendUint8(dst []byte, key string, val uint8) []byte {
+	return appendInt32(dst, key, int32(val))
+}
+
+func appendUint16(dst []byte, key string, val uint16) []byte {
+	return appendInt32(dst, key, int32(val))
+}
+
+func appendUint32(dst []byte, key string, val uint32) []byte {
+	return appendInt64(dst, key, int64(val))
+}
+
+// appendUint64 stores val as an int64 as BSON has no unsigned integer type.
+// Values overflowing an int64 are stored as a double.
+func appendUint64(dst []byte, key string, val uint64) []byte {
+	if val > math.MaxInt64 {
+		return appendFloat64(dst, key, float64(val))
+	}
+	return appendInt64(dst, key, int64(val))
+}
+
+func appendFloat32(dst []byte, key string, val float32) []byte {
+	return appendFloat64(dst, key, float64(val))
+}
+
+func appendFloat64(dst []byte, key string, val float64) []byte {
+	return appendInt64Value(appendKey(dst, bsonDouble, key), int64(math.Float64bits(val)))
+}
+
+// appendTime stores t as a BSON UTC datetime. TimeFieldFormat is ignored as
+// the datetime type is natively handled by BSON consumers.
+func appendTime(dst []byte, key string, t time.Time) []byte {
+	ms := t.Unix()*1e3 + int64(t.Nanosecond())/1e6
+	return appendInt64Value(appendKey(dst, bsonDatetime, key), ms)
+}
+
+func appendTimestamp(dst []byte) []byte {
+	return appendTime(dst, TimestampFieldName, TimestampFunc())
+}
+
+func appendDuration(dst []byte, key string, d time.Duration) []byte {
+	if DurationFieldInteger {
+		return appendInt64(dst, key, int64(d/DurationFieldUnit))
+	}
+	return appendFloat64(dst, key, float64(d)/float64(DurationFieldUnit))
+}
+
+// appendInterface marshals i to JSON using reflection and transcodes the
+// result to BSON, preserving the order of object keys.
+func appendInterface(dst []byte, key string, i interface{}) []byte {
+	marshaled, err := json.Marshal(i)
+	if err != nil {
+		return appendString(dst, key, fmt.Sprintf("marshaling error: %v", err))
+	}
+	d := json.NewDecoder(bytes.NewReader(marshaled))
+	d.UseNumber()
+	out, err := appendJSONValue(dst, key, d)
+	if err != nil {
+		return appendString(dst, key, fmt.Sprintf("marshaling error: %v", err))
+	}
+	return out
+}
+
+// appendJSONValue reads the next JSON value from d and appends it as key.
+func appendJSONValue(dst []byte, key string, d *json.Decoder) ([]byte, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return dst, err
+	}
+	switch t := tok.(type) {
+	case nil:
+		return appendKey(dst, bsonNull, key), nil
+	case bool:
+		return appendBool(dst, key, t), nil
+	case string:
+		return appendString(dst, key, t), nil
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return appendInt64(dst, key, i), nil
+		}
+		f, err := t.Float64()
+		if err != nil {
+			return dst, err
+		}
+		return appendFloat64(dst, key, f), nil
+	case json.Delim:
+		o := appendBeginMarker(make([]byte, 0, 100))
+		for n := 0; d.More(); n++ {
+			k := strconv.Itoa(n)
+			if t == '{' {
+				kt, err := d.Token()
+				if err != nil {
+					return dst, err
+				}
+				k, _ = kt.(string)
+			}
+			if o, err = appendJSONValue(o, k, d); err != nil {
+				return dst, err
+			}
+		}
+		// Consume the closing delimiter.
+		if _, err := d.Token(); err != nil {
+			return dst, err
+		}
+		typ := byte(bsonDocument)
+		if t == '[' {
+			typ = bsonArray
+		}
+		return append(appendKey(dst, typ, key), appendEndMarker(o)...), nil
+	}
+	return dst, fmt.Errorf("unexpected token %v", tok)
+}
+
+// rewriteFields returns a copy of the document o, started with
+// appendBeginMarker and not yet terminated, in which the values of the fields
+// f returns true for are replaced by the returned string. Nested documents
+// are rewritten recursively if nested is true. It returns nil if no field is
+// replaced.
+func rewriteFields(o []byte, f fieldRewriter, nested bool) []byte {
+	var dst []byte
+	for i := 4; i < len(o); {
+		start := i
+		typ := o[i]
+		n := bytes.IndexByte(o[i+1:], 0)
+		if n == -1 {
+			return nil
+		}
+		key := o[i+1 : i+1+n]
+		valStart := i + 2 + n
+		size := bsonValueSize(typ, o[valStart:])
+		if size < 0 || valStart+size > len(o) {
+			return nil
+		}
+		val := o[valStart : valStart+size]
+		i = valStart + size
+		var s []byte
+		isString := typ == bsonString
+		if isString {
+			s = val[4 : len(val)-1]
+		}
+		repl, ok := f(key, s, isString)
+		var obj []byte
+		if !ok && nested && typ == bsonDocument {
+			obj = rewriteFields(val[:len(val)-1], f, true)
+		}
+		if !ok && obj == nil {
+			if dst != nil {
+				dst = append(dst, o[start:i]...)
+			}
+			continue
+		}
+		if dst == nil {
+			dst = append(make([]byte, 0, len(o)+50), o[:start]...)
+		}
+		if ok {
+			dst = appendString(dst, string(key), repl)
+		} else {
+			dst = appendObject(dst, string(key), obj)
+		}
+	}
+	return dst
+}
+
+// decodeIfBinaryToBytes transcodes p, a sequence of BSON documents, to JSON
+// events terminated by line breaks as written by the JSON encoder, so the
+// JSON consumers like ConsoleWriter accept the BSON events. p is returned
+// as is if it is not a sequence of BSON documents.
+func decodeIfBinaryToBytes(p []byte) []byte {
+	var dst []byte
+	for i := 0; i < len(p); {
+		if len(p)-i < 5 {
+			return p
+		}
+		n := int(binary.LittleEndian.Uint32(p[i:]))
+		if n < 5 || n > len(p)-i || p[i+n-1] != 0 {
+			return p
+		}
+		var ok bool
+		if dst, ok = appendBSONDocumentJSON(dst, p[i:i+n], '{', '}'); !ok {
+			return p
+		}
+		dst = append(dst, '\n')
+		i += n
+	}
+	if dst == nil {
+		return p
+	}
+	return dst
+}
+
+// appendBSONDocumentJSON appends the BSON document or array doc as a JSON
+// object or array delimited by begin and end. It returns false if doc is
+// invalid.
+func appendBSONDocumentJSON(dst []byte, doc []byte, begin, end byte) ([]byte, bool) {
+	dst = append(dst, begin)
+	for i := 4; i < len(doc)-1; {
+		typ := doc[i]
+		n := bytes.IndexByte(doc[i+1:], 0)
+		if n == -1 {
+			return dst, false
+		}
+		key := doc[i+1 : i+1+n]
+		valStart := i + 2 + n
+		size := bsonValueSize(typ, doc[valStart:])
+		if size < 0 || valStart+size > len(doc)-1 {
+			return dst, false
+		}
+		val := doc[valStart : valStart+size]
+		i = valStart + size
+		if dst[len(dst)-1] != begin {
+			dst = append(dst, ',')
+		}
+		if begin == '{' {
+			dst = append(appendJSONString(dst, string(key)), ':')
+		}
+		var ok bool
+		if dst, ok = appendBSONValueJSON(dst, typ, val); !ok {
+			return dst, false
+		}
+	}
+	return append(dst, end), true
+}
+
+// appendBSONValueJSON appends the BSON value val of type typ as JSON, as
+// the JSON encoder would have encoded it.
+func appendBSONValueJSON(dst []byte, typ byte, val []byte) ([]byte, bool) {
+	switch typ {
+	case bsonDouble:
+		return appendFloatValue(dst, math.Float64frombits(binary.LittleEndian.Uint64(val))), true
+	case bsonString:
+		if len(val) < 5 {
+			return dst, false
+		}
+		return appendJSONString(dst, string(val[4:len(val)-1])), true
+	case bsonDocument:
+		return appendBSONDocumentJSON(dst, val, '{', '}')
+	case bsonArray:
+		return appendBSONDocumentJSON(dst, val, '[', ']')
+	case bsonBool:
+		return strconv.AppendBool(dst, val[0] != 0), true
+	case bsonDatetime:
+		ms := int64(binary.LittleEndian.Uint64(val))
+		t := time.Unix(ms/1e3, ms%1e3*1e6).UTC()
+		if TimeFieldFormat == "" {
+			return appendIntValue(dst, t.Unix()), true
+		}
+		return appendJSONString(dst, t.Format(TimeFieldFormat)), true
+	case bsonNull:
+		return append(dst, "null"...), true
+	case bsonInt32:
+		return appendIntValue(dst, int64(int32(binary.LittleEndian.Uint32(val)))), true
+	case bsonInt64:
+		return appendIntValue(dst, int64(binary.LittleEndian.Uint64(val))), true
+	}
+	return dst, false
+}
+
+// bsonValueSize returns the size of the value of type typ starting at b[0]
+// or -1 if the type is unknown.
+func bsonValueSize(typ byte, b []byte) int {
+	switch typ {
+	case bsonDouble, bsonDatetime, bsonInt64:
+		return 8
+	case bsonInt32:
+		return 4
+	case bsonBool:
+		return 1
+	case bsonNull:
+		return 0
+	case bsonString:
+		if len(b) < 4 {
+			return -1
+		}
+		return 4 + int(binary.LittleEndian.Uint32(b))
+	case bsonDocument, bsonArray:
+		if len(b) < 4 {
+			return -1
+		}
+		return int(binary.LittleEndian.Uint32(b))
+	}
+	return -1
+}
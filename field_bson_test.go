@@ -0,0 +1,149 @@
+// +build zerolog_bson
+
+package zerolog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// bsonDoc builds a BSON document from already encoded elements.
+func bsonDoc(elems ...[]byte) []byte {
+	doc := []byte{0, 0, 0, 0}
+	for _, e := range elems {
+		doc = append(doc, e...)
+	}
+	doc = append(doc, 0)
+	binary.LittleEndian.PutUint32(doc, uint32(len(doc)))
+	return doc
+}
+
+func bsonElem(typ byte, key string, val []byte) []byte {
+	return append(append(append([]byte{typ}, key...), 0), val...)
+}
+
+func bsonStr(key, val string) []byte {
+	v := make([]byte, 4, 4+len(val)+1)
+	binary.LittleEndian.PutUint32(v, uint32(len(val)+1))
+	v = append(append(v, val...), 0)
+	return bsonElem(bsonString, key, v)
+}
+
+func bsonI32(key string, i int32) []byte {
+	v := make([]byte, 4)
+	binary.LittleEndian.PutUint32(v, uint32(i))
+	return bsonElem(bsonInt32, key, v)
+}
+
+func bsonI64(typ byte, key string, i int64) []byte {
+	v := make([]byte, 8)
+	binary.LittleEndian.PutUint64(v, uint64(i))
+	return bsonElem(typ, key, v)
+}
+
+func TestBSONFields(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Log().
+		Str("foo", "bar").
+		Bool("bool", true).
+		Int8("int8", 2).
+		Int64("int64", 5).
+		Uint64("uint64", math.MaxUint64).
+		Float64("float64", 12).
+		Time("time", time.Unix(1, 5e6)).
+		Msg("")
+	want := bsonDoc(
+		bsonStr("foo", "bar"),
+		bsonElem(bsonBool, "bool", []byte{1}),
+		bsonI32("int8", 2),
+		bsonI64(bsonInt64, "int64", 5),
+		bsonI64(bsonDouble, "uint64", int64(math.Float64bits(math.MaxUint64))),
+		bsonI64(bsonDouble, "float64", int64(math.Float64bits(12))),
+		bsonI64(bsonDatetime, "time", 1005),
+	)
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestBSONContextAndDict(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().
+		Str("ctx", "val").
+		Dict("dict", Dict().Int32("n", 1)).
+		Logger()
+	log.Info().Dict("edict", Dict().Str("a", "b")).Msg("hello")
+	want := bsonDoc(
+		bsonStr("level", "info"),
+		bsonStr("ctx", "val"),
+		bsonElem(bsonDocument, "dict", bsonDoc(bsonI32("n", 1))),
+		bsonElem(bsonDocument, "edict", bsonDoc(bsonStr("a", "b"))),
+		bsonStr("message", "hello"),
+	)
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestBSONInterface(t *testing.T) {
+	out := &bytes.Buffer{}
+	obj := struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+		Ptr  *int     `json:"ptr"`
+	}{
+		Name: "john",
+		Tags: []string{"a"},
+	}
+	New(out).Log().Interface("obj", obj).Msg("")
+	want := bsonDoc(
+		bsonElem(bsonDocument, "obj", bsonDoc(
+			bsonStr("name", "john"),
+			bsonElem(bsonArray, "tags", bsonDoc(bsonStr("0", "a"))),
+			bsonElem(bsonNull, "ptr", nil),
+		)),
+	)
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestBSONInvalidUTF8(t *testing.T) {
+	got := appendString(nil, "k\x00ey", "a\xffb")
+	if want := bsonStr("key", "a\ufffdb"); !bytes.Equal(got, want) {
+		t.Errorf("invalid encoding:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestBSONRedact(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Hook(NewRedactHook("password"))
+	log.Log().Str("user", "john").Int64("password", 1).Dict("d", Dict().Str("password", "x")).Msg("")
+	want := bsonDoc(
+		bsonStr("user", "john"),
+		bsonStr("password", "[REDACTED]"),
+		bsonElem(bsonDocument, "d", bsonDoc(bsonStr("password", "[REDACTED]"))),
+	)
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestBSONErrChain(t *testing.T) {
+	out := &bytes.Buffer{}
+	New(out).Log().ErrChain(errors.New("failed")).Msg("")
+	want := bsonDoc(
+		bsonStr("error", "failed"),
+		bsonElem(bsonArray, "error_chain", bsonDoc(
+			bsonElem(bsonDocument, "0", bsonDoc(bsonStr("type", "*errors.errorString"), bsonStr("message", "failed"))),
+		)),
+	)
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,32 @@
+package zerolog
+
+import "strings"
+
+// FilterFunc returns true if an event with the string field key set to val
+// should be dropped.
+type FilterFunc func(key, val string) bool
+
+// FieldEquals returns a filter matching the string field key set to val.
+func FieldEquals(key, val string) FilterFunc {
+	return func(k, v string) bool {
+		return k == key && v == val
+	}
+}
+
+// FieldContains returns a filter matching the string field key containing
+// substr.
+//
+//     log = log.Filter(zerolog.FieldContains("user_agent", "kube-probe"))
+func FieldContains(key, substr string) FilterFunc {
+	return func(k, v string) bool {
+		return k == key && strings.Contains(v, substr)
+	}
+}
+
+// FieldHasPrefix returns a filter matching the string field key starting with
+// prefix.
+func FieldHasPrefix(key, prefix string) FilterFunc {
+	return func(k, v string) bool {
+		return k == key && strings.HasPrefix(v, prefix)
+	}
+}
@@ -0,0 +1,59 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFilterContext(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Filter(FieldContains("user_agent", "kube-probe"))
+	probe := log.With().Str("user_agent", "kube-probe/1.7").Logger()
+	probe.Info().Msg("filtered")
+	probe.Log().Msg("filtered")
+	user := log.With().Str("user_agent", "Mozilla/5.0").Logger()
+	user.Info().Msg("routed")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","user_agent":"Mozilla/5.0","message":"routed"}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+}
+
+func TestFilterEvent(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).
+		Filter(FieldEquals("path", "/healthz")).
+		Filter(FieldHasPrefix(MessageFieldName, "noisy"))
+	e := log.Info().Str("path", "/healthz")
+	if e.Enabled() {
+		t.Error("filtered event still enabled")
+	}
+	e.Int("n", 1).Msg("filtered")
+	log.Info().Msg("noisy message")
+	log.Info().Msgf("noisy %s", "message")
+	log.Info().Str("path", "/").Msg("routed")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","path":"/","message":"routed"}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+}
+
+func TestFilterSiblings(t *testing.T) {
+	out := &bytes.Buffer{}
+	parent := New(out).Filter(FieldEquals("a", "1"))
+	child1 := parent.Filter(FieldEquals("b", "1"))
+	child2 := parent.Filter(FieldEquals("c", "1"))
+	child1.Log().Str("c", "1").Msg("")
+	child2.Log().Str("b", "1").Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"c":"1"}`+"\n"+`{"b":"1"}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+}
+
+func TestFilterFatalDone(t *testing.T) {
+	var called string
+	log := New(nil).Filter(FieldEquals("a", "1"))
+	e := log.newEvent(ErrorLevel, func(msg string) { called = msg })
+	e.Str("a", "1").Msg("done")
+	if called != "done" {
+		t.Errorf("done not called on filtered event: %q", called)
+	}
+}
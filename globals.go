@@ -1,7 +1,12 @@
 package zerolog
 
-import "time"
-import "sync/atomic"
+import (
+	"encoding/base64"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
 
 var (
 	// TimestampFieldName is the field name used for the timestamp field.
@@ -16,8 +21,45 @@ var (
 	// ErrorFieldName is the field name used for error fields.
 	ErrorFieldName = "error"
 
-	// SampleFieldName is the name of the field used to report sampling.
-	SampleFieldName = "sample"
+	// ErrorChainFieldName is the field name used by Event.ErrChain.
+	ErrorChainFieldName = "error_chain"
+
+	// CallerFieldName is the field name used for caller field.
+	CallerFieldName = "caller"
+
+	// ComponentFieldName is the field name used by Context.Component.
+	ComponentFieldName = "component"
+
+	// HostnameFieldName is the field name used by Context.Hostname.
+	HostnameFieldName = "hostname"
+
+	// PidFieldName is the field name used by Context.Pid.
+	PidFieldName = "pid"
+
+	// ExecutableFieldName is the field name used by Context.Executable.
+	ExecutableFieldName = "exe"
+
+	// ContainerIDFieldName is the field name used by Context.ContainerID.
+	ContainerIDFieldName = "container_id"
+
+	// VersionFieldName is the field name used by Context.BuildInfo for the
+	// version of the program.
+	VersionFieldName = "version"
+
+	// GitSHAFieldName is the field name used by Context.BuildInfo for the
+	// git commit of the program.
+	GitSHAFieldName = "git_sha"
+
+	// BuildTimeFieldName is the field name used by Context.BuildInfo for the
+	// build time of the program.
+	BuildTimeFieldName = "build_time"
+
+	// SampleRateFieldName is the field name used to report the sampling rate
+	// of samplers implementing SampleRater.
+	SampleRateFieldName = "sample_rate"
+
+	// SeverityFieldName is the field name used by Logger.Severity.
+	SeverityFieldName = "severity"
 
 	// TimeFieldFormat defines the time format of the Time field type.
 	// If set to an empty string, the time is formatted as an UNIX timestamp
@@ -34,6 +76,38 @@ var (
 	// DurationFieldInteger renders Dur fields as integer instead of float if
 	// set to true.
 	DurationFieldInteger = false
+
+	// ExitFunc is called by the Msg method of fatal events to exit the
+	// process. It can be replaced to run cleanup code or in tests.
+	ExitFunc = os.Exit
+
+	// RedactedValue replaces the values of the fields redacted by RedactHook.
+	RedactedValue = "[REDACTED]"
+
+	// CallerSkipFrameCount is the number of stack frames to skip to find the
+	// caller added by Event.Caller.
+	CallerSkipFrameCount = 2
+
+	// CallerMarshalFunc formats the caller field from the program counter,
+	// the file and the line of the caller. The formatted locations are
+	// cached by program counter, so it must be set before logging.
+	CallerMarshalFunc = func(pc uintptr, file string, line int) string {
+		return file + ":" + strconv.Itoa(line)
+	}
+
+	// Base64Encoding defines the encoding of the fields added with Base64.
+	Base64Encoding = base64.StdEncoding
+
+	// RawJSONValidation validates the values added with RawJSON, adding the
+	// invalid ones as strings so the events stay valid JSON. Trusted
+	// producers of already valid JSON can set it to false to skip the
+	// validation pass.
+	RawJSONValidation = true
+
+	// ErrorHandler is called when the writer of a logger fails to write an
+	// event, unless the logger has its own handler set with
+	// Logger.ErrorHandler. If nil, the error is printed on os.Stderr.
+	ErrorHandler func(err error)
 )
 
 var (
@@ -41,6 +115,10 @@ var (
 	disableSampling = new(uint32)
 )
 
+func init() {
+	SetGlobalLevel(TraceLevel)
+}
+
 // SetGlobalLevel sets the global override for log level. If this
 // values is raised, all Loggers will use at least this value.
 //
@@ -49,7 +127,8 @@ func SetGlobalLevel(l Level) {
 	atomic.StoreUint32(gLevel, uint32(l))
 }
 
-func globalLevel() Level {
+// GlobalLevel returns the current global log level.
+func GlobalLevel() Level {
 	return Level(atomic.LoadUint32(gLevel))
 }
 
@@ -63,5 +142,5 @@ func DisableSampling(v bool) {
 }
 
 func samplingDisabled() bool {
-	return atomic.LoadUint32(gLevel) == 1
+	return atomic.LoadUint32(disableSampling) == 1
 }
@@ -0,0 +1,141 @@
+package hlog
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+)
+
+// Field names of the access logs of AccessHandler and of the framework
+// middlewares of the contrib packages, such as ginhlog or echohlog.
+var (
+	// MethodFieldName is the field name used for the request method.
+	MethodFieldName = "method"
+
+	// URLFieldName is the field name used for the request URL.
+	URLFieldName = "url"
+
+	// RouteFieldName is the field name used for the matched route.
+	RouteFieldName = "route"
+
+	// StatusFieldName is the field name used for the response status.
+	StatusFieldName = "status"
+
+	// SizeFieldName is the field name used for the response size.
+	SizeFieldName = "size"
+
+	// DurationFieldName is the field name used for the request duration.
+	DurationFieldName = "duration"
+
+	// RemoteIPFieldName is the field name used for the client IP.
+	RemoteIPFieldName = "remote_ip"
+
+	// RequestIDFieldName is the field name used for the request id.
+	RequestIDFieldName = "request_id"
+
+	// PanicFieldName is the field name used for the value of a recovered
+	// panic.
+	PanicFieldName = "panic"
+
+	// StackFieldName is the field name used for the stack of a recovered
+	// panic.
+	StackFieldName = "stack"
+
+	// RequestIDHeader is the header the request id is read from and written
+	// to by the framework middlewares.
+	RequestIDHeader = "X-Request-Id"
+)
+
+// StatusLevel returns the level of the access log of a request with status:
+// info, or warn and error for 4xx and 5xx statuses.
+func StatusLevel(status int) zerolog.Level {
+	switch {
+	case status >= 500:
+		return zerolog.ErrorLevel
+	case status >= 400:
+		return zerolog.WarnLevel
+	default:
+		return zerolog.InfoLevel
+	}
+}
+
+// AccessEvent returns an event of l with the level of status, as returned by
+// StatusLevel, and the method, URL, route, status, size and duration of a
+// request started at start. The route is omitted if empty. Middlewares can
+// add their own fields to the returned event before sending it.
+func AccessEvent(l zerolog.Logger, method, url, route string, status int, size int64, start time.Time) *zerolog.Event {
+	e := l.WithLevel(StatusLevel(status))
+	if !e.Enabled() {
+		return e
+	}
+	e.Str(MethodFieldName, method).Str(URLFieldName, url)
+	if route != "" {
+		e.Str(RouteFieldName, route)
+	}
+	return e.Int(StatusFieldName, status).
+		Int64(SizeFieldName, size).
+		Dur(DurationFieldName, time.Since(start))
+}
+
+// RequestIDLogger returns l with the request id added using
+// RequestIDFieldName, and the id. If id is empty, a new one is generated.
+func RequestIDLogger(l zerolog.Logger, id string) (zerolog.Logger, string) {
+	if id == "" {
+		id = xid.New().String()
+	}
+	return l.With().Str(RequestIDFieldName, id).Logger(), id
+}
+
+// AccessHandler returns a handler logging the requests once handled with
+// AccessEvent.
+func AccessHandler() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			aw := &accessWriter{ResponseWriter: w}
+			next.ServeHTTP(aw, r)
+			status := aw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			AccessEvent(FromRequest(r), r.Method, r.URL.String(), "", status, aw.size, start).Msg("")
+		})
+	}
+}
+
+// accessWriter records the status and the size of a response.
+type accessWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (w *accessWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush implements the http.Flusher interface if the wrapped writer does.
+func (w *accessWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (w *accessWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
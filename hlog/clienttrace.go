@@ -0,0 +1,131 @@
+package hlog
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ClientTrace returns a copy of ctx with a httptrace.ClientTrace logging the
+// connection timings of an outbound request made with it, to diagnose slow
+// upstreams. When the first response byte is received, a debug event is
+// logged with the logger of ctx with the following fields:
+//
+//     dns            duration of the DNS lookup
+//     connect        duration of the TCP connection
+//     tls_handshake  duration of the TLS handshake
+//     ttfb           time to the first response byte, from getting a connection
+//     remote_addr    address of the connection
+//     conn_reused    whether the connection was reused from the pool
+//
+// The dns, connect and tls_handshake fields are only added for the steps
+// that took place, so they are missing for reused connections.
+//
+//     req = req.WithContext(hlog.ClientTrace(req.Context()))
+//     resp, err := http.DefaultClient.Do(req)
+func ClientTrace(ctx context.Context) context.Context {
+	t := &clientTrace{l: zerolog.Ctx(ctx)}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn:              t.getConn,
+		GotConn:              t.gotConn,
+		DNSStart:             t.dnsStart,
+		DNSDone:              t.dnsDone,
+		ConnectStart:         t.connectStart,
+		ConnectDone:          t.connectDone,
+		TLSHandshakeStart:    t.tlsHandshakeStart,
+		TLSHandshakeDone:     t.tlsHandshakeDone,
+		GotFirstResponseByte: t.gotFirstResponseByte,
+	})
+}
+
+// clientTrace records the timings of a request. Its methods may be called
+// concurrently, as connections are dialed in parallel.
+type clientTrace struct {
+	l zerolog.Logger
+
+	mu                                   sync.Mutex
+	start, dnsBegin, connBegin, tlsBegin time.Time
+	dns, connect, tlsHandshake           time.Duration
+	remoteAddr                           string
+	reused                               bool
+}
+
+func (t *clientTrace) getConn(hostPort string) {
+	t.mu.Lock()
+	t.start = time.Now()
+	t.mu.Unlock()
+}
+
+func (t *clientTrace) gotConn(info httptrace.GotConnInfo) {
+	t.mu.Lock()
+	if info.Conn != nil {
+		t.remoteAddr = info.Conn.RemoteAddr().String()
+	}
+	t.reused = info.Reused
+	t.mu.Unlock()
+}
+
+func (t *clientTrace) dnsStart(httptrace.DNSStartInfo) {
+	t.mu.Lock()
+	t.dnsBegin = time.Now()
+	t.mu.Unlock()
+}
+
+func (t *clientTrace) dnsDone(httptrace.DNSDoneInfo) {
+	t.mu.Lock()
+	t.dns = time.Since(t.dnsBegin)
+	t.mu.Unlock()
+}
+
+func (t *clientTrace) connectStart(network, addr string) {
+	t.mu.Lock()
+	t.connBegin = time.Now()
+	t.mu.Unlock()
+}
+
+func (t *clientTrace) connectDone(network, addr string, err error) {
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	t.connect = time.Since(t.connBegin)
+	t.mu.Unlock()
+}
+
+func (t *clientTrace) tlsHandshakeStart() {
+	t.mu.Lock()
+	t.tlsBegin = time.Now()
+	t.mu.Unlock()
+}
+
+func (t *clientTrace) tlsHandshakeDone(tls.ConnectionState, error) {
+	t.mu.Lock()
+	t.tlsHandshake = time.Since(t.tlsBegin)
+	t.mu.Unlock()
+}
+
+func (t *clientTrace) gotFirstResponseByte() {
+	e := t.l.Debug()
+	if !e.Enabled() {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.dnsBegin.IsZero() {
+		e.Dur("dns", t.dns)
+	}
+	if !t.connBegin.IsZero() {
+		e.Dur("connect", t.connect)
+	}
+	if !t.tlsBegin.IsZero() {
+		e.Dur("tls_handshake", t.tlsHandshake)
+	}
+	e.Dur("ttfb", time.Since(t.start)).
+		Str("remote_addr", t.remoteAddr).
+		Bool("conn_reused", t.reused).
+		Msg("http client trace")
+}
@@ -0,0 +1,63 @@
+package hlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestClientTrace(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	c := &http.Client{Transport: &http.Transport{}}
+
+	out := &bytes.Buffer{}
+	ctx := zerolog.New(out).WithContext(context.Background())
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", ts.URL, nil)
+		resp, err := c.Do(req.WithContext(ClientTrace(ctx)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	dec := json.NewDecoder(out)
+	for i, reused := range []bool{false, true} {
+		var m map[string]interface{}
+		if err := dec.Decode(&m); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if m["message"] != "http client trace" || m["level"] != "debug" {
+			t.Errorf("event %d: invalid message: %v", i, m)
+		}
+		if m["remote_addr"] != ts.Listener.Addr().String() {
+			t.Errorf("event %d: remote_addr = %v, want %v", i, m["remote_addr"], ts.Listener.Addr())
+		}
+		if m["conn_reused"] != reused {
+			t.Errorf("event %d: conn_reused = %v, want %v", i, m["conn_reused"], reused)
+		}
+		if _, ok := m["ttfb"]; !ok {
+			t.Errorf("event %d: missing ttfb", i)
+		}
+		if _, ok := m["connect"]; ok == reused {
+			t.Errorf("event %d: connect present = %v, want %v", i, ok, !reused)
+		}
+	}
+}
+
+func TestClientTraceDisabled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	resp, err := http.DefaultClient.Do(req.WithContext(ClientTrace(context.Background())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
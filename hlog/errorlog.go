@@ -0,0 +1,124 @@
+package hlog
+
+import (
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// errorLogRule classifies the messages of http.Server and
+// httputil.ReverseProxy matching re. The submatches of re are logged in
+// fields named by keys, an empty key meaning the message field.
+type errorLogRule struct {
+	re    *regexp.Regexp
+	level zerolog.Level
+	msg   string
+	keys  func() []string
+}
+
+var errorLogRules = []errorLogRule{
+	{
+		re:    regexp.MustCompile(`(?s)^http: panic serving (\S+): (.*?)\n(.*)$`),
+		level: zerolog.ErrorLevel,
+		msg:   "http: panic serving request",
+		keys:  func() []string { return []string{"remote_addr", "panic", "stack"} },
+	},
+	{
+		re:    regexp.MustCompile(`^http: TLS handshake error from (\S+): (.*)$`),
+		level: zerolog.DebugLevel,
+		msg:   "http: TLS handshake error",
+		keys:  func() []string { return []string{"remote_addr", zerolog.ErrorFieldName} },
+	},
+	{
+		re:    regexp.MustCompile(`^http: Accept error: (.*); retrying in (.*)$`),
+		level: zerolog.WarnLevel,
+		msg:   "http: accept error",
+		keys:  func() []string { return []string{zerolog.ErrorFieldName, "retry_in"} },
+	},
+	{
+		re:    regexp.MustCompile(`^(http: (?:superfluous response\.WriteHeader call|response\.Write(?:Header)? on hijacked connection)) from \S+ \((\S+)\)$`),
+		level: zerolog.WarnLevel,
+		keys:  func() []string { return []string{"", zerolog.CallerFieldName} },
+	},
+	{
+		re:    regexp.MustCompile(`^http: proxy error: (context canceled)$`),
+		level: zerolog.InfoLevel,
+		msg:   "http: proxy error",
+		keys:  func() []string { return []string{zerolog.ErrorFieldName} },
+	},
+	{
+		re:    regexp.MustCompile(`^http: proxy error: (.*)$`),
+		level: zerolog.ErrorLevel,
+		msg:   "http: proxy error",
+		keys:  func() []string { return []string{zerolog.ErrorFieldName} },
+	},
+	{
+		re:    regexp.MustCompile(`^httputil: ReverseProxy read error during body copy: (.*)$`),
+		level: zerolog.WarnLevel,
+		msg:   "httputil: ReverseProxy read error during body copy",
+		keys:  func() []string { return []string{zerolog.ErrorFieldName} },
+	},
+	{
+		re:    regexp.MustCompile(`^http2: .*$`),
+		level: zerolog.DebugLevel,
+	},
+}
+
+// errorLogWriter logs the messages written by the error logger of
+// http.Server and httputil.ReverseProxy.
+type errorLogWriter struct {
+	l zerolog.Logger
+}
+
+// Write implements the io.Writer interface.
+func (w errorLogWriter) Write(p []byte) (n int, err error) {
+	n = len(p)
+	msg := strings.TrimSuffix(string(p), "\n")
+	for _, r := range errorLogRules {
+		m := r.re.FindStringSubmatch(msg)
+		if m == nil {
+			continue
+		}
+		e := w.l.WithLevel(r.level)
+		if !e.Enabled() {
+			return
+		}
+		if r.msg != "" {
+			msg = r.msg
+		}
+		if r.keys != nil {
+			for i, key := range r.keys() {
+				if key == "" {
+					msg = m[i+1]
+				} else {
+					e.Str(key, m[i+1])
+				}
+			}
+		}
+		e.Msg(msg)
+		return
+	}
+	w.l.Error().Msg(msg)
+	return
+}
+
+// ErrorLogger returns a logger to set as the ErrorLog of http.Server or
+// httputil.ReverseProxy. Instead of logging all the messages at error level,
+// common messages are classified:
+//
+//     http: panic serving          error, with remote_addr, panic and stack fields
+//     http: TLS handshake error    debug, with remote_addr and error fields
+//     http: Accept error           warn, with error and retry_in fields
+//     superfluous WriteHeader call warn, with caller field
+//     http: proxy error            error, or info if the request was canceled
+//     ReverseProxy body copy error warn, with error field
+//     http2 messages               debug
+//
+// Other messages are logged at error level.
+//
+//     srv := &http.Server{ErrorLog: hlog.ErrorLogger(log)}
+func ErrorLogger(l zerolog.Logger) *log.Logger {
+	return log.New(errorLogWriter{l: l}, "", 0)
+}
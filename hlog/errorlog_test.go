@@ -0,0 +1,30 @@
+package hlog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestErrorLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	l := ErrorLogger(zerolog.New(out).Level(zerolog.DebugLevel))
+	l.Printf("http: panic serving %v: %v\n%s", "1.2.3.4:5678", "boom", "goroutine 1 [running]:\nmain.main()")
+	l.Printf("http: TLS handshake error from %s: %v", "1.2.3.4:5678", "EOF")
+	l.Printf("http: Accept error: %v; retrying in %v", "too many open files", "5ms")
+	l.Printf("http: superfluous response.WriteHeader call from %s (%s:%d)", "main.handler", "main.go", 12)
+	l.Printf("http: proxy error: %v", "context canceled")
+	l.Printf("http: proxy error: %v", "dial tcp: connection refused")
+	l.Printf("something else")
+	want := `{"level":"error","remote_addr":"1.2.3.4:5678","panic":"boom","stack":"goroutine 1 [running]:\nmain.main()","message":"http: panic serving request"}` + "\n" +
+		`{"level":"debug","remote_addr":"1.2.3.4:5678","error":"EOF","message":"http: TLS handshake error"}` + "\n" +
+		`{"level":"warn","error":"too many open files","retry_in":"5ms","message":"http: accept error"}` + "\n" +
+		`{"level":"warn","caller":"main.go:12","message":"http: superfluous response.WriteHeader call"}` + "\n" +
+		`{"level":"info","error":"context canceled","message":"http: proxy error"}` + "\n" +
+		`{"level":"error","error":"dial tcp: connection refused","message":"http: proxy error"}` + "\n" +
+		`{"level":"error","message":"something else"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -152,3 +152,72 @@ func RequestIDHandler(fieldKey, headerName string) func(next http.Handler) http.
 		})
 	}
 }
+
+// SampleHandler samples the logger of one request out of n. The decision is
+// derived from a hash of the request's unique id, as returned by
+// IDFromRequest, so all the log lines of a request are either kept or dropped
+// together, and the same request id is consistently sampled across services
+// using the same n.
+//
+// The logger of a kept request keeps its sampler, if any, so its events are
+// still sampled by it. If the request has no id yet, one is generated and
+// will be reused by RequestIDHandler.
+func SampleHandler(n uint32) func(next http.Handler) http.Handler {
+	s := zerolog.KeySampler{N: n}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, ok := IDFromRequest(r)
+			if !ok {
+				id = xid.New()
+				ctx := context.WithValue(r.Context(), idKey{}, id)
+				r = r.WithContext(ctx)
+			}
+			if !s.Keep(id.String()) {
+				// A RandomSampler of 0 drops all the events.
+				log := zerolog.Ctx(r.Context()).Sample(zerolog.RandomSampler(0))
+				r = r.WithContext(log.WithContext(r.Context()))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LevelOverrideHandler sets the minimum level of the request's loggers to the
+// level returned by f, if any. The override is stored in the request's
+// context using zerolog.WithLevelOverride, so it is honored by FromRequest
+// whatever the logger level and the global level.
+//
+// This can be used to make a single request or tenant verbose:
+//
+//     hlog.LevelOverrideHandler(func(r *http.Request) (zerolog.Level, bool) {
+//         if r.Header.Get("X-Debug") == token {
+//             return zerolog.DebugLevel, true
+//         }
+//         return 0, false
+//     })
+func LevelOverrideHandler(f func(r *http.Request) (zerolog.Level, bool)) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if l, ok := f(r); ok {
+				r = r.WithContext(zerolog.WithLevelOverride(r.Context(), l))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// FieldsHandler returns a handler adding the fields added by f to the request
+// context with zerolog.WithFields. They are added to the events having the
+// request context as context by a logger with the zerolog.CtxFieldsHook hook:
+//
+//     log.Info().Ctx(r.Context()).Msg("handled")
+func FieldsHandler(f func(r *http.Request, c zerolog.Context) zerolog.Context) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := zerolog.WithFields(r.Context(), func(c zerolog.Context) zerolog.Context {
+				return f(r, c)
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
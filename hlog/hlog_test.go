@@ -4,6 +4,7 @@ package hlog
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -13,6 +14,7 @@ import (
 
 	"net/http/httptest"
 
+	"github.com/rs/xid"
 	"github.com/rs/zerolog"
 )
 
@@ -171,3 +173,112 @@ func TestRequestIDHandler(t *testing.T) {
 	h = NewHandler(zerolog.New(out))(h)
 	h.ServeHTTP(httptest.NewRecorder(), r)
 }
+
+func TestSampleHandler(t *testing.T) {
+	s := zerolog.KeySampler{N: 2}
+	kept := 0
+	for i := 0; i < 20; i++ {
+		out := &bytes.Buffer{}
+		var id string
+		h := RequestIDHandler("id", "")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqID, _ := IDFromRequest(r)
+			id = reqID.String()
+			l := FromRequest(r)
+			l.Info().Msg("1")
+			l.Error().Msg("2")
+		}))
+		h = SampleHandler(2)(h)
+		// The sampler of the logger still applies to kept requests.
+		log := zerolog.New(out).Sample(zerolog.LevelSampler{InfoSampler: zerolog.RandomSampler(0)})
+		h = NewHandler(log)(h)
+		h.ServeHTTP(httptest.NewRecorder(), &http.Request{})
+		lines := bytes.Count(out.Bytes(), []byte("\n"))
+		want := 0
+		if s.Keep(id) {
+			want = 1
+			kept++
+		}
+		if lines != want {
+			t.Errorf("request %s: got %d lines, want %d", id, lines, want)
+		}
+	}
+	if kept == 0 || kept == 20 {
+		t.Errorf("requests not sampled: %d kept out of 20", kept)
+	}
+}
+
+func TestLevelOverrideHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := FromRequest(r)
+		l.Debug().Str("path", r.URL.Path).Msg("")
+	})
+	lh := LevelOverrideHandler(func(r *http.Request) (zerolog.Level, bool) {
+		return zerolog.DebugLevel, r.Header.Get("X-Debug") == "1"
+	})(h)
+	lh = NewHandler(zerolog.New(out).Level(zerolog.InfoLevel))(lh)
+	lh.ServeHTTP(nil, &http.Request{URL: &url.URL{Path: "/a"}, Header: http.Header{}})
+	lh.ServeHTTP(nil, &http.Request{URL: &url.URL{Path: "/b"}, Header: http.Header{"X-Debug": []string{"1"}}})
+	if want, got := `{"level":"debug","path":"/b"}`+"\n", out.String(); want != got {
+		t.Errorf("Invalid log output, got: %s, want: %s", got, want)
+	}
+}
+
+func TestFieldsHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := FromRequest(r)
+		l.Info().Ctx(r.Context()).Msg("")
+	})
+	fh := FieldsHandler(func(r *http.Request, c zerolog.Context) zerolog.Context {
+		return c.Str("path", r.URL.Path)
+	})(h)
+	fh = NewHandler(zerolog.New(out).Hook(zerolog.CtxFieldsHook{}))(fh)
+	fh.ServeHTTP(nil, &http.Request{URL: &url.URL{Path: "/a"}})
+	if want, got := `{"level":"info","path":"/a"}`+"\n", out.String(); want != got {
+		t.Errorf("Invalid log output, got: %s, want: %s", got, want)
+	}
+}
+
+func TestAccessHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("not found"))
+	})
+	ah := NewHandler(zerolog.New(out))(AccessHandler()(h))
+	ah.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/a?b=c", nil))
+	var got map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid log output %q: %v", out, err)
+	}
+	if _, ok := got["duration"].(float64); !ok {
+		t.Errorf("missing duration: %v", got)
+	}
+	delete(got, "duration")
+	want := map[string]interface{}{"level": "warn", "method": "GET", "url": "/a?b=c", "status": 404.0, "size": 9.0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestRequestIDLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	l, id := RequestIDLogger(zerolog.New(out), "")
+	if _, err := xid.FromString(id); err != nil {
+		t.Errorf("invalid generated id %q: %v", id, err)
+	}
+	l, id = RequestIDLogger(zerolog.New(out), "abc")
+	l.Log().Msg("")
+	if want, got := `{"request_id":"abc"}`+"\n", out.String(); id != "abc" || want != got {
+		t.Errorf("Invalid log output, got: %s (%s), want: %s", got, id, want)
+	}
+}
+
+func TestStatusLevel(t *testing.T) {
+	for status, want := range map[int]zerolog.Level{200: zerolog.InfoLevel, 302: zerolog.InfoLevel, 404: zerolog.WarnLevel, 503: zerolog.ErrorLevel} {
+		if got := StatusLevel(status); got != want {
+			t.Errorf("StatusLevel(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
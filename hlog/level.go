@@ -0,0 +1,112 @@
+package hlog
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog"
+)
+
+type levelPayload struct {
+	Level      string            `json:"level,omitempty"`
+	Components map[string]string `json:"components,omitempty"`
+}
+
+type errorPayload struct {
+	Error string `json:"error"`
+}
+
+// LevelHandler returns a handler reporting and changing the global level or,
+// if the component query parameter is set, the level of a component as set
+// by zerolog.SetComponentLevel.
+//
+// Supported methods are:
+//
+//     GET    returns the current level: {"level":"info"}. Without component,
+//            component levels are returned too: {"level":"info","components":{"storage":"debug"}}.
+//     PUT    sets the level from a JSON body: {"level":"debug"}.
+//     DELETE removes the level override of a component.
+//
+// GET and DELETE return a 404 status for a component without level
+// override. They do not register the component, so arbitrary names do not
+// grow the registry of zerolog.SetComponentLevel.
+//
+// This handler lets operators change the verbosity of a running service and
+// should thus not be exposed publicly.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		component := r.URL.Query().Get("component")
+		switch r.Method {
+		case "GET":
+			if component != "" && !hasComponentLevel(component) {
+				writeLevelError(w, http.StatusNotFound, errors.New("unknown component"))
+				return
+			}
+		case "PUT":
+			var req levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeLevelError(w, http.StatusBadRequest, err)
+				return
+			}
+			if req.Level == "" {
+				writeLevelError(w, http.StatusBadRequest, errors.New("missing level"))
+				return
+			}
+			lvl, err := zerolog.ParseLevel(req.Level)
+			if err != nil {
+				writeLevelError(w, http.StatusBadRequest, err)
+				return
+			}
+			if component != "" {
+				zerolog.SetComponentLevel(component, lvl)
+			} else {
+				zerolog.SetGlobalLevel(lvl)
+			}
+		case "DELETE":
+			if component == "" {
+				writeLevelError(w, http.StatusBadRequest, errors.New("missing component"))
+				return
+			}
+			if !hasComponentLevel(component) {
+				writeLevelError(w, http.StatusNotFound, errors.New("unknown component"))
+				return
+			}
+			zerolog.UnsetComponentLevel(component)
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			writeLevelError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		var res levelPayload
+		if component != "" {
+			if lvl, ok := zerolog.ComponentLevel(component); ok {
+				res.Level = lvl.String()
+			}
+		} else {
+			res.Level = zerolog.GlobalLevel().String()
+			for name, lvl := range zerolog.ComponentLevels() {
+				if res.Components == nil {
+					res.Components = map[string]string{}
+				}
+				res.Components[name] = lvl.String()
+			}
+		}
+		writeLevelJSON(w, http.StatusOK, res)
+	})
+}
+
+func hasComponentLevel(name string) bool {
+	_, ok := zerolog.ComponentLevel(name)
+	return ok
+}
+
+func writeLevelJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeLevelError(w http.ResponseWriter, status int, err error) {
+	writeLevelJSON(w, status, errorPayload{err.Error()})
+}
@@ -0,0 +1,57 @@
+// +build go1.7
+
+package hlog
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLevelHandler(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	defer zerolog.UnsetComponentLevel("storage")
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	h := LevelHandler()
+
+	tests := []struct {
+		method string
+		url    string
+		body   string
+		status int
+		want   string
+	}{
+		{"GET", "/", "", 200, `{"level":"info"}`},
+		{"PUT", "/", `{"level":"warn"}`, 200, `{"level":"warn"}`},
+		{"PUT", "/", `{"level":"foo"}`, 400, `{"error":"unknown level: \"foo\""}`},
+		{"PUT", "/", `{`, 400, `{"error":"unexpected EOF"}`},
+		{"PUT", "/", `{}`, 400, `{"error":"missing level"}`},
+		{"GET", "/?component=storage", "", 404, `{"error":"unknown component"}`},
+		{"PUT", "/?component=storage", `{"level":"debug"}`, 200, `{"level":"debug"}`},
+		{"GET", "/", "", 200, `{"level":"warn","components":{"storage":"debug"}}`},
+		{"DELETE", "/?component=storage", "", 200, `{}`},
+		{"DELETE", "/?component=storage", "", 404, `{"error":"unknown component"}`},
+		{"GET", "/?component=unknown", "", 404, `{"error":"unknown component"}`},
+		{"DELETE", "/", "", 400, `{"error":"missing component"}`},
+		{"POST", "/", "", 405, `{"error":"method not allowed"}`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+		h.ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s %s: invalid status: got %d, want %d", tt.method, tt.url, w.Code, tt.status)
+		}
+		if got := strings.TrimSpace(w.Body.String()); got != tt.want {
+			t.Errorf("%s %s: invalid body: got %s, want %s", tt.method, tt.url, got, tt.want)
+		}
+	}
+	if got, want := zerolog.GlobalLevel(), zerolog.WarnLevel; got != want {
+		t.Errorf("invalid global level: got %v, want %v", got, want)
+	}
+	if _, found := zerolog.ComponentLevels()["unknown"]; found {
+		t.Error("unknown component registered by a lookup")
+	}
+}
@@ -0,0 +1,477 @@
+package zerolog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Hook defines an interface to a log hook.
+type Hook interface {
+	// Run runs the hook with the event. It is called once the message of
+	// an event to be written is known, before the message field is added,
+	// so fields added to e by the hook precede the message.
+	Run(e *Event, level Level, msg string)
+}
+
+// MinLeveler is implemented by hooks only run for the events at or above a
+// minimum level, such as hooks reporting errors, so they don't have to check
+// the level themselves. Such hooks are not run for events without level.
+type MinLeveler interface {
+	MinLevel() Level
+}
+
+// HookError is reported to the error handler, see ErrorHandler and
+// Logger.ErrorHandler, when a hook panics or times out. The event is written
+// anyway.
+type HookError struct {
+	Hook Hook
+	// Panic is the value the hook panicked with, if any.
+	Panic interface{}
+	// Timeout is the timeout set with WithTimeout the hook exceeded, if any.
+	Timeout time.Duration
+}
+
+func (e *HookError) Error() string {
+	if e.Timeout > 0 {
+		return fmt.Sprintf("zerolog: hook %T timed out after %v", e.Hook, e.Timeout)
+	}
+	return fmt.Sprintf("zerolog: hook %T panicked: %v", e.Hook, e.Panic)
+}
+
+func isHookError(err error) bool {
+	_, ok := err.(*HookError)
+	return ok
+}
+
+// boundHook is a hook registered on a logger, with its minimum level
+// resolved once when registered instead of for each event.
+type boundHook struct {
+	Hook
+	min    Level
+	hasMin bool
+}
+
+func bindHook(h Hook) boundHook {
+	bh := boundHook{Hook: h}
+	if ml, ok := h.(MinLeveler); ok {
+		bh.min, bh.hasMin = ml.MinLevel(), true
+	}
+	return bh
+}
+
+// runHooks runs the hooks of e, skipping the MinLevelers not accepting the
+// level of e. A panicking hook is recovered and reported to the error
+// handler, and the next hooks are run.
+func runHooks(e *Event, msg string) {
+	for i := 0; i < len(e.hooks); i++ {
+		i = runHooksFrom(e, i, msg)
+	}
+}
+
+// runHooksFrom runs the hooks of e from i, with a single deferred recover
+// for all of them, and returns the position of the hook which panicked or
+// the number of hooks.
+func runHooksFrom(e *Event, i int, msg string) (last int) {
+	defer func() {
+		if r := recover(); r != nil {
+			handleError(e.onError, &HookError{Hook: e.hooks[last].Hook, Panic: r})
+		}
+	}()
+	for last = i; last < len(e.hooks); last++ {
+		// The level is read for each hook as hooks can demote the event.
+		h, level := &e.hooks[last], e.level
+		if h.hasMin && (level < h.min || level == NoLevel) {
+			continue
+		}
+		h.Hook.Run(e, level, msg)
+	}
+	return last
+}
+
+// runHook runs h with e unless h is a MinLeveler not accepting level. A
+// panicking hook is recovered and reported to the error handler so it can't
+// take down the goroutine logging the event.
+func runHook(h Hook, e *Event, level Level, msg string) {
+	if skipHook(h, level) {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			handleError(e.onError, &HookError{Hook: h, Panic: r})
+		}
+	}()
+	h.Run(e, level, msg)
+}
+
+// skipHook returns true if h is a MinLeveler not accepting level.
+func skipHook(h Hook, level Level) bool {
+	ml, ok := h.(MinLeveler)
+	return ok && (level < ml.MinLevel() || level == NoLevel)
+}
+
+type minLevelHook struct {
+	Hook
+	min Level
+}
+
+func (h minLevelHook) MinLevel() Level {
+	return h.min
+}
+
+// WithMinLevel returns a hook running h only for the events at or above min,
+// custom levels and AuditLevel included:
+//
+//     log = log.Hook(zerolog.WithMinLevel(zerolog.WarnLevel, alertHook))
+func WithMinLevel(min Level, h Hook) Hook {
+	return minLevelHook{Hook: h, min: min}
+}
+
+type timeoutHook struct {
+	h Hook
+	d time.Duration
+}
+
+// WithTimeout returns a hook running h on a copy of the event and waiting at
+// most d for it to complete. The fields h adds are kept if it completes in
+// time. Otherwise the event is written without them, a *HookError is reported
+// to the error handler and h keeps running in the background on the copy.
+func WithTimeout(d time.Duration, h Hook) Hook {
+	return timeoutHook{h: h, d: d}
+}
+
+// Run implements the Hook interface.
+func (t timeoutHook) Run(e *Event, level Level, msg string) {
+	if skipHook(t.h, level) {
+		return
+	}
+	c := &Event{
+		buf:       append(make([]byte, 0, len(e.buf)+100), e.buf...),
+		enabled:   e.enabled,
+		level:     e.level,
+		filters:   e.filters,
+		demoters:  e.demoters,
+		levelPos:  e.levelPos,
+		levelEnd:  e.levelEnd,
+		ctx:       e.ctx,
+		component: e.component,
+		onError:   e.onError,
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runHook(t.h, c, level, msg)
+	}()
+	timer := time.NewTimer(t.d)
+	defer timer.Stop()
+	select {
+	case <-done:
+		e.buf, e.enabled, e.level = c.buf, c.enabled, c.level
+		e.levelPos, e.levelEnd = c.levelPos, c.levelEnd
+	case <-timer.C:
+		handleError(e.onError, &HookError{Hook: t.h, Timeout: t.d})
+	}
+}
+
+// HookFunc is an adaptor to allow the use of an ordinary function as a Hook.
+type HookFunc func(e *Event, level Level, msg string)
+
+// Run implements the Hook interface.
+func (h HookFunc) Run(e *Event, level Level, msg string) {
+	h(e, level, msg)
+}
+
+// NamedHook returns a logger with the h Hook registered as name. If the
+// logger already has a hook with this name, it is replaced in place, keeping
+// its position in the hook chain. Otherwise h is run after the existing hooks.
+//
+// Named hooks let frameworks manage the hooks of derived loggers:
+//
+//     log = log.NamedHook("trace", otelhook.Hook{})
+//     quiet := log.RemoveHook("trace")
+func (l Logger) NamedHook(name string, h Hook) Logger {
+	if i := l.hookIndex(name); i != -1 {
+		hooks := make([]boundHook, len(l.hooks))
+		copy(hooks, l.hooks)
+		hooks[i] = bindHook(h)
+		l.hooks = hooks
+		return l
+	}
+	return l.insertHook(len(l.hooks), name, h)
+}
+
+// NamedHookBefore is like NamedHook but runs h before the hook named before.
+// If the logger has no such hook, h is run after the existing hooks. An
+// existing hook registered as name is removed first.
+func (l Logger) NamedHookBefore(before, name string, h Hook) Logger {
+	l = l.RemoveHook(name)
+	i := l.hookIndex(before)
+	if i == -1 {
+		i = len(l.hooks)
+	}
+	return l.insertHook(i, name, h)
+}
+
+// RemoveHook returns a logger without the hook registered as name.
+func (l Logger) RemoveHook(name string) Logger {
+	i := l.hookIndex(name)
+	if i == -1 {
+		return l
+	}
+	hooks := make([]boundHook, 0, len(l.hooks)-1)
+	hooks = append(append(hooks, l.hooks[:i]...), l.hooks[i+1:]...)
+	names := make([]string, 0, len(l.hookNames)-1)
+	names = append(append(names, l.hookNames[:i]...), l.hookNames[i+1:]...)
+	l.hooks, l.hookNames = hooks, names
+	return l
+}
+
+// HookNames returns the names of the hooks of the logger in the order they
+// are run. Hooks added with Hook have an empty name.
+func (l Logger) HookNames() []string {
+	names := make([]string, len(l.hookNames))
+	copy(names, l.hookNames)
+	return names
+}
+
+// hookIndex returns the position of the hook named name or -1.
+func (l Logger) hookIndex(name string) int {
+	if name == "" {
+		return -1
+	}
+	for i, n := range l.hookNames {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertHook returns a logger with h named name inserted at position i.
+func (l Logger) insertHook(i int, name string, h Hook) Logger {
+	// Copy so siblings don't share the same backing array.
+	hooks := make([]boundHook, 0, len(l.hooks)+1)
+	hooks = append(append(append(hooks, l.hooks[:i]...), bindHook(h)), l.hooks[i:]...)
+	names := make([]string, 0, len(l.hookNames)+1)
+	names = append(append(append(names, l.hookNames[:i]...), name), l.hookNames[i:]...)
+	l.hooks, l.hookNames = hooks, names
+	return l
+}
+
+// LevelHook applies a different hook for each level. A nil hook is not run.
+//
+//     log = log.Hook(zerolog.LevelHook{ErrorHook: alertHook, FatalHook: alertHook})
+type LevelHook struct {
+	NoLevelHook, TraceHook, DebugHook, InfoHook, WarnHook, ErrorHook, FatalHook, PanicHook, AuditHook Hook
+}
+
+// Run implements the Hook interface.
+func (h LevelHook) Run(e *Event, level Level, msg string) {
+	var hook Hook
+	switch level {
+	case TraceLevel:
+		hook = h.TraceHook
+	case DebugLevel:
+		hook = h.DebugHook
+	case InfoLevel:
+		hook = h.InfoHook
+	case WarnLevel:
+		hook = h.WarnHook
+	case ErrorLevel:
+		hook = h.ErrorHook
+	case FatalLevel:
+		hook = h.FatalHook
+	case PanicLevel:
+		hook = h.PanicHook
+	case AuditLevel:
+		hook = h.AuditHook
+	case NoLevel:
+		hook = h.NoLevelHook
+	}
+	if hook != nil {
+		runHook(hook, e, level, msg)
+	}
+}
+
+// NewLevelHook returns a LevelHook running h for levels at or above min,
+// custom levels excluded. Other fields can be set on the returned value.
+func NewLevelHook(min Level, h Hook) LevelHook {
+	var lh LevelHook
+	for _, l := range []struct {
+		level Level
+		hook  *Hook
+	}{
+		{TraceLevel, &lh.TraceHook},
+		{DebugLevel, &lh.DebugHook},
+		{InfoLevel, &lh.InfoHook},
+		{WarnLevel, &lh.WarnHook},
+		{ErrorLevel, &lh.ErrorHook},
+		{FatalLevel, &lh.FatalHook},
+		{PanicLevel, &lh.PanicHook},
+	} {
+		if l.level >= min {
+			*l.hook = h
+		}
+	}
+	return lh
+}
+
+// AsyncHook runs a hook on a worker goroutine so a slow hook, such as one
+// calling a remote service, does not add latency to logging calls. Events
+// are queued in a bounded queue and dropped when it is full.
+//
+// The hook is passed a detached copy of the event which only carries its
+// context.Context and component: fields added by the hook are discarded.
+// Async hooks are thus meant to observe events, not to enrich them.
+type AsyncHook struct {
+	h       Hook
+	queue   chan asyncHookEvent
+	done    chan struct{}
+	once    sync.Once
+	dropped uint64
+}
+
+type asyncHookEvent struct {
+	e     *Event
+	level Level
+	msg   string
+}
+
+// NewAsyncHook returns an AsyncHook running h with a queue of size events.
+// Close must be called to stop the worker goroutine.
+func NewAsyncHook(h Hook, size int) *AsyncHook {
+	a := &AsyncHook{
+		h:     h,
+		queue: make(chan asyncHookEvent, size),
+		done:  make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Run implements the Hook interface. It never blocks.
+func (a *AsyncHook) Run(e *Event, level Level, msg string) {
+	if skipHook(a.h, level) {
+		return
+	}
+	ev := &Event{level: level, ctx: e.ctx, component: e.component, onError: e.onError}
+	select {
+	case a.queue <- asyncHookEvent{ev, level, msg}:
+	default:
+		atomic.AddUint64(&a.dropped, 1)
+	}
+}
+
+// Dropped returns the number of events dropped because the queue was full.
+func (a *AsyncHook) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+// Close runs the hook with the queued events and stops the worker goroutine.
+// The hook must not be run after Close.
+func (a *AsyncHook) Close() {
+	a.once.Do(func() {
+		close(a.queue)
+	})
+	<-a.done
+}
+
+// Shutdown is like Close but returns ctx.Err() if ctx is done before the
+// queued events are processed. It can be registered with RegisterShutdown.
+func (a *AsyncHook) Shutdown(ctx context.Context) error {
+	a.once.Do(func() {
+		close(a.queue)
+	})
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *AsyncHook) run() {
+	defer close(a.done)
+	for ev := range a.queue {
+		runHook(a.h, ev.e, ev.level, ev.msg)
+	}
+}
+
+// BufferHook defines an interface to a hook run with the serialized event.
+// Unlike Hook, it can read the serialized fields, for instance to sign them,
+// and transform the serialized event, for instance to encrypt it or to wrap
+// it in an envelope, without decoding the event.
+type BufferHook interface {
+	// RunBuffer runs the hook with the buffer of the event, once the event
+	// hooks ran and the message field is added.
+	RunBuffer(b *EventBuffer)
+}
+
+// BufferHookFunc is an adaptor to allow the use of an ordinary function as
+// a BufferHook.
+type BufferHookFunc func(b *EventBuffer)
+
+// RunBuffer implements the BufferHook interface.
+func (h BufferHookFunc) RunBuffer(b *EventBuffer) {
+	h(b)
+}
+
+// BufferHook returns a logger with the h BufferHook. Buffer hooks are run in
+// the order they were added, after the event hooks.
+func (l Logger) BufferHook(h BufferHook) Logger {
+	// Copy so siblings don't share the same backing array.
+	hooks := make([]BufferHook, len(l.bufHooks), len(l.bufHooks)+1)
+	copy(hooks, l.bufHooks)
+	l.bufHooks = append(hooks, h)
+	return l
+}
+
+// EventBuffer gives buffer hooks access to the serialized event.
+type EventBuffer Event
+
+// beginMarkerLen is the size of the marker starting serialized events.
+var beginMarkerLen = len(appendBeginMarker(nil))
+
+// Level returns the level of the event.
+func (b *EventBuffer) Level() Level {
+	return b.level
+}
+
+// GetCtx returns the context.Context of the event, like Event.GetCtx.
+func (b *EventBuffer) GetCtx() context.Context {
+	return (*Event)(b).GetCtx()
+}
+
+// Fields returns the serialized fields of the event, without the markers
+// starting and ending the event. It must not be modified nor retained.
+func (b *EventBuffer) Fields() []byte {
+	return b.buf[beginMarkerLen:]
+}
+
+// Str adds the field key with val as a string to the event.
+func (b *EventBuffer) Str(key, val string) *EventBuffer {
+	b.buf = appendString(b.buf, key, val)
+	return b
+}
+
+// Replace replaces the value of the top level field key with val as a
+// string. It returns false if the event has no such field.
+func (b *EventBuffer) Replace(key, val string) bool {
+	buf := rewriteFields(b.buf, func(k, v []byte, isString bool) (string, bool) {
+		return val, string(k) == key
+	}, false)
+	if buf == nil {
+		return false
+	}
+	b.buf = buf
+	return true
+}
+
+// Transform registers f to transform the serialized event, terminated and
+// ready to be written, into the bytes written in its place. Transformations
+// are applied in the order they were registered. f must not retain p.
+func (b *EventBuffer) Transform(f func(p []byte) []byte) {
+	b.transforms = append(b.transforms, f)
+}
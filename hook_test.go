@@ -0,0 +1,360 @@
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	levelNameHook = HookFunc(func(e *Event, level Level, msg string) {
+		levelName := level.String()
+		if level == NoLevel {
+			levelName = "nolevel"
+		}
+		e.Str("level_name", levelName)
+	})
+	simpleHook = HookFunc(func(e *Event, level Level, msg string) {
+		e.Bool("has_level", level != NoLevel)
+		e.Str("test", "logged")
+	})
+	copyHook = HookFunc(func(e *Event, level Level, msg string) {
+		hasLevel := level != NoLevel
+		e.Bool("copy_has_level", hasLevel)
+		if hasLevel {
+			e.Str("copy_level", level.String())
+		}
+		e.Str("copy_msg", msg)
+	})
+	nopHook = HookFunc(func(e *Event, level Level, message string) {
+	})
+)
+
+func TestHook(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		test func(log Logger)
+	}{
+		{"Message", `{"level_name":"nolevel","message":"test message"}` + "\n", func(log Logger) {
+			log = log.Hook(levelNameHook)
+			log.Log().Msg("test message")
+		}},
+		{"NoLevel", `{"level_name":"nolevel"}` + "\n", func(log Logger) {
+			log = log.Hook(levelNameHook)
+			log.Log().Msg("")
+		}},
+		{"Print", `{"level":"debug","level_name":"debug"}` + "\n", func(log Logger) {
+			log = log.Hook(levelNameHook)
+			log.Debug().Msg("")
+		}},
+		{"Msgf", `{"level":"error","level_name":"error","message":"test message"}` + "\n", func(log Logger) {
+			log = log.Hook(levelNameHook)
+			log.Error().Msgf("test %s", "message")
+		}},
+		{"Copy/1", `{"copy_has_level":false,"copy_msg":""}` + "\n", func(log Logger) {
+			log = log.Hook(copyHook)
+			log.Log().Msg("")
+		}},
+		{"Copy/2", `{"level":"info","copy_has_level":true,"copy_level":"info","copy_msg":"a message","message":"a message"}` + "\n", func(log Logger) {
+			log = log.Hook(copyHook)
+			log.Info().Msg("a message")
+		}},
+		{"Multi", `{"level":"error","level_name":"error","has_level":true,"test":"logged"}` + "\n", func(log Logger) {
+			log = log.Hook(levelNameHook).Hook(simpleHook)
+			log.Error().Msg("")
+		}},
+		{"Multi/Message", `{"level":"error","level_name":"error","has_level":true,"test":"logged","message":"a message"}` + "\n", func(log Logger) {
+			log = log.Hook(levelNameHook).Hook(simpleHook)
+			log.Error().Msg("a message")
+		}},
+		{"With/single/post", `{"level":"error","foo":"bar","level_name":"error"}` + "\n", func(log Logger) {
+			log = log.With().Str("foo", "bar").Logger().Hook(levelNameHook)
+			log.Error().Msg("")
+		}},
+		{"Siblings", `{"level":"error","has_level":true,"test":"logged"}` + "\n", func(log Logger) {
+			parent := log.Hook(nopHook)
+			_ = parent.Hook(levelNameHook)
+			parent.Hook(simpleHook).Error().Msg("")
+		}},
+		{"Discarded", "", func(log Logger) {
+			log = log.Hook(simpleHook).Level(ErrorLevel)
+			log.Info().Msg("")
+		}},
+		{"None", `{"level":"error"}` + "\n", func(log Logger) {
+			log.Error().Msg("")
+		}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if strings.Contains(tt.want, `"level":"debug"`) {
+				skipIfNoDebug(t)
+			}
+			out := &bytes.Buffer{}
+			log := New(out)
+			tt.test(log)
+			if got, want := decodeIfBinaryToString(out.Bytes()), tt.want; got != want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLevelHook(t *testing.T) {
+	out := &bytes.Buffer{}
+	lh := NewLevelHook(ErrorLevel, simpleHook)
+	lh.NoLevelHook = levelNameHook
+	log := New(out).Hook(lh)
+	log.Info().Msg("")
+	log.Error().Msg("")
+	func() {
+		defer func() { recover() }()
+		log.Panic().Msg("")
+	}()
+	log.Log().Msg("")
+	log.WithLevel(PanicLevel + 30).Msg("")
+	want := `{"level":"info"}` + "\n" +
+		`{"level":"error","has_level":true,"test":"logged"}` + "\n" +
+		`{"level":"panic","has_level":true,"test":"logged"}` + "\n" +
+		`{"level_name":"nolevel"}` + "\n" +
+		`{"level":"35"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+type warnHook struct{}
+
+func (h warnHook) Run(e *Event, level Level, msg string) {
+	e.Bool("alert", true)
+}
+
+func (h warnHook) MinLevel() Level {
+	return WarnLevel
+}
+
+func TestMinLevelHook(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Hook(warnHook{}).Hook(WithMinLevel(ErrorLevel, simpleHook))
+	log.Info().Msg("")
+	log.Warn().Msg("")
+	log.Error().Msg("")
+	log.WithLevel(PanicLevel + 31).Msg("")
+	log.Log().Msg("")
+	log.Audit().Msg("")
+	want := `{"level":"info"}` + "\n" +
+		`{"level":"warn","alert":true}` + "\n" +
+		`{"level":"error","alert":true,"has_level":true,"test":"logged"}` + "\n" +
+		`{"level":"36","alert":true,"has_level":true,"test":"logged"}` + "\n" +
+		`{}` + "\n" +
+		`{"level":"audit","alert":true,"has_level":true,"test":"logged"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+type ctxKeyTest struct{}
+
+func TestHookCtx(t *testing.T) {
+	out := &bytes.Buffer{}
+	ctxHook := HookFunc(func(e *Event, level Level, msg string) {
+		if id, ok := e.GetCtx().Value(ctxKeyTest{}).(string); ok {
+			e.Str("trace_id", id)
+		}
+	})
+	log := New(out).Hook(ctxHook)
+	ctx := context.WithValue(context.Background(), ctxKeyTest{}, "abc")
+	log.Log().Msg("")
+	log.Log().Ctx(ctx).Msg("")
+	log.With().Ctx(ctx).Logger().Log().Msg("")
+	log.With().Ctx(ctx).Logger().Log().Ctx(context.Background()).Msg("")
+	want := `{}` + "\n" + `{"trace_id":"abc"}` + "\n" + `{"trace_id":"abc"}` + "\n" + `{}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestHookComponent(t *testing.T) {
+	out := &bytes.Buffer{}
+	componentHook := HookFunc(func(e *Event, level Level, msg string) {
+		e.Str("hook_component", e.GetComponent())
+	})
+	log := New(out).Hook(componentHook)
+	log.Log().Msg("")
+	log.With().Component("db").Logger().Log().Msg("")
+	want := `{"hook_component":""}` + "\n" + `{"component":"db","hook_component":"db"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestAsyncHook(t *testing.T) {
+	var got []string
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	slowHook := HookFunc(func(e *Event, level Level, msg string) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		got = append(got, level.String()+":"+msg+":"+e.GetComponent()+":"+e.GetCtx().Value(ctxKeyTest{}).(string))
+		e.Str("discarded", "1")
+	})
+	h := NewAsyncHook(slowHook, 1)
+	out := &bytes.Buffer{}
+	ctx := context.WithValue(context.Background(), ctxKeyTest{}, "abc")
+	log := New(out).With().Component("db").Ctx(ctx).Logger().Hook(h)
+	// The worker blocks on the first event, the second fills the queue and
+	// the third is dropped without blocking the caller.
+	log.Info().Msg("a")
+	<-started
+	log.Warn().Msg("b")
+	for h.Dropped() == 0 {
+		log.Error().Msg("c")
+	}
+	close(release)
+	h.Close()
+	if want := []string{"info:a:db:abc", "warn:b:db:abc"}; !reflect.DeepEqual(got[:2], want) {
+		t.Errorf("invalid hook runs: got %q, want %q", got, want)
+	}
+	if strings.Contains(decodeIfBinaryToString(out.Bytes()), "discarded") {
+		t.Errorf("fields added by async hook must be discarded: %s", decodeIfBinaryToString(out.Bytes()))
+	}
+}
+
+func TestNamedHook(t *testing.T) {
+	strHook := func(key, val string) Hook {
+		return HookFunc(func(e *Event, level Level, msg string) {
+			e.Str(key, val)
+		})
+	}
+	parent := New(nil).
+		NamedHook("a", strHook("a", "1")).
+		Hook(strHook("anon", "1")).
+		NamedHook("b", strHook("b", "1"))
+	tests := []struct {
+		name  string
+		log   Logger
+		want  string
+		names []string
+	}{
+		{"Parent", parent, `{"a":"1","anon":"1","b":"1"}`, []string{"a", "", "b"}},
+		{"Replace", parent.NamedHook("a", strHook("a", "2")), `{"a":"2","anon":"1","b":"1"}`, []string{"a", "", "b"}},
+		{"Remove", parent.RemoveHook("a"), `{"anon":"1","b":"1"}`, []string{"", "b"}},
+		{"RemoveMissing", parent.RemoveHook("c"), `{"a":"1","anon":"1","b":"1"}`, []string{"a", "", "b"}},
+		{"Before", parent.NamedHookBefore("a", "c", strHook("c", "1")), `{"c":"1","a":"1","anon":"1","b":"1"}`, []string{"c", "a", "", "b"}},
+		{"Move", parent.NamedHookBefore("a", "b", strHook("b", "2")), `{"b":"2","a":"1","anon":"1"}`, []string{"b", "a", ""}},
+		{"BeforeMissing", parent.NamedHookBefore("z", "c", strHook("c", "1")), `{"a":"1","anon":"1","b":"1","c":"1"}`, []string{"a", "", "b", "c"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			tt.log.w = levelWriterAdapter{out}
+			tt.log.Log().Msg("")
+			if got, want := decodeIfBinaryToString(out.Bytes()), tt.want+"\n"; got != want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+			}
+			if got := tt.log.HookNames(); !reflect.DeepEqual(got, tt.names) {
+				t.Errorf("invalid hook names: got %q, want %q", got, tt.names)
+			}
+		})
+	}
+}
+
+func BenchmarkHooks(b *testing.B) {
+	logger := New(nil)
+	b.ResetTimer()
+	b.Run("Nop/Single", func(b *testing.B) {
+		log := logger.Hook(nopHook)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				log.Log().Msg("")
+			}
+		})
+	})
+	b.Run("Simple", func(b *testing.B) {
+		log := logger.Hook(simpleHook)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				log.Log().Msg("")
+			}
+		})
+	})
+}
+
+func TestBufferHook(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Hook(levelNameHook).
+		BufferHook(BufferHookFunc(func(b *EventBuffer) {
+			b.Str("fields", strconv.FormatBool(bytes.Contains(b.Fields(), []byte("level_name"))))
+			b.Replace("level_name", b.Level().String()+"!")
+		})).
+		BufferHook(BufferHookFunc(func(b *EventBuffer) {
+			b.Transform(func(p []byte) []byte {
+				return append([]byte("envelope "), decodeIfBinaryToBytes(p)...)
+			})
+		}))
+	log.Info().Dict("d", Dict().Str("level_name", "nested")).Msg("a")
+	log.Info().Msg("b")
+	want := `envelope {"level":"info","d":{"level_name":"nested"},"level_name":"info!","message":"a","fields":"true"}` + "\n" +
+		`envelope {"level":"info","level_name":"info!","message":"b","fields":"true"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestHookPanic(t *testing.T) {
+	out := &bytes.Buffer{}
+	var errs []error
+	log := New(out).ErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}).Hook(HookFunc(func(e *Event, level Level, msg string) {
+		e.Str("before", "panic")
+		panic("boom")
+	})).Hook(simpleHook)
+	log.Info().Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","before":"panic","has_level":true,"test":"logged"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("invalid errors: %v", errs)
+	}
+	if he, ok := errs[0].(*HookError); !ok || he.Panic != "boom" {
+		t.Errorf("invalid error: %#v", errs[0])
+	}
+}
+
+func TestHookTimeout(t *testing.T) {
+	out := &bytes.Buffer{}
+	var errs []error
+	release := make(chan struct{})
+	defer close(release)
+	slow := HookFunc(func(e *Event, level Level, msg string) {
+		if msg == "slow" {
+			<-release
+		}
+		e.Str("hooked", msg)
+	})
+	log := New(out).ErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}).Hook(WithTimeout(20*time.Millisecond, slow))
+	log.Info().Msg("fast")
+	log.Info().Msg("slow")
+	want := `{"level":"info","hooked":"fast","message":"fast"}` + "\n" +
+		`{"level":"info","message":"slow"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("invalid errors: %v", errs)
+	}
+	if he, ok := errs[0].(*HookError); !ok || he.Timeout != 20*time.Millisecond {
+		t.Errorf("invalid error: %#v", errs[0])
+	}
+}
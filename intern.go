@@ -0,0 +1,105 @@
+package zerolog
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// keyTable is an open addressing hash table of the interned keys, indexed
+// by the address of their bytes.
+type keyTable struct {
+	shift uint
+	slots []internedKey
+}
+
+type internedKey struct {
+	// p is the address of the bytes of key, key keeping them alive.
+	p   uintptr
+	key string
+	// enc is the encoding of key, see encodeKey.
+	enc string
+}
+
+var (
+	// internedKeys is a *keyTable, nil until InternKeys is called.
+	internedKeys   unsafe.Pointer
+	internedKeysMu sync.Mutex
+)
+
+// InternKeys pre-encodes keys, with their quotes and the colon, so the
+// fields added with these keys append the encoded key instead of encoding
+// it each time. Use it for the keys of the hottest call sites.
+//
+// Keys are recognized by the address of their bytes, not by their content:
+// interning only applies to the very same strings, such as string constants
+// and literals, which the Go linker stores once per program, and not to
+// keys built at run time. Once keys are interned, the other keys pay a
+// lookup in the table of the interned keys. Interning is only used by the
+// JSON encoding.
+//
+//     const KeyUserID = "user_id"
+//
+//     func init() {
+//         zerolog.InternKeys(KeyUserID, "request_id")
+//     }
+func InternKeys(keys ...string) {
+	internedKeysMu.Lock()
+	defer internedKeysMu.Unlock()
+	var all []internedKey
+	if t := (*keyTable)(atomic.LoadPointer(&internedKeys)); t != nil {
+		for _, k := range t.slots {
+			if k.p != 0 {
+				all = append(all, k)
+			}
+		}
+	}
+	for _, key := range keys {
+		if len(key) == 0 {
+			continue
+		}
+		all = append(all, internedKey{p: stringAddr(key), key: key, enc: encodeKey(key)})
+	}
+	// Keep the table at most a quarter full so the probes are short.
+	t := &keyTable{shift: 64 - 4}
+	for 1<<(64-t.shift) < 4*len(all) {
+		t.shift--
+	}
+	t.slots = make([]internedKey, 1<<(64-t.shift))
+	for _, k := range all {
+		i := t.index(k.p)
+		for t.slots[i].p != 0 && t.slots[i].p != k.p {
+			i = (i + 1) & (len(t.slots) - 1)
+		}
+		t.slots[i] = k
+	}
+	atomic.StorePointer(&internedKeys, unsafe.Pointer(t))
+}
+
+// index returns the first slot to probe for the address p.
+func (t *keyTable) index(p uintptr) int {
+	return int((uint64(p) * 0x9e3779b97f4a7c15) >> t.shift)
+}
+
+// loadKeyTable returns the table of the interned keys, or nil until
+// InternKeys is called.
+func loadKeyTable() *keyTable {
+	return (*keyTable)(atomic.LoadPointer(&internedKeys))
+}
+
+// lookup returns the encoding of key, see encodeKey, if it was interned.
+func (t *keyTable) lookup(key string) (string, bool) {
+	p := stringAddr(key)
+	for i := t.index(p); t.slots[i].p != 0; i = (i + 1) & (len(t.slots) - 1) {
+		if t.slots[i].p == p && len(t.slots[i].key) == len(key) {
+			return t.slots[i].enc, true
+		}
+	}
+	return "", false
+}
+
+// stringAddr returns the address of the bytes of s.
+func stringAddr(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
@@ -0,0 +1,50 @@
+// +build !zerolog_bson
+
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInternKeys(t *testing.T) {
+	const key = "interned_key"
+	InternKeys(key, `quoted"key`, "")
+	InternKeys("other_key")
+	if enc, ok := loadKeyTable().lookup(key); !ok || enc != encodeKey(key) {
+		t.Errorf("lookup(%q) = %q, %v", key, enc, ok)
+	}
+	if _, ok := loadKeyTable().lookup(string([]byte(key))); ok {
+		t.Error("a copy of an interned key is interned")
+	}
+	out := &bytes.Buffer{}
+	New(out).Log().
+		Str(key, "a").
+		Str(`quoted"key`, "b").
+		Str("other_key", "c").
+		Str(string([]byte(key)), "d").
+		Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"interned_key":"a","quoted\"key":"b","other_key":"c","interned_key":"d"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func BenchmarkAppendKey(b *testing.B) {
+	interned := []string{"bench_user_id", "bench_method", "bench_status", "bench_duration", "bench_request_id"}
+	InternKeys(interned...)
+	var plain []string
+	for _, k := range interned {
+		plain = append(plain, string([]byte(k)))
+	}
+	for name, keys := range map[string][]string{"plain": plain, "interned": interned} {
+		b.Run(name, func(b *testing.B) {
+			buf := make([]byte, 0, 500)
+			for i := 0; i < b.N; i++ {
+				buf = buf[:1]
+				for _, k := range keys {
+					buf = appendKey(buf, k)
+				}
+			}
+		})
+	}
+}
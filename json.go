@@ -1,32 +1,29 @@
 package zerolog
 
-import "unicode/utf8"
+import (
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
 
 const hex = "0123456789abcdef"
 
 // appendJSONString encodes the input string to json and appends
 // the encoded string to the input byte slice.
 //
-// The operation loops though each byte in the string looking
-// for characters that need json or utf8 encoding. If the string
-// does not need encoding, then the string is appended in it's
-// entirety to the byte slice.
+// The operation scans the string, a word at a time, looking for
+// characters that need json or utf8 encoding. If the string does not need
+// encoding, then the string is appended in it's entirety to the byte slice.
 // If we encounter a byte that does need encoding, switch up
 // the operation and perform a byte-by-byte read-encode-append.
 func appendJSONString(dst []byte, s string) []byte {
 	// Start with a double quote.
 	dst = append(dst, '"')
-	// Loop through each character in the string.
-	for i := 0; i < len(s); i++ {
-		// Check if the character needs encoding. Control characters, slashes,
-		// and the double quote need json encoding. Bytes above the ascii
-		// boundary needs utf8 encoding.
-		if s[i] < 0x20 || s[i] > 0x7e || s[i] == '\\' || s[i] == '"' {
-			// We encountered a character that needs to be encoded. Switch
-			// to complex version of the algorithm.
-			dst = appendJSONStringComplex(dst, s, i)
-			return append(dst, '"')
-		}
+	if i := safeLen(s, 0); i < len(s) {
+		// We encountered a character that needs to be encoded. Switch
+		// to complex version of the algorithm.
+		dst = appendJSONStringComplex(dst, s, i)
+		return append(dst, '"')
 	}
 	// The string has no need for encoding an therefore is directly
 	// appended to the byte slice.
@@ -35,6 +32,44 @@ func appendJSONString(dst []byte, s string) []byte {
 	return append(dst, '"')
 }
 
+const (
+	lsb = 0x0101010101010101
+	msb = 0x8080808080808080
+)
+
+// safeLen returns the index of the first byte of s, from i, that needs
+// encoding, or len(s) if there is none. Control characters, slashes, and
+// the double quote need json encoding. Bytes above the ascii boundary
+// needs utf8 encoding.
+//
+// The string is first scanned 8 bytes at a time: the high bit of each byte
+// of the word is set by the bit tricks below if the byte may need encoding,
+// so only the words with such bytes are scanned byte-by-byte.
+func safeLen(s string, i int) int {
+	for ; i+8 <= len(s); i += 8 {
+		w := uint64(s[i]) | uint64(s[i+1])<<8 | uint64(s[i+2])<<16 | uint64(s[i+3])<<24 |
+			uint64(s[i+4])<<32 | uint64(s[i+5])<<40 | uint64(s[i+6])<<48 | uint64(s[i+7])<<56
+		// Bytes below 0x20.
+		m := (w - lsb*0x20) & ^w
+		// Bytes above 0x7e, including the ones with their high bit set.
+		m |= w | (w + lsb*(0x80-0x7f))
+		// Double quotes and slashes, which xor to zero.
+		q := w ^ (lsb * '"')
+		m |= (q - lsb) & ^q
+		b := w ^ (lsb * '\\')
+		m |= (b - lsb) & ^b
+		if m&msb != 0 {
+			break
+		}
+	}
+	for ; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c > 0x7e || c == '\\' || c == '"' {
+			return i
+		}
+	}
+	return len(s)
+}
+
 // appendJSONStringComplex is used by appendJSONString to take over an in
 // progress JSON string encoding that encountered a character that needs
 // to be encoded.
@@ -60,7 +95,7 @@ func appendJSONStringComplex(dst []byte, s string, i int) []byte {
 			continue
 		}
 		if b >= 0x20 && b <= 0x7e && b != '\\' && b != '"' {
-			i++
+			i = safeLen(s, i+1)
 			continue
 		}
 		// We encountered a character that needs to be encoded.
@@ -94,3 +129,66 @@ func appendJSONStringComplex(dst []byte, s string, i int) []byte {
 	}
 	return dst
 }
+
+// smallsString holds the two digits representations of the numbers from 0
+// to 99.
+const smallsString = "00010203040506070809" +
+	"10111213141516171819" +
+	"20212223242526272829" +
+	"30313233343536373839" +
+	"40414243444546474849" +
+	"50515253545556575859" +
+	"60616263646566676869" +
+	"70717273747576777879" +
+	"80818283848586878889" +
+	"90919293949596979899"
+
+// appendUintValue appends the decimal representation of v. Numbers below 100
+// are appended directly and larger ones two digits at a time, without the
+// base handling of strconv.AppendUint.
+func appendUintValue(dst []byte, v uint64) []byte {
+	if v < 10 {
+		return append(dst, byte('0'+v))
+	}
+	if v < 100 {
+		return append(dst, smallsString[v*2], smallsString[v*2+1])
+	}
+	var a [20]byte
+	i := len(a)
+	for v >= 100 {
+		is := v % 100 * 2
+		v /= 100
+		i -= 2
+		a[i+1] = smallsString[is+1]
+		a[i] = smallsString[is]
+	}
+	is := v * 2
+	i--
+	a[i] = smallsString[is+1]
+	if v >= 10 {
+		i--
+		a[i] = smallsString[is]
+	}
+	return append(dst, a[i:]...)
+}
+
+// appendIntValue appends the decimal representation of v.
+func appendIntValue(dst []byte, v int64) []byte {
+	if v < 0 {
+		// -v overflows for math.MinInt64 but its conversion to uint64 is
+		// still the absolute value.
+		return appendUintValue(append(dst, '-'), uint64(-v))
+	}
+	return appendUintValue(dst, uint64(v))
+}
+
+// appendFloatValue appends the shortest decimal representation of v as a
+// float32. Integral values exactly representable as float32 are appended as
+// integers, skipping the shortest representation search of
+// strconv.AppendFloat.
+func appendFloatValue(dst []byte, v float64) []byte {
+	if v > -(1<<24) && v < 1<<24 && v == math.Trunc(v) && (v != 0 || !math.Signbit(v)) {
+		return appendIntValue(dst, int64(v))
+	}
+	return strconv.AppendFloat(dst, v, 'f', -1, 32)
+}
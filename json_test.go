@@ -1,6 +1,10 @@
 package zerolog
 
 import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -61,6 +65,34 @@ func TestAppendJSONString(t *testing.T) {
 	}
 }
 
+func TestAppendJSONStringWordBoundaries(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"\"", `\"`},
+		{"\\", `\\`},
+		{"\n", `\n`},
+		{"\x00", `\u0000`},
+		{"\x1f", `\u001f`},
+		{"\x7f", `\u007f`},
+		{"\xff", `\ufffd`},
+		{"é", `é`},
+		{" ", ` `},
+		{"~", `~`},
+	}
+	pad := strings.Repeat("a", 20)
+	for _, tt := range tests {
+		for p := 0; p <= len(pad); p++ {
+			in := pad[:p] + tt.in + pad[p:]
+			want := `"` + pad[:p] + tt.out + pad[p:] + `"`
+			if got := string(appendJSONString(nil, in)); got != want {
+				t.Errorf("appendJSONString(%q) = %#q, want %#q", in, got, want)
+			}
+		}
+	}
+}
+
 func BenchmarkAppendJSONString(b *testing.B) {
 	tests := map[string]string{
 		"NoEncoding":       `aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa`,
@@ -70,13 +102,87 @@ func BenchmarkAppendJSONString(b *testing.B) {
 		"MultiBytesFirst":  `❤️aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa`,
 		"MultiBytesMiddle": `aaaaaaaaaaaaaaaaaaaaaaaaa❤️aaaaaaaaaaaaaaaaaaaaaaaa`,
 		"MultiBytesLast":   `aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa❤️`,
+		"NoEncodingLong":   strings.Repeat(`aaaaaaaaaaaaaaaaaaaaaaaaa`, 20),
 	}
 	for name, str := range tests {
 		b.Run(name, func(b *testing.B) {
-			buf := make([]byte, 0, 100)
+			buf := make([]byte, 0, len(str)+100)
 			for i := 0; i < b.N; i++ {
 				_ = appendJSONString(buf, str)
 			}
 		})
 	}
 }
+
+func TestAppendIntValue(t *testing.T) {
+	vals := []int64{0, 1, 9, 10, 42, 99, 100, 101, 999, 1000, 65535, 1234567, 1 << 40, math.MaxInt64, -1, -10, -99, -100, -12345, math.MinInt64}
+	for i := 0; i < 1000; i++ {
+		vals = append(vals, rand.Int63()>>uint(rand.Intn(63)))
+	}
+	for _, v := range vals {
+		if got, want := string(appendIntValue(nil, v)), strconv.FormatInt(v, 10); got != want {
+			t.Errorf("appendIntValue(%d) = %s, want %s", v, got, want)
+		}
+		if v >= 0 {
+			if got, want := string(appendUintValue(nil, uint64(v))), strconv.FormatUint(uint64(v), 10); got != want {
+				t.Errorf("appendUintValue(%d) = %s, want %s", v, got, want)
+			}
+		}
+	}
+	if got, want := string(appendUintValue(nil, math.MaxUint64)), "18446744073709551615"; got != want {
+		t.Errorf("appendUintValue(MaxUint64) = %s, want %s", got, want)
+	}
+}
+
+func TestAppendFloatValue(t *testing.T) {
+	vals := []float64{0, math.Copysign(0, -1), 1, -1, 42, 0.5, 3.14159, -99.9, 1<<24 - 1, 1 << 24, 1<<24 + 1, 123456789, 1e20, -1e-20, math.MaxFloat32}
+	for i := 0; i < 1000; i++ {
+		vals = append(vals, float64(rand.Int63n(1<<26)-1<<25), rand.NormFloat64()*1e6)
+	}
+	for _, v := range vals {
+		if got, want := string(appendFloatValue(nil, v)), strconv.FormatFloat(v, 'f', -1, 32); got != want {
+			t.Errorf("appendFloatValue(%v) = %s, want %s", v, got, want)
+		}
+	}
+}
+
+var (
+	benchInts   = []int64{0, 7, 42, 123, 1000, 65535, 1234567, -42, 1 << 40}
+	benchFloats = []float64{0, 1, 42, 1234, 0.5, 3.14159, 99.9}
+)
+
+func BenchmarkAppendInt(b *testing.B) {
+	buf := make([]byte, 0, 100)
+	b.Run("strconv", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, v := range benchInts {
+				buf = strconv.AppendInt(buf[:0], v, 10)
+			}
+		}
+	})
+	b.Run("appendIntValue", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, v := range benchInts {
+				buf = appendIntValue(buf[:0], v)
+			}
+		}
+	})
+}
+
+func BenchmarkAppendFloat(b *testing.B) {
+	buf := make([]byte, 0, 100)
+	b.Run("strconv", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, v := range benchFloats {
+				buf = strconv.AppendFloat(buf[:0], v, 'f', -1, 32)
+			}
+		}
+	})
+	b.Run("appendFloatValue", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, v := range benchFloats {
+				buf = appendFloatValue(buf[:0], v)
+			}
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// consoleField is a top-level field of an event scanned by ConsoleWriter:
+// its unquoted key and its raw JSON value, both referencing the event unless
+// the key has escape sequences.
+type consoleField struct {
+	key []byte
+	raw []byte
+}
+
+// value returns the value of the field passed to the formatters: a string, a
+// json.Number, a bool, nil, or a json.RawMessage for objects and arrays. It
+// returns nil if the field is missing.
+func (f consoleField) value() interface{} {
+	if f.raw == nil {
+		return nil
+	}
+	return scanValue(f.raw)
+}
+
+// consoleFields holds the fields of an event, sorted by key.
+type consoleFields []consoleField
+
+// lookup returns the field key and true, or false if there is no such
+// field.
+func (f consoleFields) lookup(key string) (consoleField, bool) {
+	i, j := 0, len(f)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if string(f[h].key) < key {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	if i < len(f) && string(f[i].key) == key {
+		return f[i], true
+	}
+	return consoleField{}, false
+}
+
+var consoleFieldsPool = sync.Pool{
+	New: func() interface{} {
+		f := make(consoleFields, 0, 16)
+		return &f
+	},
+}
+
+var errNotObject = errors.New("event is not a JSON object")
+
+// scanFields appends the top-level fields of the JSON object p to dst,
+// without decoding their values, which reference p. The fields are sorted by
+// key and, for duplicate keys, the last one is kept.
+func scanFields(dst consoleFields, p []byte) (consoleFields, error) {
+	if !json.Valid(p) {
+		// Decode p for a detailed error.
+		var v interface{}
+		return dst, json.Unmarshal(p, &v)
+	}
+	i := skipSpace(p, 0)
+	if i == len(p) || p[i] != '{' {
+		return dst, errNotObject
+	}
+	for i++; ; i++ {
+		i = skipSpace(p, i)
+		if p[i] == '}' {
+			break
+		}
+		end := skipString(p, i)
+		key := unquoteJSONBytes(p[i:end])
+		i = skipSpace(p, end) + 1 // ':'
+		i = skipSpace(p, i)
+		end = skipValue(p, i)
+		dst = append(dst, consoleField{key, p[i:end]})
+		// Insertion sort, stable and cheap for the few fields of an event.
+		for j := len(dst) - 1; j > 0 && bytes.Compare(dst[j-1].key, dst[j].key) > 0; j-- {
+			dst[j-1], dst[j] = dst[j], dst[j-1]
+		}
+		i = skipSpace(p, end)
+		if p[i] == '}' {
+			break
+		}
+	}
+	// Keep the last of the duplicate keys, like the decoding in a map.
+	n := 0
+	for i := range dst {
+		if i+1 < len(dst) && bytes.Equal(dst[i+1].key, dst[i].key) {
+			continue
+		}
+		dst[n] = dst[i]
+		n++
+	}
+	return dst[:n], nil
+}
+
+// scanValue returns the value of the valid JSON value v.
+func scanValue(v []byte) interface{} {
+	switch v[0] {
+	case '"':
+		return unquoteJSON(v)
+	case '{', '[':
+		return json.RawMessage(v)
+	case 't':
+		return true
+	case 'f':
+		return false
+	case 'n':
+		return nil
+	}
+	return json.Number(v)
+}
+
+// unquoteJSON returns the content of the valid JSON string s.
+func unquoteJSON(s []byte) string {
+	for _, c := range s[1 : len(s)-1] {
+		if c == '\\' {
+			var v string
+			json.Unmarshal(s, &v)
+			return v
+		}
+	}
+	return string(s[1 : len(s)-1])
+}
+
+// unquoteJSONBytes returns the content of the valid JSON string s, sharing
+// the memory of s if it has no escape sequence.
+func unquoteJSONBytes(s []byte) []byte {
+	if bytes.IndexByte(s, '\\') == -1 {
+		return s[1 : len(s)-1]
+	}
+	return []byte(unquoteJSON(s))
+}
+
+func skipSpace(p []byte, i int) int {
+	for i < len(p) && (p[i] == ' ' || p[i] == '\t' || p[i] == '\n' || p[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// skipString returns the index following the valid JSON string starting at
+// p[i].
+func skipString(p []byte, i int) int {
+	for i++; p[i] != '"'; i++ {
+		if p[i] == '\\' {
+			i++
+		}
+	}
+	return i + 1
+}
+
+// skipValue returns the index following the valid JSON value starting at
+// p[i].
+func skipValue(p []byte, i int) int {
+	switch p[i] {
+	case '"':
+		return skipString(p, i)
+	case '{', '[':
+		depth := 0
+		for ; ; i++ {
+			switch p[i] {
+			case '"':
+				i = skipString(p, i) - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				if depth--; depth == 0 {
+					return i + 1
+				}
+			}
+		}
+	}
+	for i < len(p) && p[i] != ',' && p[i] != '}' && p[i] != ']' &&
+		p[i] != ' ' && p[i] != '\t' && p[i] != '\n' && p[i] != '\r' {
+		i++
+	}
+	return i
+}
+
+// writeCompactJSON writes the valid JSON value v to buf without
+// insignificant spaces.
+func writeCompactJSON(buf *bytes.Buffer, v []byte) {
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case '"':
+			i = skipString(v, i) - 1
+		case ' ', '\t', '\n', '\r':
+			json.Compact(buf, v)
+			return
+		}
+	}
+	buf.Write(v)
+}
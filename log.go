@@ -30,6 +30,9 @@
 //
 // Level logging
 //
+// Available levels, from lowest to highest, are trace, debug, info, warn,
+// error, fatal and panic.
+//
 //     zerolog.SetGlobalLevel(zerolog.InfoLevel)
 //
 //     log.Debug().Msg("filtered out message")
@@ -62,24 +65,36 @@
 //
 // Sample logs:
 //
-//     sampled := log.Sample(10)
+//     sampled := log.Sample(&zerolog.BasicSampler{N: 10})
 //     sampled.Info().Msg("will be logged every 10 messages")
 //
 package zerolog
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
-	"os"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // Level defines log levels.
-type Level uint8
+//
+// The standard levels keep their historical values, from -1 for trace to 5
+// for panic. Custom levels registered with RegisterLevel use the spare values
+// below TraceLevel or above PanicLevel.
+type Level int8
 
 const (
+	// TraceLevel defines trace log level.
+	TraceLevel Level = iota - 1
 	// DebugLevel defines debug log level.
-	DebugLevel Level = iota
+	DebugLevel
 	// InfoLevel defines info log level.
 	InfoLevel
 	// WarnLevel defines warn log level.
@@ -90,12 +105,21 @@ const (
 	FatalLevel
 	// PanicLevel defines panic log level.
 	PanicLevel
+	// AuditLevel defines the level of audit events. Audit events pass thru
+	// all the levels except Disabled and are never sampled, filtered or rate
+	// limited.
+	AuditLevel Level = math.MaxInt8 - 2
+	// NoLevel defines an absent log level. Events without level pass thru
+	// all the levels except Disabled.
+	NoLevel Level = math.MaxInt8 - 1
 	// Disabled disables the logger.
-	Disabled
+	Disabled Level = math.MaxInt8
 )
 
 func (l Level) String() string {
 	switch l {
+	case TraceLevel:
+		return "trace"
 	case DebugLevel:
 		return "debug"
 	case InfoLevel:
@@ -108,19 +132,94 @@ func (l Level) String() string {
 		return "fatal"
 	case PanicLevel:
 		return "panic"
+	case AuditLevel:
+		return "audit"
+	case NoLevel:
+		return ""
+	case Disabled:
+		return "disabled"
 	}
-	return ""
+	if name, ok := customLevels.Load().(map[Level]string)[l]; ok {
+		return name
+	}
+	return strconv.Itoa(int(l))
 }
 
-const (
-	// Often samples log every 10 events.
-	Often = 10
-	// Sometimes samples log every 100 events.
-	Sometimes = 100
-	// Rarely samples log every 1000 events.
-	Rarely = 1000
+// ParseLevel converts a level string into a zerolog Level value. Standard
+// level names are matched case-insensitively, then names registered with
+// RegisterLevel and finally numeric levels are tried. An empty string is
+// parsed as NoLevel.
+func ParseLevel(levelStr string) (Level, error) {
+	for _, l := range []Level{TraceLevel, DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel, PanicLevel, AuditLevel, NoLevel, Disabled} {
+		if strings.EqualFold(levelStr, l.String()) {
+			return l, nil
+		}
+	}
+	for l, name := range customLevels.Load().(map[Level]string) {
+		if levelStr == name {
+			return l, nil
+		}
+	}
+	i, err := strconv.Atoi(levelStr)
+	if err != nil || i < math.MinInt8 || i > math.MaxInt8 {
+		return 0, fmt.Errorf("unknown level: %q", levelStr)
+	}
+	return Level(i), nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface so levels
+// can be read from flags, environment variables and configuration files.
+func (l *Level) UnmarshalText(text []byte) error {
+	lvl, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = lvl
+	return nil
+}
+
+var (
+	customLevels   atomic.Value // map[Level]string
+	customLevelsMu sync.Mutex
 )
 
+// RegisterLevel associates name to the custom level l, so events logged at
+// this level with WithLevel get name as level field value. Unregistered
+// custom levels are rendered as a number.
+//
+// Custom levels are ordered with standard levels using their numeric value.
+// As the standard levels are consecutive, custom levels are either more
+// verbose than trace or more severe than panic, up to AuditLevel:
+//
+//     const AlertLevel = zerolog.PanicLevel + 1
+//     zerolog.RegisterLevel(AlertLevel, "alert")
+//
+// RegisterLevel panics if l is a standard level, NoLevel or Disabled.
+func RegisterLevel(l Level, name string) {
+	switch l {
+	case TraceLevel, DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel, PanicLevel, AuditLevel, NoLevel, Disabled:
+		panic("zerolog: cannot register standard level " + strconv.Itoa(int(l)))
+	}
+	customLevelsMu.Lock()
+	defer customLevelsMu.Unlock()
+	// Copy on write so String can read the map without locking.
+	levels := map[Level]string{}
+	for k, v := range customLevels.Load().(map[Level]string) {
+		levels[k] = v
+	}
+	levels[l] = name
+	customLevels.Store(levels)
+}
+
+func init() {
+	customLevels.Store(map[Level]string{})
+}
+
 var disabledEvent = newEvent(levelWriterAdapter{ioutil.Discard}, 0, false)
 
 // A Logger represents an active logging object that generates lines
@@ -129,11 +228,25 @@ var disabledEvent = newEvent(levelWriterAdapter{ioutil.Discard}, 0, false)
 // serialization to the Writer. If your Writer is not thread safe,
 // you may consider a sync wrapper.
 type Logger struct {
-	w       LevelWriter
-	level   Level
-	sample  uint32
-	counter *uint32
-	context []byte
+	w         LevelWriter
+	level     Level
+	sampler   Sampler
+	context   []byte
+	component *componentLevel
+	override  *Level
+	floor     *Level
+	filters   []FilterFunc
+	filtered  bool
+	demoters  []DemoteFunc
+	severity  SeverityMap
+	once      *onceKey
+	hooks     []boundHook
+	hookNames []string
+	bufHooks  []BufferHook
+	ctx       context.Context
+	onError   func(err error)
+	sizeHint  int
+	deferred  bool
 }
 
 // New creates a root logger with given output writer. If the output writer implements
@@ -151,7 +264,7 @@ func New(w io.Writer) Logger {
 	if !ok {
 		lw = levelWriterAdapter{w}
 	}
-	return Logger{w: lw}
+	return Logger{w: lw, level: TraceLevel}
 }
 
 // Nop returns a disabled logger for which all operation are no-op.
@@ -160,82 +273,237 @@ func Nop() Logger {
 }
 
 // With creates a child logger with the field added to its context.
+//
+// The context of the parent is shared with the child until a field is added
+// to the child, so deriving many child loggers without fields of their own
+// does not copy the context of the parent.
 func (l Logger) With() Context {
-	context := l.context
-	l.context = make([]byte, 0, 500)
-	if context != nil {
-		l.context = append(l.context, context...)
-	} else {
+	if l.context == nil {
+		l.context = make([]byte, 0, 500)
 		// first byte of context is presence of timestamp or not
 		l.context = append(l.context, 0)
+	} else {
+		// Cap the capacity so the first field added to the child copies
+		// the context instead of writing to the array of the parent.
+		l.context = l.context[:len(l.context):len(l.context)]
 	}
 	return Context{l}
 }
 
 // Level creates a child logger with the minimum accepted level set to level.
+// The level of the parent is replaced: use With().Level(lvl) to derive a
+// child logger which can't be more verbose than its parent.
 func (l Logger) Level(lvl Level) Logger {
-	return Logger{
-		w:       l.w,
-		level:   lvl,
-		sample:  l.sample,
-		counter: l.counter,
-		context: l.context,
-	}
-}
-
-// Sample returns a logger that only let one message out of every to pass thru.
-func (l Logger) Sample(every int) Logger {
-	if every == 0 {
-		// Create a child with no sampling.
-		return Logger{
-			w:       l.w,
-			level:   l.level,
-			context: l.context,
+	l.level = lvl
+	return l
+}
+
+// GetLevel returns the minimum level of the logger as set with Level.
+func (l Logger) GetLevel() Level {
+	return l.level
+}
+
+// EffectiveLevel returns the minimum level of the events the logger
+// currently accepts, taking the global level, the component level and the
+// context level override into account. Libraries can use it to enable
+// expensive instrumentation only when it will be logged.
+//
+// Events at or above this level may still be dropped by samplers or filters.
+func (l Logger) EffectiveLevel() Level {
+	if l.filtered {
+		return Disabled
+	}
+	return l.minLevel()
+}
+
+// minLevel returns the minimum accepted level, ignoring filters.
+func (l *Logger) minLevel() Level {
+	min, gLvl := l.level, GlobalLevel()
+	if min == Disabled || gLvl == Disabled {
+		// Overrides never unmute a disabled logger.
+		return Disabled
+	}
+	if l.override != nil {
+		// A context level override takes precedence over all other levels.
+		min, gLvl = *l.override, *l.override
+	} else if l.component != nil {
+		// A component level overrides both the logger and global levels.
+		if cLvl, ok := l.component.get(); ok {
+			min, gLvl = cLvl, cLvl
 		}
 	}
-	return Logger{
-		w:       l.w,
-		level:   l.level,
-		sample:  uint32(every),
-		counter: new(uint32),
-		context: l.context,
+	if l.floor != nil && *l.floor > min {
+		// Overrides cannot make a logger more verbose than the level it was
+		// clamped to with Context.Level.
+		min = *l.floor
+	}
+	if gLvl > min {
+		return gLvl
+	}
+	return min
+}
+
+// Sample returns a logger with the s sampler. A nil sampler disables
+// sampling.
+func (l Logger) Sample(s Sampler) Logger {
+	l.sampler = s
+	return l
+}
+
+// ErrorHandler returns a logger calling f instead of the global ErrorHandler
+// when the writer fails to write an event.
+func (l Logger) ErrorHandler(f func(err error)) Logger {
+	l.onError = f
+	return l
+}
+
+// Hook returns a logger with the h Hook. Hooks are run in the order they
+// were added when the message of an event is set.
+//
+// See NamedHook to manage hooks by name.
+func (l Logger) Hook(h Hook) Logger {
+	return l.insertHook(len(l.hooks), "", h)
+}
+
+// Filter returns a child logger dropping events with a string field matching
+// f. Filters are evaluated as soon as a field is added: a sub-logger with a
+// context field matching a filter is muted, and an event with a matching field
+// stops encoding its remaining fields. The message is also matched using the
+// zerolog.MessageFieldName key.
+//
+// Only string fields added with Str are evaluated.
+func (l Logger) Filter(f FilterFunc) Logger {
+	// Copy so siblings don't share the same backing array.
+	filters := make([]FilterFunc, len(l.filters), len(l.filters)+1)
+	copy(filters, l.filters)
+	l.filters = append(filters, f)
+	return l
+}
+
+// Once returns a child logger emitting a single event for key during the
+// lifetime of the process: once an event has been logged by a logger with
+// key, all the subsequent events of the loggers with the same key are
+// dropped. This is useful for "deprecated option used" style warnings:
+//
+//     log.Once("deprecated-timeout").Warn().Msg("timeout is deprecated, use deadline")
+//
+// Events dropped by their level, a filter or a sampler do not consume the
+// key.
+//
+// Keys are kept for the lifetime of the process, so they should come from a
+// small fixed set. Keys of OnceTTL are removed once their ttl elapsed.
+func (l Logger) Once(key string) Logger {
+	l.once = &onceKey{key: key}
+	return l
+}
+
+// OnceTTL is like Once but lets an event with key pass again once ttl has
+// elapsed since the last logged one.
+func (l Logger) OnceTTL(key string, ttl time.Duration) Logger {
+	l.once = &onceKey{key: key, ttl: ttl}
+	return l
+}
+
+// Severity returns a child logger adding the severity of each event, as
+// mapped by m, using the zerolog.SeverityFieldName field name. Use it to feed
+// systems expecting their own severity vocabulary:
+//
+//     log = log.Severity(zerolog.GCPSeverities)
+func (l Logger) Severity(m SeverityMap) Logger {
+	l.severity = m
+	return l
+}
+
+// DeferFields returns a child logger whose events keep their fields as typed
+// values and only serialize them once the events are known to be written,
+// after the filters and the message samplers accepted them. Events dropped
+// by a filter or a sampler thus never pay the cost of encoding their fields,
+// at the cost of copying them. Use it for loggers whose events are mostly
+// dropped, like heavily sampled debug logs.
+//
+// The values added with Interface are marshaled when the event is written:
+// they must not be modified until then.
+func (l Logger) DeferFields() Logger {
+	l.deferred = true
+	return l
+}
+
+// WithEventSizeHint returns a child logger whose events start with a buffer
+// of at least n bytes. Use it for loggers writing consistently large events,
+// such as request dumps, so their buffers do not grow by repeated
+// reallocation when the pooled buffers are smaller. n should not exceed
+// EventBufferMaxSize, or the buffers are discarded instead of being pooled.
+func (l Logger) WithEventSizeHint(n int) Logger {
+	l.sizeHint = n
+	return l
+}
+
+// Demote returns a child logger changing the level of events with an error
+// matching f, added with Err or AnErr. This is useful to demote known-benign
+// errors from Error to Warn so they stop triggering alerts while remaining in
+// the logs. Demoted events are written even if their new level is below the
+// logger level. Demoting an event created with Fatal or Panic does not prevent
+// the program from exiting or panicking.
+//
+// The first matching rule wins.
+func (l Logger) Demote(f DemoteFunc) Logger {
+	demoters := make([]DemoteFunc, len(l.demoters), len(l.demoters)+1)
+	copy(demoters, l.demoters)
+	l.demoters = append(demoters, f)
+	return l
+}
+
+// Trace starts a new message with trace level.
+//
+// You must call Msg on the returned event in order to send the event.
+func (l Logger) Trace() *Event {
+	if !DebugEnabled {
+		return disabledEvent
 	}
+	return l.newEvent(TraceLevel, nil)
 }
 
 // Debug starts a new message with debug level.
 //
 // You must call Msg on the returned event in order to send the event.
 func (l Logger) Debug() *Event {
-	return l.newEvent(DebugLevel, true, nil)
+	if !DebugEnabled {
+		return disabledEvent
+	}
+	return l.newEvent(DebugLevel, nil)
 }
 
 // Info starts a new message with info level.
 //
 // You must call Msg on the returned event in order to send the event.
 func (l Logger) Info() *Event {
-	return l.newEvent(InfoLevel, true, nil)
+	return l.newEvent(InfoLevel, nil)
 }
 
 // Warn starts a new message with warn level.
 //
 // You must call Msg on the returned event in order to send the event.
 func (l Logger) Warn() *Event {
-	return l.newEvent(WarnLevel, true, nil)
+	return l.newEvent(WarnLevel, nil)
 }
 
 // Error starts a new message with error level.
 //
 // You must call Msg on the returned event in order to send the event.
 func (l Logger) Error() *Event {
-	return l.newEvent(ErrorLevel, true, nil)
+	return l.newEvent(ErrorLevel, nil)
 }
 
-// Fatal starts a new message with fatal level. The os.Exit(1) function
-// is called by the Msg method.
+// Fatal starts a new message with fatal level. The Msg method calls the exit
+// hooks registered with RegisterExitHook and then ExitFunc(1), os.Exit by
+// default.
 //
 // You must call Msg on the returned event in order to send the event.
 func (l Logger) Fatal() *Event {
-	return l.newEvent(FatalLevel, true, func(msg string) { os.Exit(1) })
+	return l.newEvent(FatalLevel, func(msg string) {
+		runExitHooks()
+		ExitFunc(1)
+	})
 }
 
 // Panic starts a new message with panic level. The message is also sent
@@ -243,17 +511,41 @@ func (l Logger) Fatal() *Event {
 //
 // You must call Msg on the returned event in order to send the event.
 func (l Logger) Panic() *Event {
-	return l.newEvent(PanicLevel, true, func(msg string) { panic(msg) })
+	return l.newEvent(PanicLevel, func(msg string) { panic(msg) })
 }
 
-// Log starts a new message with no level. Setting GlobalLevel to Disabled
+// WithLevel starts a new message with level. Unlike Fatal and Panic
+// methods, WithLevel does not terminate the program or stop the ordinary
+// flow of a goroutine when used with their respective levels.
+//
+// Use this method with custom levels registered with RegisterLevel. Using
+// NoLevel is equivalent to calling Log.
+//
+// You must call Msg on the returned event in order to send the event.
+func (l Logger) WithLevel(level Level) *Event {
+	if level == Disabled {
+		return disabledEvent
+	}
+	return l.newEvent(level, nil)
+}
+
+// Audit starts a new message with audit level. Audit events are meant for
+// compliance-relevant records that must always be persisted: they pass thru
+// all the levels except Disabled and bypass samplers, filters and
+// RateLimitWriter.
+//
+// You must call Msg on the returned event in order to send the event.
+func (l Logger) Audit() *Event {
+	return l.newEvent(AuditLevel, nil)
+}
+
+// Log starts a new message with no level. Such events pass thru all the
+// levels except Disabled: setting the logger level or GlobalLevel to Disabled
 // will still disable events produced by this method.
 //
 // You must call Msg on the returned event in order to send the event.
 func (l Logger) Log() *Event {
-	// We use panic level with addLevelField=false to make Log passthrough all
-	// levels except Disabled.
-	return l.newEvent(PanicLevel, false, nil)
+	return l.newEvent(NoLevel, nil)
 }
 
 // Write implements the io.Writer interface. This is useful to set as a writer
@@ -268,44 +560,84 @@ func (l Logger) Write(p []byte) (n int, err error) {
 	return
 }
 
-func (l Logger) newEvent(level Level, addLevelField bool, done func(string)) *Event {
+func (l *Logger) newEvent(level Level, done func(string)) *Event {
 	enabled := l.should(level)
 	if !enabled {
 		return disabledEvent
 	}
-	lvl := InfoLevel
-	if addLevelField {
-		lvl = level
+	e := newEvent(l.w, level, enabled)
+	l.initEvent(e, level, done)
+	return e
+}
+
+// initEvent sets the logger fields of e, a reset enabled event.
+func (l *Logger) initEvent(e *Event, level Level, done func(string)) {
+	if l.sizeHint > cap(e.buf) {
+		e.buf = append(make([]byte, 0, l.sizeHint), e.buf...)
 	}
-	e := newEvent(l.w, lvl, enabled)
 	e.done = done
+	e.hooks = l.hooks
+	e.ctx = l.ctx
+	e.component = l.component
+	e.onError = l.onError
+	e.bufHooks = l.bufHooks
+	e.once = l.once
+	if level != AuditLevel {
+		e.filters = l.filters
+		e.demoters = l.demoters
+		if ms, ok := l.sampler.(MessageSampler); ok && !samplingDisabled() {
+			e.sampler = ms
+		}
+	}
 	if l.context != nil && len(l.context) > 0 && l.context[0] > 0 {
 		// first byte of context is ts flag
 		e.buf = appendTimestamp(e.buf)
 	}
-	if addLevelField {
+	if level != NoLevel {
+		e.levelPos = len(e.buf)
 		e.Str(LevelFieldName, level.String())
+		e.levelEnd = len(e.buf)
 	}
-	if l.sample > 0 && SampleFieldName != "" {
-		e.Uint32(SampleFieldName, l.sample)
+	if l.severity != nil && SeverityFieldName != "" {
+		if sev := l.severity.Severity(level); sev != "" {
+			e.Str(SeverityFieldName, sev)
+		}
+	}
+	if sr, ok := l.sampler.(SampleRater); ok && SampleRateFieldName != "" && level != AuditLevel {
+		e.Uint32(SampleRateFieldName, sr.SampleRate())
 	}
 	if l.context != nil && len(l.context) > 1 {
-		if len(e.buf) > 1 {
-			e.buf = append(e.buf, ',')
-		}
-		e.buf = append(e.buf, l.context[1:]...)
+		e.buf = appendObjectData(e.buf, l.context[1:])
 	}
-	return e
+	e.deferred = l.deferred
 }
 
 // should returns true if the log event should be logged.
-func (l Logger) should(lvl Level) bool {
-	if lvl < l.level || lvl < globalLevel() {
+//
+// The level is checked first so disabled events cost a single atomic load of
+// the global level, whatever the filters, samplers or hooks of the logger,
+// unless it has a component level or a context level override.
+func (l *Logger) should(lvl Level) bool {
+	if !DebugEnabled && lvl < InfoLevel {
+		return false
+	}
+	if l.component == nil && l.override == nil {
+		if lvl < l.level || (l.floor != nil && lvl < *l.floor) || lvl < GlobalLevel() {
+			return false
+		}
+	} else if lvl < l.minLevel() {
+		return false
+	}
+	if l.filtered && lvl != AuditLevel {
+		return false
+	}
+	if l.once != nil && l.once.taken() {
+		// The key is only consumed by Event.msg, once the event passed all
+		// the other checks.
 		return false
 	}
-	if l.sample > 0 && l.counter != nil && !samplingDisabled() {
-		c := atomic.AddUint32(l.counter, 1)
-		return c%l.sample == 0
+	if l.sampler != nil && lvl != AuditLevel && !samplingDisabled() {
+		return l.sampler.Sample(lvl)
 	}
 	return true
 }
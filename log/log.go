@@ -21,9 +21,21 @@ func Level(level zerolog.Level) zerolog.Logger {
 	return Logger.Level(level)
 }
 
-// Sample returns a logger that only let one message out of every to pass thru.
-func Sample(every int) zerolog.Logger {
-	return Logger.Sample(every)
+// GetLevel returns the minimum level of the global logger.
+func GetLevel() zerolog.Level {
+	return Logger.GetLevel()
+}
+
+// Sample returns a logger with the s sampler.
+func Sample(s zerolog.Sampler) zerolog.Logger {
+	return Logger.Sample(s)
+}
+
+// Trace starts a new message with trace level.
+//
+// You must call Msg on the returned event in order to send the event.
+func Trace() *zerolog.Event {
+	return Logger.Trace()
 }
 
 // Debug starts a new message with debug level.
@@ -54,8 +66,9 @@ func Error() *zerolog.Event {
 	return Logger.Error()
 }
 
-// Fatal starts a new message with fatal level. The os.Exit(1) function
-// is called by the Msg method.
+// Fatal starts a new message with fatal level. The Msg method calls the exit
+// hooks registered with zerolog.RegisterExitHook and then zerolog.ExitFunc(1),
+// os.Exit by default.
 //
 // You must call Msg on the returned event in order to send the event.
 func Fatal() *zerolog.Event {
@@ -70,6 +83,23 @@ func Panic() *zerolog.Event {
 	return Logger.Panic()
 }
 
+// WithLevel starts a new message with level. Unlike Fatal and Panic
+// methods, WithLevel does not terminate the program or stop the ordinary
+// flow of a goroutine when used with their respective levels.
+//
+// You must call Msg on the returned event in order to send the event.
+func WithLevel(level zerolog.Level) *zerolog.Event {
+	return Logger.WithLevel(level)
+}
+
+// Audit starts a new message with audit level. Audit events are never
+// sampled, filtered or rate limited.
+//
+// You must call Msg on the returned event in order to send the event.
+func Audit() *zerolog.Event {
+	return Logger.Audit()
+}
+
 // Log starts a new message with no level. Setting zerolog.GlobalLevel to
 // zerlog.Disabled will still disable events produced by this method.
 //
@@ -0,0 +1,20 @@
+// +build !zerolog_bson,!zerolog_nodebug
+
+package zerolog_test
+
+import (
+	"os"
+
+	"github.com/rs/zerolog"
+)
+
+func ExampleLogger_Debug() {
+	log := zerolog.New(os.Stdout)
+
+	log.Debug().
+		Str("foo", "bar").
+		Int("n", 123).
+		Msg("hello world")
+
+	// Output: {"level":"debug","foo":"bar","n":123,"message":"hello world"}
+}
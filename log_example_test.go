@@ -1,3 +1,5 @@
+// +build !zerolog_bson
+
 package zerolog_test
 
 import (
@@ -38,26 +40,15 @@ func ExampleLogger_Level() {
 }
 
 func ExampleLogger_Sample() {
-	log := zerolog.New(os.Stdout).Sample(2)
+	log := zerolog.New(os.Stdout).Sample(&zerolog.BasicSampler{N: 2})
 
 	log.Info().Msg("message 1")
 	log.Info().Msg("message 2")
 	log.Info().Msg("message 3")
 	log.Info().Msg("message 4")
 
-	// Output: {"level":"info","sample":2,"message":"message 2"}
-	// {"level":"info","sample":2,"message":"message 4"}
-}
-
-func ExampleLogger_Debug() {
-	log := zerolog.New(os.Stdout)
-
-	log.Debug().
-		Str("foo", "bar").
-		Int("n", 123).
-		Msg("hello world")
-
-	// Output: {"level":"debug","foo":"bar","n":123,"message":"hello world"}
+	// Output: {"level":"info","message":"message 1"}
+	// {"level":"info","message":"message 3"}
 }
 
 func ExampleLogger_Info() {
@@ -0,0 +1,60 @@
+// +build !zerolog_bson
+
+package zerolog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestTimestampCache(t *testing.T) {
+	defer func(f func() time.Time) { TimestampFunc = f }(TimestampFunc)
+	now := time.Date(2001, time.February, 3, 4, 5, 6, 7, time.UTC)
+	TimestampFunc = func() time.Time { return now }
+	// The refresh goroutine does not tick during the test.
+	SetTimestampCache(time.Hour)
+	defer SetTimestampCache(0)
+	out := &bytes.Buffer{}
+	log := New(out).With().Timestamp().Logger()
+	log.Log().Msg("")
+	now = now.Add(30 * time.Minute)
+	log.Log().Msg("")
+	now = now.Add(30 * time.Minute)
+	refreshTimestamp(time.Hour)
+	log.Log().Msg("")
+	want := `{"time":"2001-02-03T04:00:00Z"}` + "\n" + `{"time":"2001-02-03T04:00:00Z"}` + "\n" + `{"time":"2001-02-03T05:00:00Z"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	defer func(f string) { TimeFieldFormat = f }(TimeFieldFormat)
+	TimeFieldFormat = time.Kitchen
+	log.Log().Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"time":"5:05AM"}`+"\n"; got != want {
+		t.Errorf("invalid log output after a format change: got %q, want %q", got, want)
+	}
+
+	out.Reset()
+	SetTimestampCache(0)
+	log.Log().Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"time":"5:05AM"}`+"\n"; got != want {
+		t.Errorf("invalid log output without cache: got %q, want %q", got, want)
+	}
+}
+
+func TestBytesRawJSONAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector drops the pooled events")
+	}
+	log := New(ioutil.Discard)
+	b, raw := []byte("some bytes"), []byte(`{"a":[1,"two",{"b":null}]}`)
+	allocs := testing.AllocsPerRun(100, func() {
+		log.Info().Bytes("bytes", b).RawJSON("raw", raw).Msg("")
+	})
+	if allocs != 0 {
+		t.Errorf("allocs = %v, want 0", allocs)
+	}
+}
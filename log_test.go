@@ -2,18 +2,36 @@ package zerolog
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"reflect"
 	"testing"
 	"time"
 )
 
+// decodeIfBinaryToString returns the events written in p as JSON, so the
+// tests comparing JSON output also run with the zerolog_bson build tag.
+func decodeIfBinaryToString(p []byte) string {
+	return string(decodeIfBinaryToBytes(p))
+}
+
+// skipIfNoDebug skips the tests expecting debug or trace events when they
+// are compiled out with the zerolog_nodebug build tag.
+func skipIfNoDebug(t *testing.T) {
+	if !DebugEnabled {
+		t.Skip("debug and trace events are compiled out")
+	}
+}
+
 func TestLog(t *testing.T) {
 	t.Run("empty", func(t *testing.T) {
 		out := &bytes.Buffer{}
 		log := New(out)
 		log.Log().Msg("")
-		if got, want := out.String(), "{}\n"; got != want {
+		if got, want := decodeIfBinaryToString(out.Bytes()), "{}\n"; got != want {
 			t.Errorf("invalid log output: got %q, want %q", got, want)
 		}
 	})
@@ -22,7 +40,7 @@ func TestLog(t *testing.T) {
 		out := &bytes.Buffer{}
 		log := New(out)
 		log.Log().Str("foo", "bar").Msg("")
-		if got, want := out.String(), `{"foo":"bar"}`+"\n"; got != want {
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"foo":"bar"}`+"\n"; got != want {
 			t.Errorf("invalid log output: got %q, want %q", got, want)
 		}
 	})
@@ -34,7 +52,7 @@ func TestLog(t *testing.T) {
 			Str("foo", "bar").
 			Int("n", 123).
 			Msg("")
-		if got, want := out.String(), `{"foo":"bar","n":123}`+"\n"; got != want {
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"foo":"bar","n":123}`+"\n"; got != want {
 			t.Errorf("invalid log output: got %q, want %q", got, want)
 		}
 	})
@@ -45,7 +63,7 @@ func TestInfo(t *testing.T) {
 		out := &bytes.Buffer{}
 		log := New(out)
 		log.Info().Msg("")
-		if got, want := out.String(), `{"level":"info"}`+"\n"; got != want {
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info"}`+"\n"; got != want {
 			t.Errorf("invalid log output: got %q, want %q", got, want)
 		}
 	})
@@ -54,7 +72,7 @@ func TestInfo(t *testing.T) {
 		out := &bytes.Buffer{}
 		log := New(out)
 		log.Info().Str("foo", "bar").Msg("")
-		if got, want := out.String(), `{"level":"info","foo":"bar"}`+"\n"; got != want {
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","foo":"bar"}`+"\n"; got != want {
 			t.Errorf("invalid log output: got %q, want %q", got, want)
 		}
 	})
@@ -66,7 +84,7 @@ func TestInfo(t *testing.T) {
 			Str("foo", "bar").
 			Int("n", 123).
 			Msg("")
-		if got, want := out.String(), `{"level":"info","foo":"bar","n":123}`+"\n"; got != want {
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","foo":"bar","n":123}`+"\n"; got != want {
 			t.Errorf("invalid log output: got %q, want %q", got, want)
 		}
 	})
@@ -94,11 +112,39 @@ func TestWith(t *testing.T) {
 		Time("time", time.Time{}).
 		Logger()
 	log.Log().Msg("")
-	if got, want := out.String(), `{"foo":"bar","error":"some error","bool":true,"int":1,"int8":2,"int16":3,"int32":4,"int64":5,"uint":6,"uint8":7,"uint16":8,"uint32":9,"uint64":10,"float32":11,"float64":12,"time":"0001-01-01T00:00:00Z"}`+"\n"; got != want {
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"foo":"bar","error":"some error","bool":true,"int":1,"int8":2,"int16":3,"int32":4,"int64":5,"uint":6,"uint8":7,"uint16":8,"uint32":9,"uint64":10,"float32":11,"float64":12,"time":"0001-01-01T00:00:00Z"}`+"\n"; got != want {
 		t.Errorf("invalid log output: got %q, want %q", got, want)
 	}
 }
 
+func TestWithSharedContext(t *testing.T) {
+	out := &bytes.Buffer{}
+	parent := New(out).With().Str("foo", "bar").Logger()
+	a := parent.With().Str("child", "a").Logger()
+	b := parent.With().Str("child", "b").Logger()
+	ts := parent.With().Timestamp().Logger()
+	parent.Log().Msg("")
+	a.Log().Msg("")
+	b.Log().Msg("")
+	want := `{"foo":"bar"}` + "\n" + `{"foo":"bar","child":"a"}` + "\n" + `{"foo":"bar","child":"b"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if parent.context[0] != 0 || ts.context[0] != 1 {
+		t.Error("Timestamp of the child changed the context of the parent")
+	}
+}
+
+func TestWithNoCopy(t *testing.T) {
+	parent := New(ioutil.Discard).With().Str("foo", "bar").Logger()
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = parent.With().Logger()
+	})
+	if allocs != 0 {
+		t.Errorf("With allocated %v times, want 0", allocs)
+	}
+}
+
 func TestFields(t *testing.T) {
 	out := &bytes.Buffer{}
 	log := New(out)
@@ -124,7 +170,7 @@ func TestFields(t *testing.T) {
 		Time("time", time.Time{}).
 		TimeDiff("diff", now, now.Add(-10*time.Second)).
 		Msg("")
-	if got, want := out.String(), `{"foo":"bar","error":"some error","bool":true,"int":1,"int8":2,"int16":3,"int32":4,"int64":5,"uint":6,"uint8":7,"uint16":8,"uint32":9,"uint64":10,"float32":11,"float64":12,"dur":1000,"time":"0001-01-01T00:00:00Z","diff":10000}`+"\n"; got != want {
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"foo":"bar","error":"some error","bool":true,"int":1,"int8":2,"int16":3,"int32":4,"int64":5,"uint":6,"uint8":7,"uint16":8,"uint32":9,"uint64":10,"float32":11,"float64":12,"dur":1000,"time":"0001-01-01T00:00:00Z","diff":10000}`+"\n"; got != want {
 		t.Errorf("invalid log output: got %q, want %q", got, want)
 	}
 }
@@ -154,7 +200,7 @@ func TestFieldsDisabled(t *testing.T) {
 		Time("time", time.Time{}).
 		TimeDiff("diff", now, now.Add(-10*time.Second)).
 		Msg("")
-	if got, want := out.String(), ""; got != want {
+	if got, want := decodeIfBinaryToString(out.Bytes()), ""; got != want {
 		t.Errorf("invalid log output: got %q, want %q", got, want)
 	}
 }
@@ -162,26 +208,165 @@ func TestFieldsDisabled(t *testing.T) {
 func TestMsgf(t *testing.T) {
 	out := &bytes.Buffer{}
 	New(out).Log().Msgf("one %s %.1f %d %v", "two", 3.4, 5, errors.New("six"))
-	if got, want := out.String(), `{"message":"one two 3.4 5 six"}`+"\n"; got != want {
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"message":"one two 3.4 5 six"}`+"\n"; got != want {
 		t.Errorf("invalid log output: got %q, want %q", got, want)
 	}
 }
 
+func TestStrUnsafe(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().StrUnsafe("id", "abc-123").Logger()
+	log.Log().StrUnsafe("foo", "bar").Str("quoted", `"a"`).Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"id":"abc-123","foo":"bar","quoted":"\"a\""}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+type testObject struct {
+	name string
+	n    int
+}
+
+func (o testObject) MarshalZerologObject(e *Event) {
+	e.Str("name", o.name).Int("n", o.n)
+}
+
+func TestObject(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().Object("ctx", testObject{"a", 1}).Logger()
+	log.Log().Object("obj", testObject{"b", 2}).EmbedObject(testObject{"c", 3}).Object("nil", nil).Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"ctx":{"name":"a","n":1},"obj":{"name":"b","n":2},"name":"c","n":3}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestBytesRawJSON(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().Bytes("ctx", []byte("c")).RawJSON("raw", []byte(`[1]`)).Logger()
+	log.Log().
+		Bytes("bytes", []byte(`a"b`)).
+		RawJSON("obj", []byte(`{"a":1}`)).
+		RawJSON("invalid", []byte(`{"a"`)).
+		Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"ctx":"c","raw":[1],"bytes":"a\"b","obj":{"a":1},"invalid":"{\"a\""}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestBase64(t *testing.T) {
+	defer func() { Base64Encoding = base64.StdEncoding }()
+	out := &bytes.Buffer{}
+	sig := []byte{0xfb, 0xff, 0x01}
+	log := New(out).With().Base64("key", []byte("k")).Logger()
+	log.Log().Base64("sig", sig).Base64("empty", nil).Msg("")
+	Base64Encoding = base64.RawURLEncoding
+	log.Log().Base64("sig", sig).Msg("")
+	want := `{"key":"aw==","sig":"+/8B","empty":""}` + "\n" + `{"key":"aw==","sig":"-_8B"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	discard := New(ioutil.Discard)
+	allocs := testing.AllocsPerRun(100, func() {
+		discard.Info().Base64("sig", sig).Msg("")
+	})
+	if allocs != 0 {
+		t.Errorf("allocs = %v, want 0", allocs)
+	}
+}
+
+func TestRawJSONValidation(t *testing.T) {
+	defer func() { RawJSONValidation = true }()
+	RawJSONValidation = false
+	out := &bytes.Buffer{}
+	New(out).Log().RawJSON("trusted", []byte(`{"a":1}`)).Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"trusted":{"a":1}}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
 func TestWithAndFieldsCombined(t *testing.T) {
 	out := &bytes.Buffer{}
 	log := New(out).With().Str("f1", "val").Str("f2", "val").Logger()
 	log.Log().Str("f3", "val").Msg("")
-	if got, want := out.String(), `{"f1":"val","f2":"val","f3":"val"}`+"\n"; got != want {
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"f1":"val","f2":"val","f3":"val"}`+"\n"; got != want {
 		t.Errorf("invalid log output: got %q, want %q", got, want)
 	}
 }
 
+func TestContextLevel(t *testing.T) {
+	out := &bytes.Buffer{}
+	parent := New(out).Level(InfoLevel)
+	louder := parent.With().Level(DebugLevel).Logger()
+	quieter := parent.With().Level(ErrorLevel).Logger()
+	louder.Debug().Msg("filtered")
+	louder.Info().Msg("louder")
+	quieter.Warn().Msg("filtered")
+	quieter.Error().Msg("quieter")
+	// Logger.Level cannot lower the level below the clamped one either.
+	lowered := quieter.Level(InfoLevel)
+	lowered.Warn().Msg("filtered")
+	if got := lowered.EffectiveLevel(); got != ErrorLevel {
+		t.Errorf("EffectiveLevel: got %v, want %v", got, ErrorLevel)
+	}
+	want := `{"level":"info","message":"louder"}` + "\n" + `{"level":"error","message":"quieter"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, want)
+	}
+	if got := quieter.With().Level(WarnLevel).Logger().GetLevel(); got != ErrorLevel {
+		t.Errorf("level not clamped: got %v, want %v", got, ErrorLevel)
+	}
+}
+
+func TestGetLevel(t *testing.T) {
+	defer SetGlobalLevel(GlobalLevel())
+	SetGlobalLevel(TraceLevel)
+	log := New(nil).Level(InfoLevel)
+	if got := log.GetLevel(); got != InfoLevel {
+		t.Errorf("GetLevel: got %v, want %v", got, InfoLevel)
+	}
+	if got := log.EffectiveLevel(); got != InfoLevel {
+		t.Errorf("EffectiveLevel: got %v, want %v", got, InfoLevel)
+	}
+	SetGlobalLevel(ErrorLevel)
+	if got := log.EffectiveLevel(); got != ErrorLevel {
+		t.Errorf("EffectiveLevel with global level: got %v, want %v", got, ErrorLevel)
+	}
+	defer UnsetComponentLevel("getlevel")
+	SetComponentLevel("getlevel", DebugLevel)
+	if got := log.With().Component("getlevel").Logger().EffectiveLevel(); got != DebugLevel {
+		t.Errorf("EffectiveLevel with component level: got %v, want %v", got, DebugLevel)
+	}
+	filtered := log.Filter(FieldEquals("a", "b")).With().Str("a", "b").Logger()
+	if got := filtered.EffectiveLevel(); got != Disabled {
+		t.Errorf("EffectiveLevel of filtered logger: got %v, want %v", got, Disabled)
+	}
+}
+
 func TestLevel(t *testing.T) {
 	t.Run("Disabled", func(t *testing.T) {
 		out := &bytes.Buffer{}
 		log := New(out).Level(Disabled)
 		log.Info().Msg("test")
-		if got, want := out.String(), ""; got != want {
+		if got, want := decodeIfBinaryToString(out.Bytes()), ""; got != want {
+			t.Errorf("invalid log output: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Trace", func(t *testing.T) {
+		skipIfNoDebug(t)
+		out := &bytes.Buffer{}
+		log := New(out)
+		log.Trace().Msg("test")
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"trace","message":"test"}`+"\n"; got != want {
+			t.Errorf("invalid log output: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Debug filters Trace", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := New(out).Level(DebugLevel)
+		log.Trace().Msg("test")
+		if got, want := decodeIfBinaryToString(out.Bytes()), ""; got != want {
 			t.Errorf("invalid log output: got %q, want %q", got, want)
 		}
 	})
@@ -190,20 +375,208 @@ func TestLevel(t *testing.T) {
 		out := &bytes.Buffer{}
 		log := New(out).Level(InfoLevel)
 		log.Info().Msg("test")
-		if got, want := out.String(), `{"level":"info","message":"test"}`+"\n"; got != want {
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"test"}`+"\n"; got != want {
+			t.Errorf("invalid log output: got %q, want %q", got, want)
+		}
+	})
+}
+
+type countSampler struct{ n int }
+
+func (s *countSampler) Sample(lvl Level) bool {
+	s.n++
+	return true
+}
+
+func TestDisabledLevelFastPath(t *testing.T) {
+	defer SetGlobalLevel(GlobalLevel())
+	s := &countSampler{}
+	hooked := false
+	log := New(ioutil.Discard).Level(InfoLevel).Sample(s).
+		Hook(HookFunc(func(e *Event, level Level, msg string) { hooked = true }))
+	allocs := testing.AllocsPerRun(100, func() {
+		log.Debug().Str("foo", "bar").Msg("")
+	})
+	SetGlobalLevel(ErrorLevel)
+	log.Warn().Msg("")
+	if allocs != 0 {
+		t.Errorf("allocs = %v, want 0", allocs)
+	}
+	if s.n != 0 || hooked {
+		t.Errorf("disabled events ran the sampler %d times, hooks: %v", s.n, hooked)
+	}
+}
+
+func TestNoLevel(t *testing.T) {
+	t.Run("Log passthrough", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := New(out).Level(PanicLevel + 10)
+		log.Log().Msg("test")
+		log.WithLevel(NoLevel).Msg("test")
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"message":"test"}`+"\n"+`{"message":"test"}`+"\n"; got != want {
+			t.Errorf("invalid log output: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Disabled logger", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := New(out).Level(Disabled)
+		log.Log().Msg("test")
+		log.Panic().Msg("test")
+		if got, want := decodeIfBinaryToString(out.Bytes()), ""; got != want {
+			t.Errorf("invalid log output: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Level writer", func(t *testing.T) {
+		lw := &levelWriter{}
+		New(lw).Log().Msg("test")
+		if len(lw.ops) != 1 || lw.ops[0].l != NoLevel {
+			t.Errorf("invalid ops: %v", lw.ops)
+		}
+	})
+}
+
+func TestAudit(t *testing.T) {
+	t.Run("Bypass", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := New(out).
+			Level(PanicLevel).
+			Sample(&BasicSampler{N: 100}).
+			Filter(FieldEquals("user", "john"))
+		log = log.With().Str("user", "john").Logger()
+		log.Audit().Str("user", "john").Msg("first")
+		log.Audit().Msg("second")
+		want := `{"level":"audit","user":"john","user":"john","message":"first"}` + "\n" +
+			`{"level":"audit","user":"john","message":"second"}` + "\n"
+		if got := decodeIfBinaryToString(out.Bytes()); got != want {
+			t.Errorf("invalid log output: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Disabled logger", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		New(out).Level(Disabled).Audit().Msg("test")
+		if got, want := decodeIfBinaryToString(out.Bytes()), ""; got != want {
+			t.Errorf("invalid log output: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Rate limit", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := New(RateLimitWriter(out, 1, time.Minute, nil))
+		log.Info().Msg("")
+		log.Info().Msg("")
+		log.Audit().Msg("")
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info"}`+"\n"+`{"level":"audit"}`+"\n"; got != want {
 			t.Errorf("invalid log output: got %q, want %q", got, want)
 		}
 	})
 }
 
+func TestWithLevel(t *testing.T) {
+	t.Run("Standard", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := New(out)
+		log.WithLevel(FatalLevel).Msg("test")
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"fatal","message":"test"}`+"\n"; got != want {
+			t.Errorf("invalid log output: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Unregistered", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := New(out)
+		log.WithLevel(PanicLevel + 20).Msg("test")
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"25","message":"test"}`+"\n"; got != want {
+			t.Errorf("invalid log output: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Registered", func(t *testing.T) {
+		RegisterLevel(PanicLevel+2, "notice")
+		out := &bytes.Buffer{}
+		log := New(out).Level(PanicLevel + 2)
+		log.Info().Msg("filtered")
+		log.WithLevel(PanicLevel + 2).Msg("test")
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"notice","message":"test"}`+"\n"; got != want {
+			t.Errorf("invalid log output: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := New(out)
+		log.WithLevel(Disabled).Msg("test")
+		if got, want := decodeIfBinaryToString(out.Bytes()), ""; got != want {
+			t.Errorf("invalid log output: got %q, want %q", got, want)
+		}
+	})
+}
+
+func TestRegisterStandardLevel(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterLevel did not panic on standard level")
+		}
+	}()
+	RegisterLevel(InfoLevel, "information")
+}
+
+func TestParseLevel(t *testing.T) {
+	RegisterLevel(PanicLevel+3, "alert")
+	tests := []struct {
+		in      string
+		want    Level
+		wantErr bool
+	}{
+		{"trace", TraceLevel, false},
+		{"debug", DebugLevel, false},
+		{"INFO", InfoLevel, false},
+		{"Warn", WarnLevel, false},
+		{"error", ErrorLevel, false},
+		{"fatal", FatalLevel, false},
+		{"panic", PanicLevel, false},
+		{"disabled", Disabled, false},
+		{"alert", PanicLevel + 3, false},
+		{"12", Level(12), false},
+		{"-100", Level(-100), false},
+		{"128", 0, true},
+		{"foo", 0, true},
+		{"", NoLevel, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v, error: %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLevelText(t *testing.T) {
+	var l Level
+	if err := json.Unmarshal([]byte(`"warn"`), &l); err != nil || l != WarnLevel {
+		t.Errorf("Unmarshal() = %v, %v, want %v", l, err, WarnLevel)
+	}
+	if err := l.UnmarshalText([]byte("foo")); err == nil {
+		t.Error("UnmarshalText() did not fail on invalid level")
+	}
+	b, err := json.Marshal(struct {
+		Level Level `json:"level"`
+	}{DebugLevel})
+	if got, want := string(b), `{"level":"debug"}`; err != nil || got != want {
+		t.Errorf("Marshal() = %s, %v, want %s", got, err, want)
+	}
+}
+
 func TestSampling(t *testing.T) {
 	out := &bytes.Buffer{}
-	log := New(out).Sample(2)
+	log := New(out).Sample(&BasicSampler{N: 2})
 	log.Log().Int("i", 1).Msg("")
 	log.Log().Int("i", 2).Msg("")
 	log.Log().Int("i", 3).Msg("")
 	log.Log().Int("i", 4).Msg("")
-	if got, want := out.String(), "{\"sample\":2,\"i\":2}\n{\"sample\":2,\"i\":4}\n"; got != want {
+	if got, want := decodeIfBinaryToString(out.Bytes()), "{\"i\":1}\n{\"i\":3}\n"; got != want {
 		t.Errorf("invalid log output: got %q, want %q", got, want)
 	}
 }
@@ -223,11 +596,12 @@ func (lw *levelWriter) WriteLevel(lvl Level, p []byte) (int, error) {
 	lw.ops = append(lw.ops, struct {
 		l Level
 		p string
-	}{lvl, string(p)})
+	}{lvl, decodeIfBinaryToString(p)})
 	return len(p), nil
 }
 
 func TestLevelWriter(t *testing.T) {
+	skipIfNoDebug(t)
 	lw := &levelWriter{
 		ops: []struct {
 			l Level
@@ -235,6 +609,7 @@ func TestLevelWriter(t *testing.T) {
 		}{},
 	}
 	log := New(lw)
+	log.Trace().Msg("0")
 	log.Debug().Msg("1")
 	log.Info().Msg("2")
 	log.Warn().Msg("3")
@@ -243,6 +618,7 @@ func TestLevelWriter(t *testing.T) {
 		l Level
 		p string
 	}{
+		{TraceLevel, `{"level":"trace","message":"0"}` + "\n"},
 		{DebugLevel, `{"level":"debug","message":"1"}` + "\n"},
 		{InfoLevel, `{"level":"info","message":"2"}` + "\n"},
 		{WarnLevel, `{"level":"warn","message":"3"}` + "\n"},
@@ -264,7 +640,7 @@ func TestContextTimestamp(t *testing.T) {
 	log := New(out).With().Timestamp().Str("foo", "bar").Logger()
 	log.Log().Msg("hello world")
 
-	if got, want := out.String(), `{"time":"2001-02-03T04:05:06Z","foo":"bar","message":"hello world"}`+"\n"; got != want {
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"time":"2001-02-03T04:05:06Z","foo":"bar","message":"hello world"}`+"\n"; got != want {
 		t.Errorf("invalid log output: got %q, want %q", got, want)
 	}
 }
@@ -280,7 +656,61 @@ func TestEventTimestamp(t *testing.T) {
 	log := New(out).With().Str("foo", "bar").Logger()
 	log.Log().Timestamp().Msg("hello world")
 
-	if got, want := out.String(), `{"foo":"bar","time":"2001-02-03T04:05:06Z","message":"hello world"}`+"\n"; got != want {
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"foo":"bar","time":"2001-02-03T04:05:06Z","message":"hello world"}`+"\n"; got != want {
 		t.Errorf("invalid log output: got %q, want %q", got, want)
 	}
 }
+
+type errWriter struct {
+	error
+}
+
+func (w errWriter) Write(p []byte) (n int, err error) {
+	return 0, w.error
+}
+
+func TestErrorHandler(t *testing.T) {
+	var got []error
+	ErrorHandler = func(err error) {
+		got = append(got, err)
+	}
+	defer func() {
+		ErrorHandler = nil
+	}()
+	errGlobal, errLogger := errors.New("global"), errors.New("logger")
+	New(errWriter{errGlobal}).Log().Msg("")
+	var gotLogger []error
+	New(errWriter{errLogger}).ErrorHandler(func(err error) {
+		gotLogger = append(gotLogger, err)
+	}).Log().Msg("")
+	if want := []error{errGlobal}; !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid global errors: got %v, want %v", got, want)
+	}
+	if want := []error{errLogger}; !reflect.DeepEqual(gotLogger, want) {
+		t.Errorf("invalid logger errors: got %v, want %v", gotLogger, want)
+	}
+}
+
+type fieldsError struct {
+	code int
+}
+
+func (e fieldsError) Error() string {
+	return "failed"
+}
+
+func (e fieldsError) ErrorFields(d *Event) {
+	d.Int("code", e.code)
+}
+
+func TestErrChain(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out)
+	err := fmt.Errorf("query: %w", fieldsError{code: 42})
+	log.Log().ErrChain(err).Msg("")
+	log.Log().ErrChain(nil).Msg("")
+	want := `{"error":"query: failed","error_chain":[{"type":"*fmt.wrapError","message":"query: failed"},{"type":"zerolog.fieldsError","message":"failed","code":42}]}` + "\n" + `{}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,139 @@
+package zerolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"sync"
+)
+
+// Build information of the program, set with -ldflags. They take precedence
+// over the information embedded by the go tool used by Context.BuildInfo:
+//
+//     go build -ldflags "-X github.com/rs/zerolog.BuildVersion=v1.2.3 -X github.com/rs/zerolog.BuildGitSHA=$(git rev-parse HEAD)"
+var (
+	BuildVersion string
+	BuildGitSHA  string
+	BuildTime    string
+)
+
+// processMetadata holds the metadata of the process, computed once.
+type processMetadata struct {
+	hostname    string
+	pid         int
+	executable  string
+	containerID string
+	version     string
+	gitSHA      string
+	buildTime   string
+}
+
+var (
+	metadataOnce sync.Once
+	metadata     processMetadata
+)
+
+func getMetadata() processMetadata {
+	metadataOnce.Do(func() {
+		metadata.hostname, _ = os.Hostname()
+		metadata.pid = os.Getpid()
+		if exe, err := os.Executable(); err == nil {
+			metadata.executable = filepath.Base(exe)
+		} else if len(os.Args) > 0 {
+			metadata.executable = filepath.Base(os.Args[0])
+		}
+		metadata.containerID = containerID()
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			if v := bi.Main.Version; v != "(devel)" {
+				metadata.version = v
+			}
+			for _, s := range bi.Settings {
+				switch s.Key {
+				case "vcs.revision":
+					metadata.gitSHA = s.Value
+				case "vcs.time":
+					metadata.buildTime = s.Value
+				}
+			}
+		}
+	})
+	return metadata
+}
+
+var containerIDRe = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// containerID returns the id of the container running the process, as found
+// in the cgroups or the mounts of the process, or an empty string.
+func containerID() string {
+	for _, f := range []string{"/proc/self/cgroup", "/proc/self/mountinfo"} {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		if id := containerIDRe.Find(b); id != nil {
+			return string(id)
+		}
+	}
+	return ""
+}
+
+// Hostname adds the hostname of the machine to the logger context using the
+// zerolog.HostnameFieldName field name.
+func (c Context) Hostname() Context {
+	return c.Str(HostnameFieldName, getMetadata().hostname)
+}
+
+// Pid adds the process id to the logger context using the
+// zerolog.PidFieldName field name.
+func (c Context) Pid() Context {
+	return c.Int(PidFieldName, getMetadata().pid)
+}
+
+// Executable adds the name of the executable of the process to the logger
+// context using the zerolog.ExecutableFieldName field name.
+func (c Context) Executable() Context {
+	return c.Str(ExecutableFieldName, getMetadata().executable)
+}
+
+// ContainerID adds the id of the container running the process to the logger
+// context using the zerolog.ContainerIDFieldName field name. No field is
+// added if the process does not run in a container.
+func (c Context) ContainerID() Context {
+	if id := getMetadata().containerID; id != "" {
+		return c.Str(ContainerIDFieldName, id)
+	}
+	return c
+}
+
+// Metadata adds the hostname, process id, executable name and container id,
+// if any, to the logger context. The metadata is computed once for the
+// process.
+//
+//     log := zerolog.New(os.Stderr).With().Metadata().Logger()
+func (c Context) Metadata() Context {
+	return c.Hostname().Pid().Executable().ContainerID()
+}
+
+// BuildInfo adds the version, git commit and build time of the program to
+// the logger context using the zerolog.VersionFieldName,
+// zerolog.GitSHAFieldName and zerolog.BuildTimeFieldName field names, so
+// every event is attributable to a build. They are read from BuildVersion,
+// BuildGitSHA and BuildTime if set with -ldflags or from the build
+// information embedded by the go tool. Unknown information is not added.
+func (c Context) BuildInfo() Context {
+	m := getMetadata()
+	for _, f := range []struct{ key, ldflag, embedded string }{
+		{VersionFieldName, BuildVersion, m.version},
+		{GitSHAFieldName, BuildGitSHA, m.gitSHA},
+		{BuildTimeFieldName, BuildTime, m.buildTime},
+	} {
+		if f.ldflag != "" {
+			c = c.Str(f.key, f.ldflag)
+		} else if f.embedded != "" {
+			c = c.Str(f.key, f.embedded)
+		}
+	}
+	return c
+}
@@ -0,0 +1,44 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestMetadata(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().Metadata().Logger()
+	log.Log().Msg("")
+	var got map[string]interface{}
+	if err := json.Unmarshal(decodeIfBinaryToBytes(out.Bytes()), &got); err != nil {
+		t.Fatal(err)
+	}
+	hostname, _ := os.Hostname()
+	if got[HostnameFieldName] != hostname {
+		t.Errorf("invalid hostname: %v", got[HostnameFieldName])
+	}
+	if pid, _ := got[PidFieldName].(float64); int(pid) != os.Getpid() {
+		t.Errorf("invalid pid: %v", got[PidFieldName])
+	}
+	if exe, _ := got[ExecutableFieldName].(string); exe == "" {
+		t.Errorf("invalid executable: %v", got[ExecutableFieldName])
+	}
+	if id, found := got[ContainerIDFieldName]; found && len(id.(string)) != 64 {
+		t.Errorf("invalid container id: %v", id)
+	}
+}
+
+func TestBuildInfo(t *testing.T) {
+	defer func(v, sha, ts string) {
+		BuildVersion, BuildGitSHA, BuildTime = v, sha, ts
+	}(BuildVersion, BuildGitSHA, BuildTime)
+	BuildVersion, BuildGitSHA, BuildTime = "v1.2.3", "0123abc", "2001-02-03T04:05:06Z"
+	out := &bytes.Buffer{}
+	log := New(out).With().BuildInfo().Logger()
+	log.Log().Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"version":"v1.2.3","git_sha":"0123abc","build_time":"2001-02-03T04:05:06Z"}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,281 @@
+package zerolog
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// MmapWriter is a file writer copying the events to a memory mapping of the
+// file instead of calling write(2), for local logging where even the latency
+// of a buffered write matters. The file is preallocated and mapped by chunks
+// and the kernel writes the mapped pages back to the disk asynchronously.
+//
+// The events are safe once written if the process crashes, as they are in
+// the page cache, but not if the system crashes: only Sync, Close and the
+// events written with FatalLevel or PanicLevel wait for the pages to be
+// written to the disk. The file must not be truncated by another process
+// while it is mapped.
+//
+// While the writer is open, the end of the events is stored after each write
+// in a side file, named after the file with a ".end" suffix and mapped too.
+// Close truncates the file to the size of the events and removes the side
+// file. If the process stops without calling Close, the file ends with the
+// zeroed preallocated bytes of the last chunk: NewMmapWriter then reads the
+// end of the events from the side file and appends the new events after it.
+type MmapWriter struct {
+	mu    sync.Mutex
+	f     *os.File
+	chunk int
+	// data maps the file from off, and pos is the end of the events in data.
+	data []byte
+	off  int64
+	pos  int
+	// endf is the side file and end its mapping, holding off+pos.
+	endf *os.File
+	end  []byte
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewMmapWriter opens or creates the file at path, appending the events to
+// it, with the file mapped by chunks of chunkSize bytes, rounded up to the
+// page size. If syncInterval is not zero, the dirty pages are scheduled to be
+// written to the disk every syncInterval, without waiting for the writes.
+func NewMmapWriter(path string, chunkSize int, syncInterval time.Duration) (*MmapWriter, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	endf, err := os.OpenFile(path+".end", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	page := os.Getpagesize()
+	w := &MmapWriter{
+		f:     f,
+		chunk: (chunkSize + page - 1) / page * page,
+		endf:  endf,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if w.chunk == 0 {
+		w.chunk = page
+	}
+	end, err := w.mapEnd()
+	if err == nil {
+		err = w.remap(end, 0)
+	}
+	if err != nil {
+		if w.end != nil {
+			syscall.Munmap(w.end)
+		}
+		endf.Close()
+		f.Close()
+		return nil, err
+	}
+	w.storeEnd()
+	if syncInterval > 0 {
+		go w.run(syncInterval)
+	} else {
+		close(w.done)
+	}
+	return w, nil
+}
+
+// mapEnd maps the side file and returns the end of the events it holds, or
+// the size of the file if the side file is new, as the file was then closed
+// cleanly or not written by a MmapWriter.
+func (w *MmapWriter) mapEnd() (int64, error) {
+	fi, err := w.f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	efi, err := w.endf.Stat()
+	if err != nil {
+		return 0, err
+	}
+	end := fi.Size()
+	if efi.Size() < 8 {
+		// The end is written before the side file is mapped, so it is
+		// valid even if the process stops right after.
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], uint64(end))
+		if _, err := w.endf.WriteAt(b[:], 0); err != nil {
+			return 0, err
+		}
+	}
+	w.end, err = syscall.Mmap(int(w.endf.Fd()), 0, 8, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return 0, err
+	}
+	if e := int64(binary.LittleEndian.Uint64(w.end)); e < end {
+		end = e
+	}
+	return end, nil
+}
+
+// storeEnd stores the end of the events in the side file. It must be called
+// with w.mu held.
+func (w *MmapWriter) storeEnd() {
+	binary.LittleEndian.PutUint64(w.end, uint64(w.off)+uint64(w.pos))
+}
+
+// remap maps the chunk of the file starting at the page of offset end, large
+// enough to hold n more bytes. It must be called with w.mu held.
+func (w *MmapWriter) remap(end int64, n int) error {
+	if w.data != nil {
+		if err := syscall.Munmap(w.data); err != nil {
+			return err
+		}
+		w.data = nil
+	}
+	page := int64(os.Getpagesize())
+	off := end / page * page
+	pos := int(end - off)
+	size := w.chunk
+	for size < pos+n {
+		size += w.chunk
+	}
+	if err := syscall.Fallocate(int(w.f.Fd()), 0, off, int64(size)); err != nil {
+		// Not all the file systems support fallocate.
+		if err := w.f.Truncate(off + int64(size)); err != nil {
+			return err
+		}
+	}
+	data, err := syscall.Mmap(int(w.f.Fd()), off, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	w.data, w.off, w.pos = data, off, pos
+	return nil
+}
+
+// Write implements the io.Writer interface.
+func (w *MmapWriter) Write(p []byte) (n int, err error) {
+	return w.write(p, false)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *MmapWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	return w.write(p, l == FatalLevel || l == PanicLevel)
+}
+
+func (w *MmapWriter) write(p []byte, sync bool) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.data == nil {
+		return 0, os.ErrClosed
+	}
+	if w.pos+len(p) > len(w.data) {
+		if err = w.remap(w.off+int64(w.pos), len(p)); err != nil {
+			return 0, err
+		}
+	}
+	w.pos += copy(w.data[w.pos:], p)
+	w.storeEnd()
+	if sync {
+		if err = w.msync(syscall.MS_SYNC); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// msync flushes the events and the side file to the disk. It must be called
+// with w.mu held.
+func (w *MmapWriter) msync(flags int) error {
+	if err := msync(w.data[:w.pos], flags); err != nil {
+		return err
+	}
+	return msync(w.end, flags)
+}
+
+// Sync writes the events to the disk and waits for the writes.
+func (w *MmapWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.data == nil {
+		return os.ErrClosed
+	}
+	return w.msync(syscall.MS_SYNC)
+}
+
+// Close writes the events to the disk, unmaps the file, truncates it to the
+// size of the events and closes it. The side file is removed.
+func (w *MmapWriter) Close() error {
+	w.once.Do(func() { close(w.stop) })
+	<-w.done
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.data == nil {
+		return os.ErrClosed
+	}
+	err := msync(w.data[:w.pos], syscall.MS_SYNC)
+	if uerr := syscall.Munmap(w.data); err == nil {
+		err = uerr
+	}
+	w.data = nil
+	if terr := w.f.Truncate(w.off + int64(w.pos)); err == nil {
+		err = terr
+	}
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	// The side file is only removed once the file is truncated, so a
+	// failed Close can still be recovered from.
+	if err == nil {
+		err = os.Remove(w.endf.Name())
+	}
+	if uerr := syscall.Munmap(w.end); err == nil {
+		err = uerr
+	}
+	if cerr := w.endf.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Shutdown is like Close. It can be registered with RegisterShutdown.
+func (w *MmapWriter) Shutdown(ctx context.Context) error {
+	return w.Close()
+}
+
+func (w *MmapWriter) run(interval time.Duration) {
+	defer close(w.done)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			w.mu.Lock()
+			if w.pos > 0 {
+				if err := w.msync(syscall.MS_ASYNC); err != nil {
+					handleError(nil, err)
+				}
+			}
+			w.mu.Unlock()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// msync flushes the pages of the mapping b, starting at a page boundary, to
+// the disk.
+func msync(b []byte, flags int) error {
+	if len(b) == 0 {
+		return nil
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC, uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)), uintptr(flags))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
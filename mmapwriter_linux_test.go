@@ -0,0 +1,104 @@
+package zerolog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMmapWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	w, err := NewMmapWriter(path, 4096, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := New(w)
+	var want strings.Builder
+	for i := 0; i < 300; i++ {
+		log.Log().Int("n", i).Msg("")
+		want.WriteString(`{"n":` + strconv.Itoa(i) + "}\n")
+	}
+	// Larger than a chunk.
+	big := strings.Repeat("x", 10000)
+	log.Log().Str("big", big).Msg("")
+	want.WriteString(`{"big":"` + big + `"}` + "\n")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("closed\n")); err != os.ErrClosed {
+		t.Errorf("Write after Close returned %v, want os.ErrClosed", err)
+	}
+
+	w, err = NewMmapWriter(path, 4096, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	New(w).WithLevel(FatalLevel).Msg("")
+	want.WriteString(`{"level":"fatal"}` + "\n")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decodeIfBinaryToString(got) != want.String() {
+		t.Errorf("invalid file content:\ngot:  %q\nwant: %q", got, want.String())
+	}
+}
+
+func TestMmapWriterPreallocated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	// The file of a process stopped without closing the writer, with events
+	// ending with zero bytes as BSON documents do.
+	w, err := NewMmapWriter(path, 4096, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("1\x00"))
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() != 4096 {
+		t.Fatalf("file size = %d, want 4096 preallocated bytes", fi.Size())
+	}
+
+	w, err = NewMmapWriter(path, 4096, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("2\x00"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1\x002\x00"; string(got) != want {
+		t.Errorf("invalid file content:\ngot:  %q\nwant: %q", got, want)
+	}
+	if _, err := os.Stat(path + ".end"); !os.IsNotExist(err) {
+		t.Errorf("side file not removed by Close: %v", err)
+	}
+}
+
+func BenchmarkMmapWriter(b *testing.B) {
+	w, err := NewMmapWriter(filepath.Join(b.TempDir(), "log"), 1<<20, time.Second)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer w.Close()
+	logger := New(w)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info().Msg(fakeMessage)
+		}
+	})
+}
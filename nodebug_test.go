@@ -0,0 +1,20 @@
+// +build zerolog_nodebug
+
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNoDebug(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Trace().Msg("trace")
+	log.Debug().Msg("debug")
+	log.WithLevel(TraceLevel - 1).Msg("custom")
+	log.Info().Msg("info")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"info"}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,5 @@
+// +build !race
+
+package zerolog
+
+const raceEnabled = false
@@ -0,0 +1,94 @@
+package zerolog
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// onceKeys holds the *onceEntry of each key passed to Logger.Once.
+var onceKeys sync.Map
+
+// onceStores counts the entries stored in onceKeys, to sweep the expired
+// ones every onceSweepEvery stores.
+var onceStores uint32
+
+const onceSweepEvery = 1024
+
+// onceEvicted is stored in the until field of an entry removed from
+// onceKeys, so takes racing with its removal retry with a new entry.
+const onceEvicted = -1
+
+// onceEntry stores the time until which the events of a key are dropped as
+// UNIX nanoseconds: 0 if the next event passes, math.MaxInt64 if all events
+// are dropped.
+type onceEntry struct {
+	until int64
+}
+
+type onceKey struct {
+	key string
+	ttl time.Duration
+}
+
+// taken returns true if the events with the key are currently dropped. It
+// does not register the key, so disabled events can be dropped early.
+func (o onceKey) taken() bool {
+	v, ok := onceKeys.Load(o.key)
+	if !ok {
+		return false
+	}
+	until := atomic.LoadInt64(&v.(*onceEntry).until)
+	return until == math.MaxInt64 || (until > 0 && time.Now().UnixNano() < until)
+}
+
+// take returns true if an event with the key can pass, marking the key as
+// consumed.
+func (o onceKey) take() bool {
+	for {
+		v, ok := onceKeys.Load(o.key)
+		if !ok {
+			var loaded bool
+			v, loaded = onceKeys.LoadOrStore(o.key, &onceEntry{})
+			if !loaded && atomic.AddUint32(&onceStores, 1)%onceSweepEvery == 0 {
+				sweepOnceKeys()
+			}
+		}
+		e := v.(*onceEntry)
+		until := atomic.LoadInt64(&e.until)
+		if until == onceEvicted {
+			// The entry is being removed by sweepOnceKeys.
+			runtime.Gosched()
+			continue
+		}
+		now := time.Now().UnixNano()
+		if until == math.MaxInt64 || (until != 0 && now < until) {
+			return false
+		}
+		next := int64(math.MaxInt64)
+		if o.ttl > 0 {
+			next = now + int64(o.ttl)
+		}
+		if atomic.CompareAndSwapInt64(&e.until, until, next) {
+			return true
+		}
+	}
+}
+
+// sweepOnceKeys removes the entries of the OnceTTL keys whose ttl elapsed.
+// Such keys behave as if they were never used, so removing them is
+// transparent.
+func sweepOnceKeys() {
+	now := time.Now().UnixNano()
+	onceKeys.Range(func(k, v interface{}) bool {
+		e := v.(*onceEntry)
+		until := atomic.LoadInt64(&e.until)
+		if until > 0 && until != math.MaxInt64 && now >= until &&
+			atomic.CompareAndSwapInt64(&e.until, until, onceEvicted) {
+			onceKeys.Delete(k)
+		}
+		return true
+	})
+}
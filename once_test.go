@@ -0,0 +1,68 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestOnce(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Level(InfoLevel)
+	log.Once("test-once").Debug().Msg("filtered")
+	for i := 0; i < 3; i++ {
+		log.Once("test-once").Warn().Int("i", i).Msg("deprecated")
+	}
+	log.With().Str("foo", "bar").Logger().Once("test-once").Warn().Msg("dropped")
+	log.Once("test-once-2").Warn().Msg("other")
+	want := `{"level":"warn","i":0,"message":"deprecated"}` + "\n" + `{"level":"warn","message":"other"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestOnceTTL(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).OnceTTL("test-once-ttl", 50*time.Millisecond)
+	log.Log().Msg("1")
+	log.Log().Msg("2")
+	time.Sleep(60 * time.Millisecond)
+	log.Log().Msg("3")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"message":"1"}`+"\n"+`{"message":"3"}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+}
+
+func TestOnceDropped(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Once("test-once-dropped")
+	log.Sample(RandomSampler(0)).Warn().Msg("sampled")
+	log.Filter(func(key, val string) bool { return val == "filtered" }).Warn().Msg("filtered")
+	fs := &FirstNSampler{N: 1}
+	fs.SampleMessage(WarnLevel, "first")
+	log.Sample(fs).Warn().Msg("first")
+	log.Warn().Msg("kept")
+	log.Warn().Msg("dropped")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"warn","message":"kept"}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+}
+
+func TestOnceSweep(t *testing.T) {
+	expired := onceKey{key: "test-once-sweep", ttl: time.Nanosecond}
+	kept := onceKey{key: "test-once-sweep-kept"}
+	if !expired.take() || !kept.take() {
+		t.Fatal("keys already taken")
+	}
+	time.Sleep(time.Millisecond)
+	sweepOnceKeys()
+	if _, ok := onceKeys.Load(expired.key); ok {
+		t.Error("expired key not removed")
+	}
+	if _, ok := onceKeys.Load(kept.key); !ok {
+		t.Error("key without ttl removed")
+	}
+	if !expired.take() || kept.take() {
+		t.Error("invalid take after sweep")
+	}
+}
@@ -0,0 +1,68 @@
+package zerolog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// EventBufferSize is the initial capacity, in bytes, of the buffers of
+	// the events allocated by the pool. Setting it to the size of the usual
+	// events avoids growing the buffers of new events.
+	EventBufferSize = 500
+
+	// EventBufferMaxSize is the capacity, in bytes, above which the buffer of
+	// an event is discarded instead of being returned to the pool, so a
+	// single huge event does not pin its memory for the life of the program.
+	// If zero, all the buffers are returned to the pool.
+	EventBufferMaxSize = 1 << 16
+)
+
+var eventPool = &sync.Pool{
+	New: func() interface{} {
+		atomic.AddUint64(&poolStats.allocated, 1)
+		return &Event{
+			buf: make([]byte, 0, EventBufferSize),
+		}
+	},
+}
+
+var poolStats struct {
+	allocated uint64
+	discarded uint64
+}
+
+// PoolStats holds the statistics of the event pool.
+type PoolStats struct {
+	// Allocated is the number of events allocated because the pool was
+	// empty.
+	Allocated uint64
+
+	// Discarded is the number of events not returned to the pool because
+	// their buffer grew above EventBufferMaxSize.
+	Discarded uint64
+}
+
+// EventPoolStats returns the statistics of the event pool since the start of
+// the program. A steadily growing Allocated count means the events are
+// garbage collected faster than they are reused, and a high Discarded count
+// that EventBufferMaxSize is too small for the usual events.
+func EventPoolStats() PoolStats {
+	return PoolStats{
+		Allocated: atomic.LoadUint64(&poolStats.allocated),
+		Discarded: atomic.LoadUint64(&poolStats.discarded),
+	}
+}
+
+// putEvent returns e to the pool, unless its buffer grew above
+// EventBufferMaxSize or it is the event of an Emitter.
+func putEvent(e *Event) {
+	if e.pinned {
+		return
+	}
+	if EventBufferMaxSize > 0 && (cap(e.buf) > EventBufferMaxSize || cap(e.stagedBytes) > EventBufferMaxSize) {
+		atomic.AddUint64(&poolStats.discarded, 1)
+		return
+	}
+	eventPool.Put(e)
+}
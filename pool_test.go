@@ -0,0 +1,55 @@
+package zerolog
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestEventBufferMaxSize(t *testing.T) {
+	defer func(max int) { EventBufferMaxSize = max }(EventBufferMaxSize)
+	EventBufferMaxSize = 1024
+	log := New(ioutil.Discard)
+
+	before := EventPoolStats()
+	log.Info().Str("big", strings.Repeat("x", 2048)).Msg("")
+	if got := EventPoolStats().Discarded - before.Discarded; got != 1 {
+		t.Errorf("Discarded = %d, want 1", got)
+	}
+
+	before = EventPoolStats()
+	log.Info().Str("small", "x").Msg("")
+	if got := EventPoolStats().Discarded - before.Discarded; got != 0 {
+		t.Errorf("Discarded = %d, want 0", got)
+	}
+
+	EventBufferMaxSize = 0
+	before = EventPoolStats()
+	log.Info().Str("big", strings.Repeat("x", 2048)).Msg("")
+	if got := EventPoolStats().Discarded - before.Discarded; got != 0 {
+		t.Errorf("Discarded = %d, want 0 without a maximum size", got)
+	}
+}
+
+func TestEventBufferSize(t *testing.T) {
+	defer func(size int) { EventBufferSize = size }(EventBufferSize)
+	EventBufferSize = 4096
+	before := EventPoolStats()
+	e := eventPool.New().(*Event)
+	if cap(e.buf) != 4096 {
+		t.Errorf("cap(buf) = %d, want 4096", cap(e.buf))
+	}
+	if got := EventPoolStats().Allocated - before.Allocated; got != 1 {
+		t.Errorf("Allocated = %d, want 1", got)
+	}
+}
+
+func TestWithEventSizeHint(t *testing.T) {
+	log := New(ioutil.Discard)
+	if e := log.WithEventSizeHint(8192).Info(); cap(e.buf) < 8192 {
+		t.Errorf("cap(buf) = %d, want at least 8192", cap(e.buf))
+	}
+	if e := log.Info(); cap(e.buf) < EventBufferSize {
+		t.Errorf("cap(buf) = %d, want at least %d without hint", cap(e.buf), EventBufferSize)
+	}
+}
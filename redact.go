@@ -0,0 +1,259 @@
+package zerolog
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// fieldRewriter is called with the key of each field of an event and, for
+// string fields, with its value. It returns the value replacing the field
+// value and true, or false to keep the field unchanged. key and val must not
+// be retained.
+type fieldRewriter func(key, val []byte, isString bool) (string, bool)
+
+// rewriteFields rewrites the fields of e with f.
+func (e *Event) rewriteFields(f fieldRewriter) {
+	if buf := rewriteFields(e.buf, f, true); buf != nil {
+		e.buf = buf
+	}
+}
+
+// RedactHook is a hook replacing the values of sensitive fields with
+// RedactedValue before the event is written, including the fields of
+// dictionaries and objects added with Dict or Interface.
+//
+// As hooks run in order, fields added by hooks registered after the
+// RedactHook are not redacted.
+type RedactHook struct {
+	keys  map[string]bool
+	globs []string
+}
+
+// NewRedactHook returns a RedactHook redacting the fields whose key matches
+// one of patterns. Keys are matched case-insensitively and patterns can be
+// globs as supported by path.Match, like "*token*".
+func NewRedactHook(patterns ...string) RedactHook {
+	h := RedactHook{keys: map[string]bool{}}
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if strings.ContainsAny(p, `*?[\`) {
+			h.globs = append(h.globs, p)
+		} else {
+			h.keys[p] = true
+		}
+	}
+	return h
+}
+
+// Run implements the Hook interface.
+func (h RedactHook) Run(e *Event, level Level, msg string) {
+	e.rewriteFields(h.redact)
+}
+
+func (h RedactHook) redact(key, val []byte, isString bool) (string, bool) {
+	if h.match(key) {
+		return RedactedValue, true
+	}
+	return "", false
+}
+
+// match returns true if the field key is redacted by h.
+func (h RedactHook) match(key []byte) bool {
+	for _, c := range key {
+		if c >= 'A' && c <= 'Z' {
+			key = bytes.ToLower(key)
+			break
+		}
+	}
+	if h.keys[string(key)] {
+		return true
+	}
+	if len(h.globs) == 0 {
+		return false
+	}
+	k := string(key)
+	for _, g := range h.globs {
+		if ok, _ := path.Match(g, k); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskPattern masks the parts of string field values matching Regexp with
+// Mask.
+type MaskPattern struct {
+	Regexp *regexp.Regexp
+	Mask   string
+
+	// Hint, if not empty, is a string that all matches contain. Values not
+	// containing it are skipped without running the regular expression.
+	Hint string
+
+	// Prefilter, if not nil, returns false for the values that cannot
+	// contain a match, which are skipped without running the regular
+	// expression, like with Hint.
+	Prefilter func(val []byte) bool
+
+	// Validate, if not nil, returns true if a match must be masked. Other
+	// matches are left as is.
+	Validate func(match []byte) bool
+}
+
+// Predefined patterns for MaskHook.
+var (
+	// MaskEmails masks email addresses.
+	MaskEmails = MaskPattern{
+		Regexp: regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`),
+		Mask:   "[EMAIL]",
+		Hint:   "@",
+	}
+
+	// MaskCreditCards masks sequences of 13 to 19 digits, optionally
+	// separated by spaces or dashes, as found in credit card numbers. Only
+	// the sequences passing the Luhn checksum of card numbers are masked,
+	// so most order ids, timestamps or phone numbers are left as is.
+	MaskCreditCards = MaskPattern{
+		Regexp:    regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		Mask:      "[CARD]",
+		Prefilter: func(val []byte) bool { return hasDigits(val, 13) },
+		Validate:  luhnValid,
+	}
+
+	// MaskBearerTokens masks the tokens of bearer authorization headers.
+	MaskBearerTokens = MaskPattern{
+		Regexp: regexp.MustCompile(`(?i)bearer\s+[a-z0-9\-._~+/]+=*`),
+		Mask:   "Bearer [TOKEN]",
+		Hint:   "earer",
+	}
+)
+
+// MaskHook is a hook masking the parts of string field values matching
+// patterns, such as email addresses, before the event is written. Unlike
+// RedactHook, it scans the values of all fields, including the fields of
+// dictionaries and objects, whatever their key. The message is not masked.
+type MaskHook struct {
+	patterns []MaskPattern
+}
+
+// NewMaskHook returns a MaskHook masking patterns, or MaskEmails,
+// MaskCreditCards and MaskBearerTokens if none is given.
+func NewMaskHook(patterns ...MaskPattern) MaskHook {
+	if len(patterns) == 0 {
+		patterns = []MaskPattern{MaskEmails, MaskCreditCards, MaskBearerTokens}
+	}
+	return MaskHook{patterns: patterns}
+}
+
+// Run implements the Hook interface.
+func (h MaskHook) Run(e *Event, level Level, msg string) {
+	e.rewriteFields(h.mask)
+}
+
+func (h MaskHook) mask(key, val []byte, isString bool) (string, bool) {
+	if !isString {
+		return "", false
+	}
+	masked := false
+	for _, p := range h.patterns {
+		if p.Hint != "" && !bytes.Contains(val, []byte(p.Hint)) {
+			continue
+		}
+		if p.Prefilter != nil && !p.Prefilter(val) {
+			continue
+		}
+		if p.Validate != nil {
+			replaced := false
+			res := p.Regexp.ReplaceAllFunc(val, func(m []byte) []byte {
+				if !p.Validate(m) {
+					return m
+				}
+				replaced = true
+				return []byte(p.Mask)
+			})
+			if replaced {
+				val, masked = res, true
+			}
+			continue
+		}
+		if p.Regexp.Match(val) {
+			val = p.Regexp.ReplaceAllLiteral(val, []byte(p.Mask))
+			masked = true
+		}
+	}
+	if !masked {
+		return "", false
+	}
+	return string(val), true
+}
+
+// hasDigits returns true if b holds at least n digits.
+func hasDigits(b []byte, n int) bool {
+	for _, c := range b {
+		if c >= '0' && c <= '9' {
+			if n--; n == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// luhnValid returns true if the digits of b, ignoring other characters, pass
+// the Luhn checksum of credit card numbers.
+func luhnValid(b []byte) bool {
+	sum, double := 0, false
+	for i := len(b) - 1; i >= 0; i-- {
+		c := b[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// TruncateHook is a hook truncating the string values larger than a maximum
+// size, including the fields of dictionaries and objects, so an accidentally
+// logged payload does not flood the log pipeline. A "...(truncated, N bytes)"
+// marker giving the number of bytes removed is appended to truncated values.
+//
+// With the zerolog_bson build tag, the string elements of the BSON documents
+// are truncated.
+type TruncateHook struct {
+	max int
+}
+
+// NewTruncateHook returns a TruncateHook truncating the string values larger
+// than max bytes.
+func NewTruncateHook(max int) TruncateHook {
+	return TruncateHook{max: max}
+}
+
+// Run implements the Hook interface.
+func (h TruncateHook) Run(e *Event, level Level, msg string) {
+	e.rewriteFields(h.truncate)
+}
+
+func (h TruncateHook) truncate(key, val []byte, isString bool) (string, bool) {
+	if !isString || len(val) <= h.max {
+		return "", false
+	}
+	n := h.max
+	// Do not cut a multi-byte character.
+	for n > 0 && !utf8.RuneStart(val[n]) {
+		n--
+	}
+	return string(val[:n]) + "...(truncated, " + strconv.Itoa(len(val)-n) + " bytes)", true
+}
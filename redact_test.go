@@ -0,0 +1,112 @@
+package zerolog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"regexp"
+	"testing"
+)
+
+func TestRedactHook(t *testing.T) {
+	h := NewRedactHook("password", "Authorization", "*token*")
+	tests := []struct {
+		name string
+		f    func(log Logger)
+		want string
+	}{
+		{"Str", func(log Logger) {
+			log.Log().Str("user", "john").Str("PASSWORD", "secret").Msg("login")
+		}, `{"user":"john","PASSWORD":"[REDACTED]","message":"login"}`},
+		{"NonString", func(log Logger) {
+			log.Log().Int("access_token", 42).Bool("ok", true).Msg("")
+		}, `{"access_token":"[REDACTED]","ok":true}`},
+		{"First", func(log Logger) {
+			log.Log().Str("password", "secret").Str("user", "john").Msg("")
+		}, `{"password":"[REDACTED]","user":"john"}`},
+		{"Context", func(log Logger) {
+			log.With().Str("authorization", "Bearer x").Logger().Info().Msg("")
+		}, `{"level":"info","authorization":"[REDACTED]"}`},
+		{"Dict", func(log Logger) {
+			log.Log().Dict("req", Dict().Str("path", "/").Dict("headers", Dict().Str("Authorization", "Bearer x"))).Msg("")
+		}, `{"req":{"path":"/","headers":{"Authorization":"[REDACTED]"}}}`},
+		{"Interface", func(log Logger) {
+			log.Log().Interface("obj", map[string]interface{}{"a": "b,\"}", "refresh_token": []int{1}}).Msg("")
+		}, `{"obj":{"a":"b,\"}","refresh_token":"[REDACTED]"}}`},
+		{"Object", func(log Logger) {
+			log.Log().Dict("password", Dict().Str("a", "b")).Msg("")
+		}, `{"password":"[REDACTED]"}`},
+		{"Unchanged", func(log Logger) {
+			log.Log().Str("user", "john").Msg("")
+		}, `{"user":"john"}`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			tt.f(New(out).Hook(h))
+			if got, want := decodeIfBinaryToString(out.Bytes()), tt.want+"\n"; got != want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMaskHook(t *testing.T) {
+	tests := []struct {
+		name string
+		h    MaskHook
+		f    func(log Logger)
+		want string
+	}{
+		{"Email", NewMaskHook(), func(log Logger) {
+			log.Log().Str("to", "john@example.com, jane@example.org").Int("n", 2).Msg("")
+		}, `{"to":"[EMAIL], [EMAIL]","n":2}`},
+		{"Card", NewMaskHook(), func(log Logger) {
+			log.Log().Str("card", "4111 1111 1111 1111").Str("order", "12345").Msg("")
+		}, `{"card":"[CARD]","order":"12345"}`},
+		{"Not a card", NewMaskHook(), func(log Logger) {
+			log.Log().Str("ts", "1494567715123456789").Str("order", "order 4111-1111-1111-1112").Msg("")
+		}, `{"ts":"1494567715123456789","order":"order 4111-1111-1111-1112"}`},
+		{"Bearer", NewMaskHook(), func(log Logger) {
+			log.Log().Dict("headers", Dict().Str("auth", "Bearer abc.def-ghi")).Msg("")
+		}, `{"headers":{"auth":"Bearer [TOKEN]"}}`},
+		{"Custom", NewMaskHook(MaskPattern{Regexp: regexp.MustCompile(`\d{3}-\d{2}-\d{4}`), Mask: "[SSN]"}), func(log Logger) {
+			log.Log().Str("note", "ssn 078-05-1120, mail a@b.co").Msg("")
+		}, `{"note":"ssn [SSN], mail a@b.co"}`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			tt.f(New(out).Hook(tt.h))
+			if got, want := decodeIfBinaryToString(out.Bytes()), tt.want+"\n"; got != want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkMaskHook(b *testing.B) {
+	log := New(ioutil.Discard).Hook(NewMaskHook())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Info().Str("user", "john").Str("path", "/api/v1/users").Int("status", 200).Msg("request")
+	}
+}
+
+func TestTruncateHook(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Hook(NewTruncateHook(5))
+	log.Log().
+		Str("short", "abcde").
+		Str("long", "abcdefghij").
+		Str("utf8", "abcdé").
+		Int("n", 1234567).
+		Dict("d", Dict().Str("long", "abcdef")).
+		Msg("message not truncated")
+	want := `{"short":"abcde","long":"abcde...(truncated, 5 bytes)","utf8":"abcd...(truncated, 2 bytes)","n":1234567,"d":{"long":"abcde...(truncated, 1 bytes)"},"message":"message not truncated"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
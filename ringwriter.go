@@ -0,0 +1,256 @@
+package zerolog
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// RingWriter is an asynchronous writer designed for many goroutines logging
+// concurrently. Events are pushed, without locking, to one of several
+// bounded ring buffers, the shards, and written to the wrapped writer by a
+// single consumer goroutine. Writing never blocks: events are dropped when
+// their shard is full, and counted per shard.
+//
+// Spreading the writes over shards avoids the contention of a single queue,
+// at the cost of ordering: events written to different shards, even by the
+// same goroutine, may be written out of order. Events written with
+// FatalLevel, PanicLevel or AuditLevel are written immediately, after the
+// queued events, as the process may exit right after them.
+//
+// Errors of the wrapped writer are reported to ErrorHandler. Close, or
+// Shutdown registered with RegisterShutdown, must be called to write the
+// queued events and stop the consumer goroutine.
+type RingWriter struct {
+	w      io.Writer
+	// mu serializes the drains of the consumer goroutine and of the
+	// immediate writes, so the shards keep a single consumer at a time.
+	mu     sync.Mutex
+	shards []ringShard
+	next   uint32
+	notify chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+	closed uint32
+}
+
+// ringShard is a bounded multi-producer single-consumer queue. Each slot
+// carries a sequence number telling whether it is free for the producer
+// claiming position pos (seq == pos) or holds the event of position pos
+// for the consumer (seq == pos+1).
+type ringShard struct {
+	// head is the next position claimed by the producers.
+	head uint64
+	_    [56]byte // keep head and tail on separate cache lines
+	// tail is the next position read by the consumer.
+	tail    uint64
+	dropped uint64
+	mask    uint64
+	slots   []ringSlot
+}
+
+type ringSlot struct {
+	seq uint64
+	b   *[]byte
+}
+
+var ringBufPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 500)
+		return &b
+	},
+}
+
+// NewRingWriter returns a RingWriter writing to w with shards ring buffers
+// of size events each. size is rounded up to a power of two.
+func NewRingWriter(w io.Writer, shards, size int) *RingWriter {
+	if shards < 1 {
+		shards = 1
+	}
+	n := 1
+	for n < size {
+		n <<= 1
+	}
+	rw := &RingWriter{
+		w:      w,
+		shards: make([]ringShard, shards),
+		notify: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for i := range rw.shards {
+		s := &rw.shards[i]
+		s.mask = uint64(n - 1)
+		s.slots = make([]ringSlot, n)
+		for j := range s.slots {
+			s.slots[j].seq = uint64(j)
+		}
+	}
+	go rw.run()
+	return rw
+}
+
+// Write implements the io.Writer interface. It never blocks.
+func (w *RingWriter) Write(p []byte) (n int, err error) {
+	return w.write(p, false)
+}
+
+// WriteLevel implements the LevelWriter interface. Unlike Write, it blocks
+// for events written with FatalLevel, PanicLevel or AuditLevel until they
+// are written.
+func (w *RingWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	return w.write(p, l >= FatalLevel && l != NoLevel)
+}
+
+func (w *RingWriter) write(p []byte, now bool) (n int, err error) {
+	if atomic.LoadUint32(&w.closed) == 1 {
+		return len(p), nil
+	}
+	if now {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.drain()
+		return w.w.Write(p)
+	}
+	s := &w.shards[atomic.AddUint32(&w.next, 1)%uint32(len(w.shards))]
+	// p is copied as the buffer of the event is reused once written.
+	b := ringBufPool.Get().(*[]byte)
+	*b = append((*b)[:0], p...)
+	if !s.push(b) {
+		ringBufPool.Put(b)
+		atomic.AddUint64(&s.dropped, 1)
+		return len(p), nil
+	}
+	if atomic.LoadUint32(&w.closed) == 1 {
+		// Close was called after the first check: the consumer goroutine
+		// may have done its last drain before the push.
+		w.mu.Lock()
+		w.drain()
+		w.mu.Unlock()
+		return len(p), nil
+	}
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of events dropped by each shard because it
+// was full.
+func (w *RingWriter) Dropped() []uint64 {
+	d := make([]uint64, len(w.shards))
+	for i := range w.shards {
+		d[i] = atomic.LoadUint64(&w.shards[i].dropped)
+	}
+	return d
+}
+
+// Close writes the queued events and stops the consumer goroutine. Events
+// written after Close are dropped.
+func (w *RingWriter) Close() error {
+	w.once.Do(func() {
+		atomic.StoreUint32(&w.closed, 1)
+		close(w.stop)
+	})
+	<-w.done
+	return nil
+}
+
+// Shutdown is like Close but returns ctx.Err() if ctx is done before the
+// queued events are written. It can be registered with RegisterShutdown.
+func (w *RingWriter) Shutdown(ctx context.Context) error {
+	w.once.Do(func() {
+		atomic.StoreUint32(&w.closed, 1)
+		close(w.stop)
+	})
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *RingWriter) run() {
+	defer close(w.done)
+	for {
+		w.mu.Lock()
+		found := w.drain()
+		w.mu.Unlock()
+		if found {
+			continue
+		}
+		select {
+		case <-w.notify:
+		case <-w.stop:
+			w.mu.Lock()
+			w.drain()
+			w.mu.Unlock()
+			return
+		}
+	}
+}
+
+// drain writes the queued events of all the shards and returns true if
+// there were any. It must be called with w.mu held.
+func (w *RingWriter) drain() bool {
+	found := false
+	for i := range w.shards {
+		s := &w.shards[i]
+		for {
+			b, ok := s.pop()
+			if !ok {
+				break
+			}
+			found = true
+			if _, err := w.w.Write(*b); err != nil {
+				handleError(nil, err)
+			}
+			if EventBufferMaxSize == 0 || cap(*b) <= EventBufferMaxSize {
+				ringBufPool.Put(b)
+			}
+		}
+	}
+	return found
+}
+
+// push queues b and returns false if s is full.
+func (s *ringShard) push(b *[]byte) bool {
+	pos := atomic.LoadUint64(&s.head)
+	for {
+		slot := &s.slots[pos&s.mask]
+		seq := atomic.LoadUint64(&slot.seq)
+		switch {
+		case seq == pos:
+			if atomic.CompareAndSwapUint64(&s.head, pos, pos+1) {
+				slot.b = b
+				atomic.StoreUint64(&slot.seq, pos+1)
+				return true
+			}
+			pos = atomic.LoadUint64(&s.head)
+		case seq < pos:
+			// The slot still holds the event of the previous lap.
+			return false
+		default:
+			// Another producer claimed pos.
+			pos = atomic.LoadUint64(&s.head)
+		}
+	}
+}
+
+// pop dequeues the next event of s. It must only be called with the mu lock
+// of the RingWriter held.
+func (s *ringShard) pop() (*[]byte, bool) {
+	slot := &s.slots[s.tail&s.mask]
+	if atomic.LoadUint64(&slot.seq) != s.tail+1 {
+		return nil, false
+	}
+	b := slot.b
+	slot.b = nil
+	atomic.StoreUint64(&slot.seq, s.tail+s.mask+1)
+	s.tail++
+	return b, true
+}
@@ -0,0 +1,116 @@
+package zerolog
+
+import (
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+type blockingWriter struct {
+	writeRecorder
+	unblock chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.unblock
+	return w.writeRecorder.Write(p)
+}
+
+func TestRingWriter(t *testing.T) {
+	out := &writeRecorder{}
+	w := NewRingWriter(out, 1, 16)
+	log := New(w)
+	for i := 0; i < 3; i++ {
+		log.Log().Int("n", i).Msg("")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	log.Log().Msg("dropped after close")
+	want := []string{`{"n":0}` + "\n", `{"n":1}` + "\n", `{"n":2}` + "\n"}
+	if got := out.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid writes:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestRingWriterFatal(t *testing.T) {
+	out := &writeRecorder{}
+	w := NewRingWriter(out, 2, 16)
+	defer w.Close()
+	log := New(w)
+	log.Log().Int("n", 0).Msg("")
+	log.Log().Int("n", 1).Msg("")
+	log.WithLevel(FatalLevel).Msg("")
+	// The fatal event is written when WriteLevel returns, after the queued
+	// events.
+	got := out.get()
+	if len(got) != 3 || got[2] != `{"level":"fatal"}`+"\n" {
+		t.Errorf("invalid writes: %q", got)
+	}
+}
+
+func TestRingWriterDropped(t *testing.T) {
+	out := &blockingWriter{unblock: make(chan struct{})}
+	w := NewRingWriter(out, 2, 2)
+	for i := 0; i < 20; i++ {
+		w.Write([]byte("event\n"))
+	}
+	close(out.unblock)
+	w.Close()
+	dropped := w.Dropped()
+	if len(dropped) != 2 {
+		t.Fatalf("len(Dropped()) = %d, want 2", len(dropped))
+	}
+	if total := dropped[0] + dropped[1] + uint64(len(out.get())); total != 20 {
+		t.Errorf("dropped %v and written %d events, want 20 in total", dropped, len(out.get()))
+	}
+	if dropped[0] == 0 || dropped[1] == 0 {
+		t.Errorf("Dropped() = %v, want drops on both shards", dropped)
+	}
+}
+
+func TestRingWriterConcurrent(t *testing.T) {
+	out := &writeRecorder{}
+	w := NewRingWriter(out, 4, 1024)
+	log := New(w)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				log.Log().Int("g", g).Int("i", i).Msg("")
+			}
+		}(g)
+	}
+	wg.Wait()
+	w.Close()
+	got := out.get()
+	var dropped uint64
+	for _, d := range w.Dropped() {
+		dropped += d
+	}
+	if uint64(len(got))+dropped != 800 {
+		t.Fatalf("written %d and dropped %d events, want 800 in total", len(got), dropped)
+	}
+	sort.Strings(got)
+	for i := 1; i < len(got); i++ {
+		if got[i] == got[i-1] {
+			t.Fatalf("event written twice: %q", got[i])
+		}
+	}
+}
+
+func BenchmarkRingWriter(b *testing.B) {
+	w := NewRingWriter(ioutil.Discard, 8, 4096)
+	defer w.Close()
+	logger := New(w)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info().Msg(fakeMessage)
+		}
+	})
+}
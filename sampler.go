@@ -0,0 +1,347 @@
+package zerolog
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// Often samples log every ~ 10 events.
+	Often = RandomSampler(10)
+	// Sometimes samples log every ~ 100 events.
+	Sometimes = RandomSampler(100)
+	// Rarely samples log every ~ 1000 events.
+	Rarely = RandomSampler(1000)
+)
+
+// Sampler defines an interface to a log sampler.
+type Sampler interface {
+	// Sample returns true if the event should be part of the sample, false if
+	// the event should be dropped.
+	Sample(lvl Level) bool
+}
+
+// SampleRater is implemented by samplers able to report their effective
+// sampling rate. The rate of such samplers is added to sampled events using
+// the zerolog.SampleRateFieldName field name.
+type SampleRater interface {
+	// SampleRate returns the current sampling rate: one event out of the
+	// returned value is kept.
+	SampleRate() uint32
+}
+
+// MessageSampler is implemented by samplers taking their decision based on
+// the message of the events. Once Sample returned true for an event,
+// SampleMessage is called when its message is set with Msg or Msgf.
+type MessageSampler interface {
+	// SampleMessage returns true if the event with msg should be part of the
+	// sample, false if the event should be dropped.
+	SampleMessage(lvl Level, msg string) bool
+}
+
+// RandomSampler use a PRNG to randomly sample an event out of N events,
+// regardless of their level.
+type RandomSampler uint32
+
+// Sample implements the Sampler interface.
+func (s RandomSampler) Sample(lvl Level) bool {
+	if s <= 0 {
+		return false
+	}
+	if rand.Intn(int(s)) != 0 {
+		return false
+	}
+	return true
+}
+
+// BasicSampler is a sampler that will send every Nth events, starting with
+// the first one, regardless of their level.
+type BasicSampler struct {
+	N       uint32
+	counter uint32
+}
+
+// Sample implements the Sampler interface.
+func (s *BasicSampler) Sample(lvl Level) bool {
+	n := s.N
+	if n == 0 {
+		return false
+	}
+	if n == 1 {
+		return true
+	}
+	c := atomic.AddUint32(&s.counter, 1)
+	return c%n == 1
+}
+
+// BurstSampler lets Burst events pass per Period then pass the decision to
+// NextSampler. If Sampler is not set, all subsequent events are rejected.
+type BurstSampler struct {
+	// Burst is the maximum number of event per period allowed before calling
+	// NextSampler.
+	Burst uint32
+	// Period defines the burst period. If 0, NextSampler is always called.
+	Period time.Duration
+	// NextSampler is the sampler used after the burst is reached. If nil,
+	// events are always rejected after the burst.
+	NextSampler Sampler
+
+	counter uint32
+	resetAt int64
+}
+
+// Sample implements the Sampler interface.
+func (s *BurstSampler) Sample(lvl Level) bool {
+	if s.Burst > 0 && s.Period > 0 {
+		if s.inc() <= s.Burst {
+			return true
+		}
+	}
+	if s.NextSampler == nil {
+		return false
+	}
+	return s.NextSampler.Sample(lvl)
+}
+
+func (s *BurstSampler) inc() uint32 {
+	now := time.Now().UnixNano()
+	resetAt := atomic.LoadInt64(&s.resetAt)
+	var c uint32
+	if now > resetAt {
+		c = 1
+		atomic.StoreUint32(&s.counter, c)
+		newResetAt := now + s.Period.Nanoseconds()
+		reset := atomic.CompareAndSwapInt64(&s.resetAt, resetAt, newResetAt)
+		if !reset {
+			// Lost the race with another goroutine trying to reset.
+			c = atomic.AddUint32(&s.counter, 1)
+		}
+	} else {
+		c = atomic.AddUint32(&s.counter, 1)
+	}
+	return c
+}
+
+// QuotaSampler lets at most N events of each level pass per Period, then one
+// event out of Thereafter until the end of the period. This bounds the worst
+// case log volume while keeping representative events.
+//
+//     log = log.Sample(&zerolog.QuotaSampler{N: 100, Thereafter: 100})
+type QuotaSampler struct {
+	// N is the number of events of each level allowed per period.
+	N uint32
+	// Thereafter defines the sampling rate once the quota is reached: one
+	// event out of Thereafter is kept. If 0, all the subsequent events of the
+	// period are dropped.
+	Thereafter uint32
+	// Period defines the quota period (default: 1 second).
+	Period time.Duration
+
+	levels sync.Map // Level -> *BurstSampler
+}
+
+// Sample implements the Sampler interface.
+func (s *QuotaSampler) Sample(lvl Level) bool {
+	b, ok := s.levels.Load(lvl)
+	if !ok {
+		period := s.Period
+		if period <= 0 {
+			period = time.Second
+		}
+		b, _ = s.levels.LoadOrStore(lvl, &BurstSampler{Period: period})
+	}
+	c := b.(*BurstSampler).inc()
+	if c <= s.N {
+		return true
+	}
+	return s.Thereafter > 0 && (c-s.N)%s.Thereafter == 0
+}
+
+// LevelSampler applies a different sampler for each level. A nil sampler
+// lets all the events of its level pass.
+type LevelSampler struct {
+	TraceSampler, DebugSampler, InfoSampler, WarnSampler, ErrorSampler Sampler
+}
+
+// Sample implements the Sampler interface.
+func (s LevelSampler) Sample(lvl Level) bool {
+	var sampler Sampler
+	switch lvl {
+	case TraceLevel:
+		sampler = s.TraceSampler
+	case DebugLevel:
+		sampler = s.DebugSampler
+	case InfoLevel:
+		sampler = s.InfoSampler
+	case WarnLevel:
+		sampler = s.WarnSampler
+	case ErrorLevel:
+		sampler = s.ErrorSampler
+	}
+	if sampler == nil {
+		return true
+	}
+	return sampler.Sample(lvl)
+}
+
+// AdaptiveSampler adjusts its sampling rate to the volume of events: when
+// more than Target events per second are submitted, it keeps one event out of
+// N, with N chosen so about Target events per second pass. The rate is
+// reevaluated every Period, loosening again when the traffic drops.
+//
+// AdaptiveSampler implements SampleRater so the effective rate is reported
+// with each event.
+type AdaptiveSampler struct {
+	// Target is the number of events per second above which sampling
+	// starts. If 0, events are not sampled.
+	Target uint32
+	// Period defines how often the sampling rate is reevaluated (default:
+	// 1 second).
+	Period time.Duration
+
+	windowStart int64
+	counter     uint32
+	rate        uint32
+}
+
+// Sample implements the Sampler interface.
+func (s *AdaptiveSampler) Sample(lvl Level) bool {
+	s.adjust()
+	c := atomic.AddUint32(&s.counter, 1)
+	rate := s.SampleRate()
+	return rate == 1 || c%rate == 1
+}
+
+// SampleRate implements the SampleRater interface.
+func (s *AdaptiveSampler) SampleRate() uint32 {
+	if rate := atomic.LoadUint32(&s.rate); rate > 1 {
+		return rate
+	}
+	return 1
+}
+
+// adjust reevaluates the sampling rate if the current period is over.
+func (s *AdaptiveSampler) adjust() {
+	period := s.Period
+	if period <= 0 {
+		period = time.Second
+	}
+	now := time.Now().UnixNano()
+	start := atomic.LoadInt64(&s.windowStart)
+	elapsed := now - start
+	if elapsed < int64(period) || !atomic.CompareAndSwapInt64(&s.windowStart, start, now) {
+		return
+	}
+	seen := atomic.SwapUint32(&s.counter, 0)
+	rate := uint32(1)
+	if s.Target > 0 && start > 0 {
+		perSecond := float64(seen) * float64(time.Second) / float64(elapsed)
+		if perSecond > float64(s.Target) {
+			rate = uint32(math.Ceil(perSecond / float64(s.Target)))
+		}
+	}
+	atomic.StoreUint32(&s.rate, rate)
+}
+
+// FirstNSampler lets the first N occurrences of each distinct message pass
+// per Period, then every Thereafter-th occurrence. This is meant to tame
+// recurring warnings while keeping a trace of their frequency.
+//
+// A counter is kept for each message (or key returned by Key) seen during the
+// period, so messages should have a low cardinality.
+//
+//     log = log.Sample(&zerolog.FirstNSampler{N: 10, Thereafter: 100, Period: time.Minute})
+type FirstNSampler struct {
+	// N is the number of occurrences of a message allowed per period.
+	N uint32
+	// Thereafter defines the sampling rate after the first N occurrences:
+	// one occurrence out of Thereafter is kept. If 0, all the subsequent
+	// occurrences are dropped.
+	Thereafter uint32
+	// Period defines the time after which counters are reset. If 0, counters
+	// are never reset.
+	Period time.Duration
+	// Key returns the key identifying the occurrences of an event. If nil,
+	// the message is used.
+	Key func(lvl Level, msg string) string
+
+	mu       sync.Mutex
+	resetAt  time.Time
+	counters map[string]uint32
+}
+
+// Sample implements the Sampler interface. The decision is taken by
+// SampleMessage.
+func (s *FirstNSampler) Sample(lvl Level) bool {
+	return true
+}
+
+// SampleMessage implements the MessageSampler interface.
+func (s *FirstNSampler) SampleMessage(lvl Level, msg string) bool {
+	key := msg
+	if s.Key != nil {
+		key = s.Key(lvl, msg)
+	}
+	s.mu.Lock()
+	if now := time.Now(); s.counters == nil || (s.Period > 0 && now.After(s.resetAt)) {
+		s.counters = map[string]uint32{}
+		s.resetAt = now.Add(s.Period)
+	}
+	c := s.counters[key] + 1
+	s.counters[key] = c
+	s.mu.Unlock()
+	if c <= s.N {
+		return true
+	}
+	return s.Thereafter > 0 && (c-s.N)%s.Thereafter == 0
+}
+
+// KeySampler samples events by entity rather than by line: all the events of
+// an entity identified by the value of the string field Field are either kept
+// or dropped together.
+//
+// The decision is taken when a logger sampled with a KeySampler gets Field
+// added to its context with Context.Str. Events of a logger without Field in
+// its context are not sampled.
+//
+//     sampled := log.Sample(zerolog.KeySampler{Field: "user_id", N: 10})
+//     userLog := sampled.With().Str("user_id", userID).Logger()
+//     // Events of userLog are logged for one user out of 10.
+type KeySampler struct {
+	// Field is the name of the string field identifying an entity.
+	Field string
+	// N defines the sampling rate: one entity out of N is kept.
+	N uint32
+}
+
+// Sample implements the Sampler interface. It lets all events pass as long
+// as no entity has been bound.
+func (s KeySampler) Sample(lvl Level) bool {
+	return true
+}
+
+// Keep returns true if the events of the entity identified by value are part
+// of the sample. The result is deterministic for a given value and N.
+func (s KeySampler) Keep(value string) bool {
+	if s.N == 0 {
+		return false
+	}
+	// FNV-1a
+	h := uint32(2166136261)
+	for i := 0; i < len(value); i++ {
+		h ^= uint32(value[i])
+		h *= 16777619
+	}
+	return h%s.N == 0
+}
+
+// fixedSampler is a sampler with a decision taken ahead of time.
+type fixedSampler bool
+
+// Sample implements the Sampler interface.
+func (s fixedSampler) Sample(lvl Level) bool {
+	return bool(s)
+}
@@ -0,0 +1,240 @@
+package zerolog
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var samplers = []struct {
+	name    string
+	sampler func() Sampler
+	total   int
+	wantMin int
+	wantMax int
+}{
+	{
+		"BasicSampler_1",
+		func() Sampler {
+			return &BasicSampler{N: 1}
+		},
+		100, 100, 100,
+	},
+	{
+		"BasicSampler_5",
+		func() Sampler {
+			return &BasicSampler{N: 5}
+		},
+		100, 20, 20,
+	},
+	{
+		"RandomSampler",
+		func() Sampler {
+			return RandomSampler(5)
+		},
+		100, 5, 40,
+	},
+	{
+		"BurstSampler",
+		func() Sampler {
+			return &BurstSampler{Burst: 20, Period: time.Second}
+		},
+		100, 20, 20,
+	},
+	{
+		"BurstSamplerNext",
+		func() Sampler {
+			return &BurstSampler{Burst: 20, Period: time.Second, NextSampler: &BasicSampler{N: 4}}
+		},
+		120, 45, 45,
+	},
+	{
+		"QuotaSampler",
+		func() Sampler {
+			return &QuotaSampler{N: 20, Thereafter: 4}
+		},
+		120, 45, 45,
+	},
+}
+
+func TestSamplers(t *testing.T) {
+	for i := range samplers {
+		s := samplers[i]
+		t.Run(s.name, func(t *testing.T) {
+			sampler := s.sampler()
+			got := 0
+			for t := s.total; t > 0; t-- {
+				if sampler.Sample(0) {
+					got++
+				}
+			}
+			if got < s.wantMin || got > s.wantMax {
+				t.Errorf("%s.Sample(0) == true %d on %d, want [%d, %d]", s.name, got, s.total, s.wantMin, s.wantMax)
+			}
+		})
+	}
+}
+
+func TestLevelSampler(t *testing.T) {
+	s := LevelSampler{
+		DebugSampler: &BasicSampler{N: 2},
+		InfoSampler:  RandomSampler(0),
+	}
+	var debug, info, warn int
+	for i := 0; i < 10; i++ {
+		if s.Sample(DebugLevel) {
+			debug++
+		}
+		if s.Sample(InfoLevel) {
+			info++
+		}
+		if s.Sample(WarnLevel) {
+			warn++
+		}
+	}
+	if debug != 5 || info != 0 || warn != 10 {
+		t.Errorf("invalid sampling: debug=%d info=%d warn=%d, want 5, 0, 10", debug, info, warn)
+	}
+}
+
+func TestAdaptiveSampler(t *testing.T) {
+	s := &AdaptiveSampler{Target: 1000, Period: 20 * time.Millisecond}
+	for i := 0; i < 100; i++ {
+		if !s.Sample(InfoLevel) {
+			t.Fatal("event dropped under target")
+		}
+	}
+
+	// Submit well above target for a full period.
+	deadline := time.Now().Add(25 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		for i := 0; i < 100; i++ {
+			s.Sample(InfoLevel)
+		}
+	}
+	s.Sample(InfoLevel)
+	if rate := s.SampleRate(); rate <= 1 {
+		t.Errorf("rate not tightened under load: %d", rate)
+	}
+
+	// Let the traffic drop for a full period.
+	time.Sleep(25 * time.Millisecond)
+	s.Sample(InfoLevel)
+	time.Sleep(25 * time.Millisecond)
+	s.Sample(InfoLevel)
+	if rate := s.SampleRate(); rate != 1 {
+		t.Errorf("rate not loosened after load: %d", rate)
+	}
+
+	out := &bytes.Buffer{}
+	New(out).Sample(s).Info().Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","sample_rate":1}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+}
+
+func TestKeySampler(t *testing.T) {
+	s := KeySampler{Field: "user_id", N: 4}
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		v := strconv.Itoa(i)
+		keep := s.Keep(v)
+		if keep != s.Keep(v) {
+			t.Fatalf("Keep(%q) is not deterministic", v)
+		}
+		if keep {
+			kept++
+		}
+	}
+	if kept < 150 || kept > 350 {
+		t.Errorf("Keep() == true %d on 1000, want ~250", kept)
+	}
+
+	out := &bytes.Buffer{}
+	log := New(out).Sample(s)
+	log.Info().Msg("not bound")
+	for i := 0; i < 10; i++ {
+		v := strconv.Itoa(i)
+		l := log.With().Str("user_id", v).Logger()
+		l.Info().Msg("1")
+		l.With().Str("foo", "bar").Logger().Info().Msg("2")
+		n := strings.Count(decodeIfBinaryToString(out.Bytes()), `"user_id":"`+v+`"`)
+		if want := map[bool]int{true: 2, false: 0}[s.Keep(v)]; n != want {
+			t.Errorf("user %s: got %d events, want %d", v, n, want)
+		}
+	}
+	if !strings.HasPrefix(decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"not bound"}`) {
+		t.Errorf("unbound event not logged: %q", decodeIfBinaryToString(out.Bytes()))
+	}
+}
+
+func TestQuotaSampler(t *testing.T) {
+	s := &QuotaSampler{N: 5, Thereafter: 10, Period: 50 * time.Millisecond}
+	count := func(lvl Level, n int) (kept int) {
+		for i := 0; i < n; i++ {
+			if s.Sample(lvl) {
+				kept++
+			}
+		}
+		return kept
+	}
+	if got, want := count(InfoLevel, 100), 5+9; got != want {
+		t.Errorf("invalid info count: got %d, want %d", got, want)
+	}
+	if got, want := count(ErrorLevel, 5), 5; got != want {
+		t.Errorf("invalid error count: got %d, want %d", got, want)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if got, want := count(InfoLevel, 5), 5; got != want {
+		t.Errorf("quota not reset: got %d, want %d", got, want)
+	}
+}
+
+func TestFirstNSampler(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Sample(&FirstNSampler{N: 2, Thereafter: 3, Period: 50 * time.Millisecond})
+	for i := 0; i < 8; i++ {
+		log.Warn().Int("i", i).Msg("retrying")
+		log.Warn().Int("i", i).Msgf("%s", "failed")
+	}
+	if got, want := strings.Count(decodeIfBinaryToString(out.Bytes()), `"message":"retrying"`), 4; got != want {
+		t.Errorf("invalid retrying count: got %d, want %d", got, want)
+	}
+	if !strings.Contains(decodeIfBinaryToString(out.Bytes()), `{"level":"warn","i":4,"message":"failed"}`) {
+		t.Errorf("5th occurrence not logged: %q", decodeIfBinaryToString(out.Bytes()))
+	}
+	if strings.Contains(decodeIfBinaryToString(out.Bytes()), `{"level":"warn","i":5,"message":"failed"}`) {
+		t.Errorf("6th occurrence logged: %q", decodeIfBinaryToString(out.Bytes()))
+	}
+	time.Sleep(60 * time.Millisecond)
+	out.Reset()
+	log.Warn().Msg("retrying")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"warn","message":"retrying"}`+"\n"; got != want {
+		t.Errorf("counter not reset: got %q, want %q", got, want)
+	}
+}
+
+func TestDisableSampling(t *testing.T) {
+	DisableSampling(true)
+	defer DisableSampling(false)
+	log := New(nil).Sample(RandomSampler(0))
+	if !log.Info().Enabled() {
+		t.Error("event not enabled with sampling disabled")
+	}
+}
+
+func BenchmarkSamplers(b *testing.B) {
+	for i := range samplers {
+		s := samplers[i]
+		b.Run(s.name, func(b *testing.B) {
+			sampler := s.sampler()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					sampler.Sample(0)
+				}
+			})
+		})
+	}
+}
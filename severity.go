@@ -0,0 +1,95 @@
+package zerolog
+
+// SeverityMap maps zerolog levels to the severity vocabulary of a downstream
+// system. It is used by writers and by Logger.Severity so all the layers
+// agree on the severity of an event.
+//
+// Custom levels missing from the map get the severity of the closest mapped
+// level below them, or of the lowest mapped level if none. NoLevel and
+// AuditLevel are only mapped if they have an explicit entry.
+//
+// Maps must not be modified once in use.
+type SeverityMap map[Level]string
+
+// Severity returns the severity of l, or an empty string if l is not mapped.
+func (m SeverityMap) Severity(l Level) string {
+	if s, ok := m[l]; ok {
+		return s
+	}
+	if l == NoLevel || l == AuditLevel || l == Disabled {
+		return ""
+	}
+	var s, lowest string
+	found, foundLowest := false, false
+	var best, min Level
+	for ml, ms := range m {
+		if ml == NoLevel || ml == AuditLevel || ml == Disabled {
+			continue
+		}
+		if ml < l && (!found || ml > best) {
+			best, s, found = ml, ms, true
+		}
+		if !foundLowest || ml < min {
+			min, lowest, foundLowest = ml, ms, true
+		}
+	}
+	if found {
+		return s
+	}
+	return lowest
+}
+
+var (
+	// SyslogSeverities maps levels to the syslog severity used by
+	// SyslogLevelWriter.
+	SyslogSeverities = SeverityMap{
+		TraceLevel: "debug",
+		DebugLevel: "debug",
+		InfoLevel:  "info",
+		WarnLevel:  "warning",
+		ErrorLevel: "err",
+		FatalLevel: "emerg",
+		PanicLevel: "crit",
+		AuditLevel: "info",
+		NoLevel:    "info",
+	}
+
+	// SyslogNumericSeverities maps levels to the numerical code of their
+	// syslog severity as defined by RFC 5424.
+	SyslogNumericSeverities = SeverityMap{
+		TraceLevel: "7",
+		DebugLevel: "7",
+		InfoLevel:  "6",
+		WarnLevel:  "4",
+		ErrorLevel: "3",
+		FatalLevel: "0",
+		PanicLevel: "2",
+		AuditLevel: "6",
+		NoLevel:    "6",
+	}
+
+	// GCPSeverities maps levels to Google Cloud Logging severities.
+	GCPSeverities = SeverityMap{
+		TraceLevel: "DEBUG",
+		DebugLevel: "DEBUG",
+		InfoLevel:  "INFO",
+		WarnLevel:  "WARNING",
+		ErrorLevel: "ERROR",
+		FatalLevel: "CRITICAL",
+		PanicLevel: "ALERT",
+		AuditLevel: "NOTICE",
+		NoLevel:    "DEFAULT",
+	}
+
+	// PagerDutySeverities maps levels to PagerDuty event severities.
+	PagerDutySeverities = SeverityMap{
+		TraceLevel: "info",
+		DebugLevel: "info",
+		InfoLevel:  "info",
+		WarnLevel:  "warning",
+		ErrorLevel: "error",
+		FatalLevel: "critical",
+		PanicLevel: "critical",
+		AuditLevel: "info",
+	}
+)
@@ -0,0 +1,44 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSeverityMap(t *testing.T) {
+	tests := []struct {
+		level Level
+		want  string
+	}{
+		{TraceLevel - 5, "7"},
+		{DebugLevel, "7"},
+		{InfoLevel, "6"},
+		{ErrorLevel, "3"},
+		{PanicLevel + 1, "2"},
+		{AuditLevel, "6"},
+		{NoLevel, "6"},
+		{Disabled, ""},
+	}
+	for _, tt := range tests {
+		if got := SyslogNumericSeverities.Severity(tt.level); got != tt.want {
+			t.Errorf("Severity(%v): got %q, want %q", tt.level, got, tt.want)
+		}
+	}
+	if got := PagerDutySeverities.Severity(NoLevel); got != "" {
+		t.Errorf("unmapped NoLevel: got %q", got)
+	}
+}
+
+func TestLoggerSeverity(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Severity(GCPSeverities)
+	log.Warn().Msg("warn")
+	log.Log().Msg("nolevel")
+	log.Severity(PagerDutySeverities).Log().Msg("unmapped")
+	want := `{"level":"warn","severity":"WARNING","message":"warn"}` + "\n" +
+		`{"severity":"DEFAULT","message":"nolevel"}` + "\n" +
+		`{"message":"unmapped"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,161 @@
+package zerolog
+
+import (
+	"context"
+	"io"
+	"net"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShardedWriter accumulates events in one buffer per processor, as set by
+// GOMAXPROCS, and periodically merges the buffers into a single write to the
+// wrapped writer. Goroutines logging concurrently mostly append to distinct
+// buffers, so they rarely contend on a lock, and the wrapped writer sees a
+// few large writes instead of one write per event. The buffers are written
+// with a single vectored write, without merging them, to TCP and Unix
+// connections and, on Linux, to files.
+//
+// Ordering is only kept per buffer: events written to different buffers
+// within an interval, even by the same goroutine, may be written out of
+// order. Events are never dropped: all the buffers are merged and written as
+// soon as one of them reaches maxSize bytes. Events written with FatalLevel,
+// PanicLevel or AuditLevel are written immediately, with the pending events.
+//
+// Errors of the periodic writes are reported to ErrorHandler. Close, or
+// Shutdown registered with RegisterShutdown, must be called to write the
+// pending events and stop the merging goroutine.
+type ShardedWriter struct {
+	w       io.Writer
+	maxSize int
+	shards  []writerShard
+	next    uint32
+	// mu serializes the merges, so the events of a buffer are written in
+	// order, and protects the fields below. The buffers of the shards are
+	// swapped with spares, written with a vectored write when supported by
+	// w, or merged into merged.
+	mu     sync.Mutex
+	spares [][]byte
+	bufs   net.Buffers
+	merged []byte
+	closed uint32
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+type writerShard struct {
+	mu  sync.Mutex
+	buf []byte
+	_   [32]byte // keep the shards on separate cache lines
+}
+
+// NewShardedWriter returns a ShardedWriter writing to w every interval, with
+// buffers of maxSize bytes.
+func NewShardedWriter(w io.Writer, maxSize int, interval time.Duration) *ShardedWriter {
+	sw := &ShardedWriter{
+		w:       w,
+		maxSize: maxSize,
+		shards:  make([]writerShard, runtime.GOMAXPROCS(0)),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	sw.spares = make([][]byte, len(sw.shards))
+	for i := range sw.shards {
+		sw.shards[i].buf = make([]byte, 0, maxSize)
+		sw.spares[i] = make([]byte, 0, maxSize)
+	}
+	go sw.run(interval)
+	return sw
+}
+
+// Write implements the io.Writer interface.
+func (w *ShardedWriter) Write(p []byte) (n int, err error) {
+	return w.write(p, false)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *ShardedWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	return w.write(p, l >= FatalLevel && l != NoLevel)
+}
+
+func (w *ShardedWriter) write(p []byte, flush bool) (n int, err error) {
+	s := &w.shards[atomic.AddUint32(&w.next, 1)%uint32(len(w.shards))]
+	s.mu.Lock()
+	// p is copied as the buffer of the event is reused once written.
+	s.buf = append(s.buf, p...)
+	full := len(s.buf) >= w.maxSize
+	s.mu.Unlock()
+	if flush || full || atomic.LoadUint32(&w.closed) == 1 {
+		if err = w.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush merges the pending events of all the buffers and writes them.
+func (w *ShardedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.bufs = w.bufs[:0]
+	for i := range w.shards {
+		s := &w.shards[i]
+		s.mu.Lock()
+		s.buf, w.spares[i] = w.spares[i][:0], s.buf
+		s.mu.Unlock()
+		if len(w.spares[i]) > 0 {
+			w.bufs = append(w.bufs, w.spares[i])
+		}
+	}
+	if len(w.bufs) == 0 {
+		return nil
+	}
+	var err error
+	w.merged, err = writeBuffers(w.w, w.bufs, w.merged)
+	for i, b := range w.spares {
+		if cap(b) > 2*w.maxSize {
+			// Do not keep the memory of an oversized event.
+			w.spares[i] = make([]byte, 0, w.maxSize)
+		}
+	}
+	if cap(w.merged) > 2*w.maxSize*len(w.shards) {
+		w.merged = nil
+	}
+	return err
+}
+
+// Close writes the pending events and stops the merging goroutine. Events
+// written after Close are written immediately. It implements the io.Closer
+// interface; the wrapped writer is not closed.
+func (w *ShardedWriter) Close() error {
+	w.once.Do(func() {
+		atomic.StoreUint32(&w.closed, 1)
+		close(w.stop)
+	})
+	<-w.done
+	return w.Flush()
+}
+
+// Shutdown is like Close. It can be registered with RegisterShutdown.
+func (w *ShardedWriter) Shutdown(ctx context.Context) error {
+	return w.Close()
+}
+
+func (w *ShardedWriter) run(interval time.Duration) {
+	defer close(w.done)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := w.Flush(); err != nil {
+				handleError(nil, err)
+			}
+		case <-w.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package zerolog
+
+import (
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShardedWriter(t *testing.T) {
+	out := &writeRecorder{}
+	w := NewShardedWriter(out, 1024, time.Hour)
+	log := New(w)
+	log.Log().Str("n", "1").Msg("")
+	log.Log().Str("n", "2").Msg("")
+	if got := out.get(); len(got) != 0 {
+		t.Fatalf("events written before the interval: %q", got)
+	}
+	log.WithLevel(FatalLevel).Msg("")
+	got := out.get()
+	if len(got) != 1 {
+		t.Fatalf("got %d writes after a fatal event, want 1", len(got))
+	}
+	lines := strings.SplitAfter(got[0], "\n")
+	sort.Strings(lines)
+	want := []string{"", `{"level":"fatal"}` + "\n", `{"n":"1"}` + "\n", `{"n":"2"}` + "\n"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("invalid events:\ngot:  %q\nwant: %q", lines, want)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	log.Log().Str("n", "3").Msg("")
+	if got := out.get(); len(got) != 2 || got[1] != `{"n":"3"}`+"\n" {
+		t.Errorf("invalid writes after Close: %q", got)
+	}
+}
+
+func TestShardedWriterFull(t *testing.T) {
+	out := &writeRecorder{}
+	w := NewShardedWriter(out, 16, time.Hour)
+	defer w.Close()
+	w.Write([]byte(strings.Repeat("x", 16) + "\n"))
+	if got := out.get(); len(got) != 1 {
+		t.Errorf("got %d writes after filling a buffer, want 1", len(got))
+	}
+}
+
+func TestShardedWriterInterval(t *testing.T) {
+	out := &writeRecorder{}
+	w := NewShardedWriter(out, 1024, 10*time.Millisecond)
+	defer w.Close()
+	New(w).Log().Str("n", "1").Msg("")
+	deadline := time.Now().Add(time.Second)
+	for len(out.get()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	want := []string{`{"n":"1"}` + "\n"}
+	if got := out.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid writes:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestShardedWriterConcurrent(t *testing.T) {
+	out := &writeRecorder{}
+	w := NewShardedWriter(out, 256, time.Millisecond)
+	log := New(w)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				log.Log().Int("g", g).Int("i", i).Msg("")
+			}
+		}(g)
+	}
+	wg.Wait()
+	w.Close()
+	var got []string
+	for _, p := range out.get() {
+		got = append(got, strings.SplitAfter(strings.TrimSuffix(p, "\n"), "\n")...)
+	}
+	if len(got) != 800 {
+		t.Fatalf("written %d events, want 800", len(got))
+	}
+	sort.Strings(got)
+	for i := 1; i < len(got); i++ {
+		if got[i] == got[i-1] {
+			t.Fatalf("event written twice: %q", got[i])
+		}
+	}
+}
+
+func BenchmarkShardedWriter(b *testing.B) {
+	w := NewShardedWriter(ioutil.Discard, 64<<10, 100*time.Millisecond)
+	defer w.Close()
+	logger := New(w)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info().Msg(fakeMessage)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package zerolog
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+var (
+	shutdownMu    sync.Mutex
+	shutdownHooks []shutdownHook
+)
+
+type shutdownHook struct {
+	name string
+	f    func(ctx context.Context) error
+}
+
+// RegisterShutdown registers f to be called by Shutdown to flush and close
+// an asynchronous or buffered writer, or an AsyncHook. name identifies the
+// writer in the error returned by Shutdown. f must return once ctx is done.
+func RegisterShutdown(name string, f func(ctx context.Context) error) {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+	shutdownHooks = append(shutdownHooks, shutdownHook{name, f})
+}
+
+// ShutdownError is returned by Shutdown when some writers could not be
+// flushed.
+type ShutdownError struct {
+	// Names are the names of the writers which could not be flushed.
+	Names []string
+
+	// Errs are the errors of the writers, in the order of Names.
+	Errs []error
+}
+
+func (e *ShutdownError) Error() string {
+	parts := make([]string, len(e.Names))
+	for i, name := range e.Names {
+		parts[i] = name + ": " + e.Errs[i].Error()
+	}
+	return "zerolog: shutdown: " + strings.Join(parts, "; ")
+}
+
+// Shutdown flushes and closes the writers registered with RegisterShutdown,
+// in the reverse order of their registration, within the deadline of ctx.
+// It is meant to be called before the process exits, for instance when a
+// Kubernetes pod is terminated:
+//
+//     ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//     defer cancel()
+//     if err := zerolog.Shutdown(ctx); err != nil {
+//         fmt.Fprintln(os.Stderr, err)
+//     }
+//
+// Writers are unregistered once Shutdown is called. If a writer fails, or
+// ctx is done before it is flushed, the returned *ShutdownError reports it
+// with the writers which were not flushed yet.
+func Shutdown(ctx context.Context) error {
+	shutdownMu.Lock()
+	hooks := shutdownHooks
+	shutdownHooks = nil
+	shutdownMu.Unlock()
+	var serr ShutdownError
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h := hooks[i]
+		var err error
+		if err = ctx.Err(); err == nil {
+			done := make(chan error, 1)
+			go func() {
+				done <- h.f(ctx)
+			}()
+			select {
+			case err = <-done:
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+		}
+		if err != nil {
+			serr.Names = append(serr.Names, h.name)
+			serr.Errs = append(serr.Errs, err)
+		}
+	}
+	if len(serr.Names) > 0 {
+		return &serr
+	}
+	return nil
+}
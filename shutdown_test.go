@@ -0,0 +1,98 @@
+package zerolog
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// callRecorder records calls made from the goroutines of Shutdown.
+type callRecorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *callRecorder) record(name string) {
+	r.mu.Lock()
+	r.calls = append(r.calls, name)
+	r.mu.Unlock()
+}
+
+func (r *callRecorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+func TestShutdown(t *testing.T) {
+	r := &callRecorder{}
+	RegisterShutdown("first", func(ctx context.Context) error {
+		r.record("first")
+		return nil
+	})
+	RegisterShutdown("failing", func(ctx context.Context) error {
+		r.record("failing")
+		return errors.New("broken pipe")
+	})
+	RegisterShutdown("stuck", func(ctx context.Context) error {
+		r.record("stuck")
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := Shutdown(ctx)
+	if want := []string{"stuck"}; !reflect.DeepEqual(r.get(), want) {
+		t.Errorf("invalid calls: got %v, want %v", r.get(), want)
+	}
+	serr, ok := err.(*ShutdownError)
+	if !ok {
+		t.Fatalf("Shutdown() = %v, want a *ShutdownError", err)
+	}
+	if want := []string{"stuck", "failing", "first"}; !reflect.DeepEqual(serr.Names, want) {
+		t.Errorf("invalid names: got %v, want %v", serr.Names, want)
+	}
+	if want := "zerolog: shutdown: stuck: context deadline exceeded; failing: context deadline exceeded; first: context deadline exceeded"; err.Error() != want {
+		t.Errorf("invalid error: got %q, want %q", err, want)
+	}
+	if err := Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown() = %v, want nil", err)
+	}
+
+	r = &callRecorder{}
+	RegisterShutdown("first", func(ctx context.Context) error {
+		r.record("first")
+		return nil
+	})
+	RegisterShutdown("failing", func(ctx context.Context) error {
+		r.record("failing")
+		return errors.New("broken pipe")
+	})
+	err = Shutdown(context.Background())
+	if want := []string{"failing", "first"}; !reflect.DeepEqual(r.get(), want) {
+		t.Errorf("invalid calls: got %v, want %v", r.get(), want)
+	}
+	if want := "zerolog: shutdown: failing: broken pipe"; err == nil || err.Error() != want {
+		t.Errorf("invalid error: got %v, want %q", err, want)
+	}
+}
+
+func TestAsyncHookShutdown(t *testing.T) {
+	release := make(chan struct{})
+	h := NewAsyncHook(HookFunc(func(e *Event, level Level, msg string) {
+		<-release
+	}), 1)
+	New(nil).Hook(h).Info().Msg("a")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := h.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(release)
+	if err := h.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,104 @@
+package zerolog
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// standardLevels lists the levels, ordered from the most to the least
+// verbose, the global level steps through with HandleLevelSignals.
+var standardLevels = []Level{
+	TraceLevel,
+	DebugLevel,
+	InfoLevel,
+	WarnLevel,
+	ErrorLevel,
+	FatalLevel,
+	PanicLevel,
+}
+
+// HandleLevelSignals installs a handler lowering the global level one notch
+// (more verbose) when the down signal is received and raising it one notch
+// (less verbose) on the up signal. If revert is not zero, the global level
+// is restored to its original value once revert elapsed without any new
+// signal.
+//
+// Levels step through the standard levels, from trace to panic. The returned
+// function uninstalls the handler, restoring the original level if a revert
+// is pending, and may be called more than once.
+//
+// A typical usage for a long running daemon is:
+//
+//     stop := zerolog.HandleLevelSignals(syscall.SIGUSR1, syscall.SIGUSR2, 10*time.Minute)
+//     defer stop()
+func HandleLevelSignals(down, up os.Signal, revert time.Duration) (stop func()) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, down, up)
+	done, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer signal.Stop(c)
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		orig := GlobalLevel()
+		for {
+			select {
+			case sig := <-c:
+				cur := GlobalLevel()
+				if timeout == nil {
+					orig = cur
+				}
+				SetGlobalLevel(stepLevel(cur, sig != down))
+				if revert > 0 {
+					if timer != nil {
+						timer.Stop()
+					}
+					timer = time.NewTimer(revert)
+					timeout = timer.C
+				}
+			case <-timeout:
+				SetGlobalLevel(orig)
+				timer, timeout = nil, nil
+			case <-done:
+				if timer != nil {
+					timer.Stop()
+					SetGlobalLevel(orig)
+				}
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-exited
+	}
+}
+
+// stepLevel returns the standard level following l if up is true or
+// preceding l otherwise. Custom levels step to the closest standard level in
+// the requested direction.
+func stepLevel(l Level, up bool) Level {
+	if up {
+		for _, sl := range standardLevels {
+			if sl > l {
+				return sl
+			}
+		}
+		if l > PanicLevel {
+			return l
+		}
+		return PanicLevel
+	}
+	for i := len(standardLevels) - 1; i >= 0; i-- {
+		if sl := standardLevels[i]; sl < l {
+			return sl
+		}
+	}
+	if l < TraceLevel {
+		return l
+	}
+	return TraceLevel
+}
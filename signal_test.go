@@ -0,0 +1,85 @@
+// +build !windows
+
+package zerolog
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestStepLevel(t *testing.T) {
+	tests := []struct {
+		l    Level
+		up   bool
+		want Level
+	}{
+		{InfoLevel, true, WarnLevel},
+		{InfoLevel, false, DebugLevel},
+		{TraceLevel, false, TraceLevel},
+		{PanicLevel, true, PanicLevel},
+		{Disabled, true, Disabled},
+		{Disabled, false, PanicLevel},
+		{TraceLevel - 5, true, TraceLevel},
+		{PanicLevel + 5, false, PanicLevel},
+	}
+	for _, tt := range tests {
+		if got := stepLevel(tt.l, tt.up); got != tt.want {
+			t.Errorf("stepLevel(%v, %v) = %v, want %v", tt.l, tt.up, got, tt.want)
+		}
+	}
+}
+
+func waitGlobalLevel(t *testing.T, want Level) {
+	deadline := time.Now().Add(time.Second)
+	for GlobalLevel() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("invalid global level: got %v, want %v", GlobalLevel(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHandleLevelSignals(t *testing.T) {
+	defer SetGlobalLevel(GlobalLevel())
+	SetGlobalLevel(InfoLevel)
+	stop := HandleLevelSignals(syscall.SIGUSR1, syscall.SIGUSR2, 50*time.Millisecond)
+	defer stop()
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Signal(syscall.SIGUSR1)
+	waitGlobalLevel(t, DebugLevel)
+	p.Signal(syscall.SIGUSR1)
+	waitGlobalLevel(t, TraceLevel)
+	// Reverts to the level set before the first signal.
+	waitGlobalLevel(t, InfoLevel)
+
+	p.Signal(syscall.SIGUSR2)
+	waitGlobalLevel(t, WarnLevel)
+	waitGlobalLevel(t, InfoLevel)
+
+	stop()
+	stop()
+}
+
+func TestHandleLevelSignalsStopReverts(t *testing.T) {
+	defer SetGlobalLevel(GlobalLevel())
+	SetGlobalLevel(InfoLevel)
+	stop := HandleLevelSignals(syscall.SIGUSR1, syscall.SIGUSR2, time.Hour)
+	defer stop()
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Signal(syscall.SIGUSR2)
+	waitGlobalLevel(t, WarnLevel)
+	stop()
+	if got := GlobalLevel(); got != InfoLevel {
+		t.Errorf("level not restored by stop: got %v, want %v", got, InfoLevel)
+	}
+}
@@ -0,0 +1,196 @@
+// +build go1.21
+
+package zerolog
+
+import (
+	"context"
+	"log/slog"
+)
+
+type slogGroup struct {
+	name  string
+	attrs []slog.Attr
+}
+
+type slogHandler struct {
+	l Logger
+	// groups are the groups opened with WithGroup, outermost first, with
+	// the attributes added in them.
+	groups []slogGroup
+}
+
+// NewSlogHandler returns a slog.Handler writing the records to l, so
+// libraries instrumented with log/slog feed into the writers, hooks and
+// samplers of l:
+//
+//     slog.SetDefault(slog.New(zerolog.NewSlogHandler(log.Logger)))
+//
+// Attributes are added as fields and groups as dictionaries. slog levels are
+// mapped to the closest zerolog level at or below them: slog.LevelDebug to
+// DebugLevel, slog.LevelInfo to InfoLevel and so on, levels below
+// slog.LevelDebug to TraceLevel. The time of the record is added unless the
+// logger context has a timestamp.
+func NewSlogHandler(l Logger) slog.Handler {
+	return slogHandler{l: l}
+}
+
+// slogLevel returns the zerolog level of the slog level lvl.
+func slogLevel(lvl slog.Level) Level {
+	switch {
+	case lvl < slog.LevelDebug:
+		return TraceLevel
+	case lvl < slog.LevelInfo:
+		return DebugLevel
+	case lvl < slog.LevelWarn:
+		return InfoLevel
+	case lvl < slog.LevelError:
+		return WarnLevel
+	}
+	return ErrorLevel
+}
+
+// Enabled implements the slog.Handler interface.
+func (h slogHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	level := slogLevel(lvl)
+	if !DebugEnabled && level < InfoLevel {
+		return false
+	}
+	return level >= h.l.EffectiveLevel()
+}
+
+// Handle implements the slog.Handler interface.
+func (h slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	e := h.l.WithLevel(slogLevel(r.Level))
+	if !e.Enabled() {
+		return nil
+	}
+	if ctx != nil {
+		e.Ctx(ctx)
+	}
+	if !r.Time.IsZero() && (len(h.l.context) == 0 || h.l.context[0] == 0) {
+		e.Time(TimestampFieldName, r.Time)
+	}
+	if len(h.groups) == 0 {
+		r.Attrs(func(a slog.Attr) bool {
+			addSlogAttr(e, a)
+			return true
+		})
+	} else if d := h.groupDict(0, r); d != nil {
+		e.Dict(h.groups[0].name, d)
+	}
+	e.Msg(r.Message)
+	return nil
+}
+
+// groupDict returns the dictionary of the i-th group holding its attributes
+// and the inner groups, the innermost one holding the attributes of r, or
+// nil if the group is empty.
+func (h slogHandler) groupDict(i int, r slog.Record) *Event {
+	d := Dict()
+	empty := true
+	for _, a := range h.groups[i].attrs {
+		empty = !addSlogAttr(d, a) && empty
+	}
+	if i == len(h.groups)-1 {
+		r.Attrs(func(a slog.Attr) bool {
+			empty = !addSlogAttr(d, a) && empty
+			return true
+		})
+	} else if inner := h.groupDict(i+1, r); inner != nil {
+		d.Dict(h.groups[i+1].name, inner)
+		empty = false
+	}
+	if empty {
+		putEvent(d)
+		return nil
+	}
+	return d
+}
+
+// WithAttrs implements the slog.Handler interface.
+func (h slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	if len(h.groups) > 0 {
+		// Copy so siblings don't share the same backing arrays.
+		groups := make([]slogGroup, len(h.groups))
+		copy(groups, h.groups)
+		last := &groups[len(groups)-1]
+		last.attrs = append(append([]slog.Attr(nil), last.attrs...), attrs...)
+		h.groups = groups
+		return h
+	}
+	d := Dict()
+	for _, a := range attrs {
+		addSlogAttr(d, a)
+	}
+	c := h.l.With()
+	c.l.context = appendObjectData(c.l.context, d.buf[beginMarkerLen:])
+	putEvent(d)
+	h.l = c.Logger()
+	return h
+}
+
+// WithGroup implements the slog.Handler interface.
+func (h slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]slogGroup, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+	h.groups = append(groups, slogGroup{name: name})
+	return h
+}
+
+// addSlogAttr adds a to e as a field and returns false if a is empty and
+// thus ignored.
+func addSlogAttr(e *Event, a slog.Attr) bool {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return false
+		}
+		if a.Key == "" {
+			// Inline the attributes of groups without key.
+			added := false
+			for _, ga := range attrs {
+				added = addSlogAttr(e, ga) || added
+			}
+			return added
+		}
+		d := Dict()
+		for _, ga := range attrs {
+			addSlogAttr(d, ga)
+		}
+		e.Dict(a.Key, d)
+		return true
+	}
+	if a.Key == "" {
+		return false
+	}
+	switch v.Kind() {
+	case slog.KindString:
+		e.Str(a.Key, v.String())
+	case slog.KindInt64:
+		e.Int64(a.Key, v.Int64())
+	case slog.KindUint64:
+		e.Uint64(a.Key, v.Uint64())
+	case slog.KindFloat64:
+		e.Float64(a.Key, v.Float64())
+	case slog.KindBool:
+		e.Bool(a.Key, v.Bool())
+	case slog.KindDuration:
+		e.Dur(a.Key, v.Duration())
+	case slog.KindTime:
+		e.Time(a.Key, v.Time())
+	default:
+		if err, ok := v.Any().(error); ok {
+			e.AnErr(a.Key, err)
+		} else {
+			e.Interface(a.Key, v.Any())
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+// +build go1.21
+
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSlogHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := slog.New(NewSlogHandler(New(out).With().Timestamp().Logger().Level(InfoLevel)))
+	TimestampFunc = func() time.Time {
+		return time.Date(2001, time.February, 3, 4, 5, 6, 7, time.UTC)
+	}
+	defer func() {
+		TimestampFunc = time.Now
+	}()
+	tests := []struct {
+		name string
+		f    func()
+		want string
+	}{
+		{"Filtered", func() {
+			log.Debug("hidden")
+		}, ``},
+		{"Attrs", func() {
+			log.Info("hello", "str", "foo", "int", 1, "float", 1.5, "bool", true, "dur", time.Second, "err", errors.New("boom"), "any", []int{1})
+		}, `{"time":"2001-02-03T04:05:06Z","level":"info","str":"foo","int":1,"float":1.5,"bool":true,"dur":1000,"err":"boom","any":[1],"message":"hello"}`},
+		{"Levels", func() {
+			log.Warn("w")
+			log.Log(nil, slog.LevelError+4, "e")
+		}, `{"time":"2001-02-03T04:05:06Z","level":"warn","message":"w"}` + "\n" + `{"time":"2001-02-03T04:05:06Z","level":"error","message":"e"}`},
+		{"Group", func() {
+			log.Info("g", slog.Group("req", "method", "GET", slog.Group("empty")), slog.Group("", "inline", 1))
+		}, `{"time":"2001-02-03T04:05:06Z","level":"info","req":{"method":"GET"},"inline":1,"message":"g"}`},
+		{"WithAttrs", func() {
+			log.With("a", 1).With("b", 2).Info("w")
+		}, `{"time":"2001-02-03T04:05:06Z","level":"info","a":1,"b":2,"message":"w"}`},
+		{"WithGroup", func() {
+			log.With("a", 1).WithGroup("g").With("b", 2).WithGroup("h").Info("w", "c", 3)
+		}, `{"time":"2001-02-03T04:05:06Z","level":"info","a":1,"g":{"b":2,"h":{"c":3}},"message":"w"}`},
+		{"EmptyGroup", func() {
+			log.WithGroup("g").WithGroup("h").Info("w")
+		}, `{"time":"2001-02-03T04:05:06Z","level":"info","message":"w"}`},
+	}
+	for _, tt := range tests {
+		out.Reset()
+		tt.f()
+		want := tt.want
+		if want != "" {
+			want += "\n"
+		}
+		if got := decodeIfBinaryToString(out.Bytes()); got != want {
+			t.Errorf("%s: invalid log output:\ngot:  %v\nwant: %v", tt.name, got, want)
+		}
+	}
+}
+
+func TestSlogHandlerRecordTime(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := slog.New(NewSlogHandler(New(out)))
+	log.Info("hello")
+	if !bytes.Contains(decodeIfBinaryToBytes(out.Bytes()), []byte(`"time":`)) {
+		t.Errorf("missing record time: %s", decodeIfBinaryToString(out.Bytes()))
+	}
+}
@@ -0,0 +1,100 @@
+package zerolog
+
+import (
+	"log"
+	"regexp"
+	"strings"
+)
+
+var (
+	// stdLogHeaderRe matches the date, time and file header of the standard
+	// library log messages.
+	stdLogHeaderRe = regexp.MustCompile(`^(?:\d{4}/\d{2}/\d{2} )?(?:\d{2}:\d{2}:\d{2}(?:\.\d+)? )?(?:(\S+\.go:\d+): )?`)
+
+	// stdLogLevelRe matches a level prefix of the standard library log
+	// messages, like "[WARN] " or "error: ".
+	stdLogLevelRe = regexp.MustCompile(`(?i)^(?:\[(trace|debug|info|warn|warning|error|err|fatal|panic)\] ?|(trace|debug|info|warn|warning|error|err|fatal|panic): )`)
+)
+
+// stdLogWriter logs the messages written by a standard library logger.
+type stdLogWriter struct {
+	l      Logger
+	level  Level
+	detect bool
+}
+
+// Write implements the io.Writer interface.
+func (w stdLogWriter) Write(p []byte) (n int, err error) {
+	n = len(p)
+	if n > 0 && p[n-1] == '\n' {
+		p = p[:n-1]
+	}
+	var caller string
+	if m := stdLogHeaderRe.FindSubmatchIndex(p); m != nil {
+		if m[2] >= 0 {
+			caller = string(p[m[2]:m[3]])
+		}
+		p = p[m[1]:]
+	}
+	level := w.level
+	if w.detect {
+		if m := stdLogLevelRe.FindSubmatch(p); m != nil {
+			level = stdLogLevel(string(m[1]) + string(m[2]))
+			p = p[len(m[0]):]
+		}
+	}
+	e := w.l.WithLevel(level)
+	if caller != "" {
+		e.Str(CallerFieldName, caller)
+	}
+	e.Msg(string(p))
+	return
+}
+
+// stdLogLevel returns the level of a level prefix matched by stdLogLevelRe.
+func stdLogLevel(s string) Level {
+	switch strings.ToLower(s) {
+	case "trace":
+		return TraceLevel
+	case "debug":
+		return DebugLevel
+	case "info":
+		return InfoLevel
+	case "warn", "warning":
+		return WarnLevel
+	case "fatal":
+		return FatalLevel
+	case "panic":
+		return PanicLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+// StdLogger returns a standard library logger whose messages are logged by l
+// at level, so libraries requiring a *log.Logger log structured events.
+//
+// The date and time headers of the messages are dropped and the file header,
+// added with the log.Lshortfile or log.Llongfile flags, is logged in the
+// zerolog.CallerFieldName field. Fatal and panic levels are logged without
+// exiting or panicking.
+//
+//     srv := &http.Server{ErrorLog: zerolog.StdLogger(log.Logger, zerolog.ErrorLevel)}
+func StdLogger(l Logger, level Level) *log.Logger {
+	return log.New(stdLogWriter{l: l, level: level}, "", 0)
+}
+
+// SetStdLogOutput sets l as the output of the standard library log package.
+// The level of each message is detected from a prefix like "[WARN] " or
+// "error: ", which is removed from the message. Messages without a level
+// prefix are logged at level.
+//
+// Headers are handled as with StdLogger, so the flags of the standard
+// library logger do not need to be changed.
+//
+//     zerolog.SetStdLogOutput(log.Logger, zerolog.InfoLevel)
+//     stdlog.Print("[WARN] disk almost full")
+//     // Output: {"level":"warn","message":"disk almost full"}
+func SetStdLogOutput(l Logger, level Level) {
+	log.SetOutput(stdLogWriter{l: l, level: level, detect: true})
+}
@@ -0,0 +1,40 @@
+package zerolog
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestStdLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	std := StdLogger(New(out), WarnLevel)
+	std.Print("hello")
+	std.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	std.Print("[ERROR] with header")
+	want := `{"level":"warn","message":"hello"}` + "\n" +
+		`{"level":"warn","caller":"stdlog_test.go:15","message":"[ERROR] with header"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestSetStdLogOutput(t *testing.T) {
+	skipIfNoDebug(t)
+	defer log.SetOutput(os.Stderr)
+	out := &bytes.Buffer{}
+	SetStdLogOutput(New(out), InfoLevel)
+	for _, msg := range []string{"plain", "[WARN] bracket", "[debug]tight", "error: colon", "Warning: word", "Error occurred"} {
+		log.Print(msg)
+	}
+	want := `{"level":"info","message":"plain"}` + "\n" +
+		`{"level":"warn","message":"bracket"}` + "\n" +
+		`{"level":"debug","message":"tight"}` + "\n" +
+		`{"level":"error","message":"colon"}` + "\n" +
+		`{"level":"warn","message":"word"}` + "\n" +
+		`{"level":"info","message":"Error occurred"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
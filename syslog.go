@@ -20,7 +20,7 @@ type syslogWriter struct {
 }
 
 // SyslogLevelWriter wraps a SyslogWriter and call the right syslog level
-// method matching the zerolog level.
+// method matching the zerolog level, as defined by SyslogSeverities.
 func SyslogLevelWriter(w SyslogWriter) LevelWriter {
 	return syslogWriter{w}
 }
@@ -31,21 +31,19 @@ func (sw syslogWriter) Write(p []byte) (n int, err error) {
 
 // WriteLevel implements LevelWriter interface.
 func (sw syslogWriter) WriteLevel(level Level, p []byte) (n int, err error) {
-	switch level {
-	case DebugLevel:
+	switch SyslogSeverities.Severity(level) {
+	case "debug":
 		err = sw.w.Debug(string(p))
-	case InfoLevel:
-		err = sw.w.Info(string(p))
-	case WarnLevel:
+	case "warning":
 		err = sw.w.Warning(string(p))
-	case ErrorLevel:
+	case "err":
 		err = sw.w.Err(string(p))
-	case FatalLevel:
+	case "emerg":
 		err = sw.w.Emerg(string(p))
-	case PanicLevel:
+	case "crit":
 		err = sw.w.Crit(string(p))
 	default:
-		panic("invalid level")
+		err = sw.w.Info(string(p))
 	}
 	n = len(p)
 	return
@@ -15,31 +15,32 @@ func (w *syslogTestWriter) Write(p []byte) (int, error) {
 	return 0, nil
 }
 func (w *syslogTestWriter) Debug(m string) error {
-	w.events = append(w.events, syslogEvent{"Debug", m})
+	w.events = append(w.events, syslogEvent{"Debug", decodeIfBinaryToString([]byte(m))})
 	return nil
 }
 func (w *syslogTestWriter) Info(m string) error {
-	w.events = append(w.events, syslogEvent{"Info", m})
+	w.events = append(w.events, syslogEvent{"Info", decodeIfBinaryToString([]byte(m))})
 	return nil
 }
 func (w *syslogTestWriter) Warning(m string) error {
-	w.events = append(w.events, syslogEvent{"Warning", m})
+	w.events = append(w.events, syslogEvent{"Warning", decodeIfBinaryToString([]byte(m))})
 	return nil
 }
 func (w *syslogTestWriter) Err(m string) error {
-	w.events = append(w.events, syslogEvent{"Err", m})
+	w.events = append(w.events, syslogEvent{"Err", decodeIfBinaryToString([]byte(m))})
 	return nil
 }
 func (w *syslogTestWriter) Emerg(m string) error {
-	w.events = append(w.events, syslogEvent{"Emerg", m})
+	w.events = append(w.events, syslogEvent{"Emerg", decodeIfBinaryToString([]byte(m))})
 	return nil
 }
 func (w *syslogTestWriter) Crit(m string) error {
-	w.events = append(w.events, syslogEvent{"Crit", m})
+	w.events = append(w.events, syslogEvent{"Crit", decodeIfBinaryToString([]byte(m))})
 	return nil
 }
 
 func TestSyslogWriter(t *testing.T) {
+	skipIfNoDebug(t)
 	sw := &syslogTestWriter{}
 	log := New(SyslogLevelWriter(sw))
 	log.Debug().Msg("debug")
@@ -56,3 +57,22 @@ func TestSyslogWriter(t *testing.T) {
 		t.Errorf("Invalid syslog message routing: want %v, got %v", want, got)
 	}
 }
+
+func TestSyslogWriterCustomLevel(t *testing.T) {
+	skipIfNoDebug(t)
+	defer SetGlobalLevel(GlobalLevel())
+	SetGlobalLevel(TraceLevel - 3)
+	sw := &syslogTestWriter{}
+	log := New(SyslogLevelWriter(sw)).Level(TraceLevel - 3)
+	log.WithLevel(TraceLevel - 3).Msg("verbose")
+	log.WithLevel(PanicLevel + 40).Msg("audit")
+	log.Log().Msg("nolevel")
+	want := []syslogEvent{
+		{"Debug", `{"level":"-4","message":"verbose"}` + "\n"},
+		{"Crit", `{"level":"45","message":"audit"}` + "\n"},
+		{"Info", `{"message":"nolevel"}` + "\n"},
+	}
+	if got := sw.events; !reflect.DeepEqual(got, want) {
+		t.Errorf("Invalid syslog message routing: want %v, got %v", want, got)
+	}
+}
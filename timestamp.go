@@ -0,0 +1,68 @@
+package zerolog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// timestampCache holds the timestamp formatted by the refresh goroutine of
+// SetTimestampCache.
+type timestampCache struct {
+	format string
+	b      []byte
+}
+
+var (
+	cachedTimestamp atomic.Value
+
+	timestampCacheMu   sync.Mutex
+	timestampCacheStop chan struct{}
+)
+
+// SetTimestampCache makes the timestamps added by Timestamp coarse grained:
+// a background goroutine reads TimestampFunc and formats the timestamp,
+// truncated to resolution, every resolution, and the events reuse the
+// formatted value instead of reading the clock and formatting it each time.
+// It is meant for high volume logging not needing sub-resolution
+// precision.
+//
+// A resolution of zero stops the goroutine and restores the per event
+// timestamps. The cache only applies to the JSON encoding with a non empty
+// TimeFieldFormat, and is bypassed until the next refresh when
+// TimeFieldFormat is changed.
+func SetTimestampCache(resolution time.Duration) {
+	timestampCacheMu.Lock()
+	defer timestampCacheMu.Unlock()
+	if timestampCacheStop != nil {
+		close(timestampCacheStop)
+		timestampCacheStop = nil
+	}
+	if resolution <= 0 {
+		cachedTimestamp.Store((*timestampCache)(nil))
+		return
+	}
+	refreshTimestamp(resolution)
+	stop := make(chan struct{})
+	timestampCacheStop = stop
+	go func() {
+		t := time.NewTicker(resolution)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				refreshTimestamp(resolution)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// refreshTimestamp formats the current timestamp, truncated to resolution,
+// into the cache.
+func refreshTimestamp(resolution time.Duration) {
+	format := TimeFieldFormat
+	t := TimestampFunc().Truncate(resolution)
+	cachedTimestamp.Store(&timestampCache{format: format, b: t.AppendFormat(nil, format)})
+}
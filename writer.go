@@ -1,8 +1,13 @@
 package zerolog
 
 import (
+	"bytes"
+	"context"
 	"io"
+	"net"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // LevelWriter defines as interface a writer may implement in order
@@ -98,3 +103,574 @@ func MultiLevelWriter(writers ...io.Writer) LevelWriter {
 	}
 	return multiLevelWriter{lwriters}
 }
+
+type rateLimitWindow struct {
+	start   time.Time
+	count   int
+	dropped int
+	timer   *time.Timer
+}
+
+type rateLimitWriter struct {
+	mu      sync.Mutex
+	lw      LevelWriter
+	limit   int
+	period  time.Duration
+	key     func(l Level, p []byte) string
+	windows map[string]*rateLimitWindow
+	now     func() time.Time // time.Now, replaced in tests
+}
+
+// RateLimitWriter wraps w so that at most limit events per period are
+// written for each key returned by the key function. Extra events are
+// dropped and a warning summarizing the number of dropped events is written
+// at the end of the period.
+//
+// Events written with AuditLevel are never dropped nor counted.
+//
+// If key is nil, all events share the same limit. Use MessageKey to limit
+// events per message. Keys should have a low cardinality as a state is kept
+// for each of them.
+func RateLimitWriter(w io.Writer, limit int, period time.Duration, key func(l Level, p []byte) string) LevelWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = levelWriterAdapter{w}
+	}
+	return &rateLimitWriter{
+		lw:      lw,
+		limit:   limit,
+		period:  period,
+		key:     key,
+		windows: map[string]*rateLimitWindow{},
+		now:     time.Now,
+	}
+}
+
+// MessageKey returns the raw encoded message of the event p, as encoded in
+// JSON. It is meant to be used as RateLimitWriter key function to limit
+// events per message. With the zerolog_bson build tag, the event is
+// transcoded to JSON first.
+func MessageKey(l Level, p []byte) string {
+	p = decodeIfBinaryToBytes(p)
+	marker := []byte(`"` + MessageFieldName + `":`)
+	i := bytes.LastIndex(p, marker)
+	if i == -1 {
+		return ""
+	}
+	msg := p[i+len(marker):]
+	msg = bytes.TrimRight(msg, "}\n")
+	return string(msg)
+}
+
+// Write implements the io.Writer interface.
+func (w *rateLimitWriter) Write(p []byte) (n int, err error) {
+	return w.write(InfoLevel, p, false)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *rateLimitWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	return w.write(l, p, true)
+}
+
+func (w *rateLimitWriter) write(l Level, p []byte, leveled bool) (n int, err error) {
+	if leveled && l == AuditLevel {
+		return w.lw.WriteLevel(l, p)
+	}
+	var k string
+	if w.key != nil {
+		k = w.key(l, p)
+	}
+	now := w.now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	win := w.windows[k]
+	if win == nil || now.Sub(win.start) >= w.period {
+		if win != nil && win.dropped > 0 {
+			// The summary timer did not fire yet.
+			win.timer.Stop()
+			w.summarize(k, win)
+		}
+		win = &rateLimitWindow{start: now}
+		w.windows[k] = win
+	}
+	win.count++
+	if win.count > w.limit {
+		win.dropped++
+		if win.dropped == 1 {
+			win.timer = time.AfterFunc(win.start.Add(w.period).Sub(now), func() {
+				w.mu.Lock()
+				defer w.mu.Unlock()
+				w.summarize(k, win)
+			})
+		}
+		return len(p), nil
+	}
+	if leveled {
+		return w.lw.WriteLevel(l, p)
+	}
+	return w.lw.Write(p)
+}
+
+// summarize writes the number of events dropped during win if any. It must
+// be called with w.mu held.
+func (w *rateLimitWriter) summarize(k string, win *rateLimitWindow) {
+	if win.dropped == 0 {
+		return
+	}
+	e := New(w.lw).Warn().Timestamp()
+	if w.key != nil {
+		e.Str("limit_key", k)
+	}
+	e.Int("dropped", win.dropped).
+		Msgf("dropped %d events in the last %s", win.dropped, w.period)
+	win.dropped = 0
+	if w.windows[k] == win {
+		delete(w.windows, k)
+	}
+}
+
+// WriteHooks are run by a HookWriter with the serialized events, unlike
+// Hook which is run with the event before it is serialized. Either function
+// may be nil.
+type WriteHooks struct {
+	// Before is called with the serialized event p before it is written
+	// and returns the bytes to write in its place, so it can be used to
+	// sign or encrypt events. p must not be retained.
+	Before func(level Level, p []byte) []byte
+
+	// After is called once the bytes returned by Before are written with
+	// the duration and the error of the write.
+	After func(level Level, p []byte, d time.Duration, err error)
+}
+
+type hookWriter struct {
+	lw    LevelWriter
+	hooks WriteHooks
+}
+
+// HookWriter wraps w so that hooks are run with each event written to w.
+func HookWriter(w io.Writer, hooks WriteHooks) LevelWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = levelWriterAdapter{w}
+	}
+	return hookWriter{lw: lw, hooks: hooks}
+}
+
+// Write implements the io.Writer interface.
+func (w hookWriter) Write(p []byte) (n int, err error) {
+	return w.write(NoLevel, p, false)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w hookWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	return w.write(l, p, true)
+}
+
+func (w hookWriter) write(l Level, p []byte, leveled bool) (n int, err error) {
+	b := p
+	if w.hooks.Before != nil {
+		b = w.hooks.Before(l, p)
+	}
+	start := time.Now()
+	if leveled {
+		n, err = w.lw.WriteLevel(l, b)
+	} else {
+		n, err = w.lw.Write(b)
+	}
+	if w.hooks.After != nil {
+		w.hooks.After(l, b, time.Since(start), err)
+	}
+	if err == nil {
+		// Report p as written even if Before changed its length.
+		n = len(p)
+	}
+	return
+}
+
+type dedupWriter struct {
+	mu       sync.Mutex
+	lw       LevelWriter
+	window   time.Duration
+	key      func(l Level, p []byte) string
+	last     string
+	level    Level
+	start    time.Time
+	running  bool
+	repeated int
+	timer    *time.Timer
+}
+
+// DedupWriter wraps w so that runs of identical events written within window
+// are collapsed: the first event of a run is written and the following ones
+// are dropped. A summary giving the number of repeated events is written once
+// a different event is written or window has elapsed, similar to syslog's
+// "last message repeated N times".
+//
+// Events are identical if the key function returns the same key for them
+// and they have the same level. If key is nil, events must be byte-identical,
+// which never happens for timestamped events: use MessageKey to compare
+// messages instead. Events written with AuditLevel are never dropped.
+func DedupWriter(w io.Writer, window time.Duration, key func(l Level, p []byte) string) LevelWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = levelWriterAdapter{w}
+	}
+	return &dedupWriter{
+		lw:     lw,
+		window: window,
+		key:    key,
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *dedupWriter) Write(p []byte) (n int, err error) {
+	return w.write(NoLevel, p, false)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *dedupWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	return w.write(l, p, true)
+}
+
+func (w *dedupWriter) write(l Level, p []byte, leveled bool) (n int, err error) {
+	if leveled && l == AuditLevel {
+		return w.lw.WriteLevel(l, p)
+	}
+	var k string
+	if w.key != nil {
+		k = w.key(l, p)
+	} else {
+		k = string(p)
+	}
+	now := time.Now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.running && k == w.last && l == w.level && now.Sub(w.start) < w.window {
+		w.repeated++
+		if w.repeated == 1 {
+			start := w.start
+			w.timer = time.AfterFunc(start.Add(w.window).Sub(now), func() {
+				w.mu.Lock()
+				defer w.mu.Unlock()
+				if w.running && w.start == start {
+					w.summarize()
+					w.running = false
+				}
+			})
+		}
+		return len(p), nil
+	}
+	if w.repeated > 0 {
+		w.timer.Stop()
+		w.summarize()
+	}
+	w.last, w.level, w.start, w.running = k, l, now, true
+	if leveled {
+		return w.lw.WriteLevel(l, p)
+	}
+	return w.lw.Write(p)
+}
+
+// summarize writes the number of repeated events of the current run if any.
+// It must be called with w.mu held.
+func (w *dedupWriter) summarize() {
+	if w.repeated == 0 {
+		return
+	}
+	New(w.lw).WithLevel(w.level).Timestamp().
+		Int("repeated", w.repeated).
+		Msgf("last message repeated %d times", w.repeated)
+	w.repeated = 0
+}
+
+type teeWriter struct {
+	lw    LevelWriter
+	tee   LevelWriter
+	match func(l Level, p []byte) bool
+}
+
+// TeeWriter returns a writer writing all the events to w and also the events
+// for which match returns true to tee. This can be used to keep a separate,
+// append-only audit trail of designated events in addition to the normal
+// output:
+//
+//     w := zerolog.TeeWriter(os.Stderr, auditFile, zerolog.FieldMatcher("audit", true))
+//
+// If match is nil, the events written with AuditLevel are teed. An error
+// writing to tee is returned even if the write to w succeeded.
+func TeeWriter(w, tee io.Writer, match func(l Level, p []byte) bool) LevelWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = levelWriterAdapter{w}
+	}
+	ltee, ok := tee.(LevelWriter)
+	if !ok {
+		ltee = levelWriterAdapter{tee}
+	}
+	if match == nil {
+		match = func(l Level, p []byte) bool {
+			return l == AuditLevel
+		}
+	}
+	return teeWriter{lw: lw, tee: ltee, match: match}
+}
+
+// Write implements the io.Writer interface.
+func (w teeWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w teeWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	n, err = w.lw.WriteLevel(l, p)
+	if !w.match(l, p) {
+		return
+	}
+	if _, terr := w.tee.WriteLevel(l, p); terr != nil {
+		err = terr
+	}
+	return
+}
+
+// FieldMatcher returns a TeeWriter match function matching the events having
+// the field key with the value val, as added by Interface. Fields of nested
+// dictionaries are matched too. With the zerolog_bson build tag, the BSON
+// encoding of the field is matched.
+func FieldMatcher(key string, val interface{}) func(l Level, p []byte) bool {
+	marker := appendBeginMarker(nil)
+	field := appendInterface(marker, key, val)[len(marker):]
+	return func(l Level, p []byte) bool {
+		return bytes.Contains(p, field)
+	}
+}
+
+// BroadcastWriter is a writer publishing the events written to it to
+// subscribers over channels, so the process can live-tail its own logs, for
+// instance from a debug endpoint. Publishing never blocks: events are dropped
+// for subscribers whose channel is full.
+//
+//     bw := zerolog.NewBroadcastWriter()
+//     log := zerolog.New(zerolog.MultiLevelWriter(os.Stderr, bw))
+//
+//     sub := bw.Subscribe(100)
+//     defer sub.Close()
+//     for p := range sub.C {
+//         fmt.Print(string(p))
+//     }
+type BroadcastWriter struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// Subscription receives the events published by a BroadcastWriter.
+type Subscription struct {
+	// C receives the events. It is closed by Close.
+	C <-chan []byte
+
+	c       chan []byte
+	w       *BroadcastWriter
+	dropped uint64
+}
+
+// NewBroadcastWriter returns a BroadcastWriter without subscribers.
+func NewBroadcastWriter() *BroadcastWriter {
+	return &BroadcastWriter{subs: map[*Subscription]struct{}{}}
+}
+
+// Subscribe returns a Subscription receiving the events written from now on,
+// buffering up to size events. Close must be called once the subscriber is
+// done.
+func (w *BroadcastWriter) Subscribe(size int) *Subscription {
+	c := make(chan []byte, size)
+	s := &Subscription{C: c, c: c, w: w}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subs[s] = struct{}{}
+	return s
+}
+
+// Write implements the io.Writer interface. The event is copied if there is
+// any subscriber.
+func (w *BroadcastWriter) Write(p []byte) (n int, err error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if len(w.subs) == 0 {
+		return len(p), nil
+	}
+	// The copy is shared by the subscribers.
+	cp := append([]byte(nil), p...)
+	for s := range w.subs {
+		select {
+		case s.c <- cp:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	}
+	return len(p), nil
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *BroadcastWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	return w.Write(p)
+}
+
+// Dropped returns the number of events dropped because C was full.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close unsubscribes s and closes C. Close is idempotent.
+func (s *Subscription) Close() {
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+	if _, ok := s.w.subs[s]; ok {
+		delete(s.w.subs, s)
+		close(s.c)
+	}
+}
+
+// BatchWriter coalesces events into batches written to the wrapped writer
+// with a single Write call, to reduce the number of write syscalls when
+// logging to pipes or files. Events are never split across batches: a batch
+// only holds complete events.
+//
+// A batch is written once it reaches maxSize bytes or maxDelay after its
+// first event, whichever comes first. Events larger than maxSize are
+// written alone. Events written with FatalLevel, PanicLevel or AuditLevel
+// are written immediately, with the pending batch, so they are not lost if
+// the process exits.
+//
+// Events are copied into the batch, as their buffers are reused once
+// written. The events written immediately, which complete or exceed a
+// batch, are not copied though when w is a TCP or Unix connection or, on
+// Linux, a file: the batch and the event are written with a single vectored
+// write (writev).
+//
+// Errors of the writes triggered by maxDelay are reported to ErrorHandler.
+// Close, or Shutdown registered with RegisterShutdown, must be called to
+// write the last batch.
+type BatchWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	maxSize  int
+	maxDelay time.Duration
+	buf      []byte
+	timer    *time.Timer
+	// vectored is true if the batch and an event can be written to w with
+	// a vectored write, using bufs.
+	vectored bool
+	bufs     net.Buffers
+}
+
+// NewBatchWriter returns a BatchWriter writing batches of at most maxSize
+// bytes to w, at most maxDelay after their first event.
+func NewBatchWriter(w io.Writer, maxSize int, maxDelay time.Duration) *BatchWriter {
+	return &BatchWriter{
+		w:        w,
+		maxSize:  maxSize,
+		maxDelay: maxDelay,
+		buf:      make([]byte, 0, maxSize),
+		vectored: canWriteBuffers(w),
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *BatchWriter) Write(p []byte) (n int, err error) {
+	return w.write(p, false)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *BatchWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	return w.write(p, l >= FatalLevel && l != NoLevel)
+}
+
+func (w *BatchWriter) write(p []byte, flush bool) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 && len(w.buf)+len(p) > w.maxSize {
+		if err = w.flush(); err != nil {
+			return 0, err
+		}
+	}
+	if w.vectored && (flush || len(w.buf)+len(p) >= w.maxSize) {
+		if err = w.flushWith(p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	// p is copied as the buffer of the event is reused once written.
+	w.buf = append(w.buf, p...)
+	if flush || len(w.buf) >= w.maxSize {
+		if err = w.flush(); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.maxDelay, w.flushTimer)
+	}
+	return len(p), nil
+}
+
+// flushTimer writes the pending batch once maxDelay elapsed.
+func (w *BatchWriter) flushTimer() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timer = nil
+	if err := w.flush(); err != nil {
+		handleError(nil, err)
+	}
+}
+
+// flush writes the pending batch. It must be called with w.mu held.
+func (w *BatchWriter) flush() error {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.w.Write(w.buf)
+	if cap(w.buf) > 2*w.maxSize {
+		// Do not keep the memory of an oversized event.
+		w.buf = make([]byte, 0, w.maxSize)
+	} else {
+		w.buf = w.buf[:0]
+	}
+	return err
+}
+
+// flushWith writes the pending batch followed by p with a single vectored
+// write, without copying p. It must be called with w.mu held.
+func (w *BatchWriter) flushWith(p []byte) error {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.bufs = append(w.bufs[:0], w.buf, p)
+	_, err := writeBuffers(w.w, w.bufs, nil)
+	// Do not keep a reference to the buffer of the event.
+	w.bufs[0], w.bufs[1] = nil, nil
+	w.buf = w.buf[:0]
+	return err
+}
+
+// Flush writes the pending batch.
+func (w *BatchWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
+// Close writes the pending batch. It implements the io.Closer interface so
+// the writer can be closed like a file; the wrapped writer is not closed.
+func (w *BatchWriter) Close() error {
+	return w.Flush()
+}
+
+// Shutdown is like Close. It can be registered with RegisterShutdown.
+func (w *BatchWriter) Shutdown(ctx context.Context) error {
+	return w.Flush()
+}
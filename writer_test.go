@@ -1,11 +1,16 @@
 package zerolog
 
 import (
+	"bytes"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestMultiSyslogWriter(t *testing.T) {
+	skipIfNoDebug(t)
 	sw := &syslogTestWriter{}
 	log := New(MultiLevelWriter(SyslogLevelWriter(sw)))
 	log.Debug().Msg("debug")
@@ -22,3 +27,256 @@ func TestMultiSyslogWriter(t *testing.T) {
 		t.Errorf("Invalid syslog message routing: want %v, got %v", want, got)
 	}
 }
+
+func TestRateLimitWriter(t *testing.T) {
+	TimestampFunc = func() time.Time {
+		return time.Date(2001, time.February, 3, 4, 5, 6, 7, time.UTC)
+	}
+	defer func() {
+		TimestampFunc = time.Now
+	}()
+	out := &bytes.Buffer{}
+	w := RateLimitWriter(out, 2, time.Hour, nil)
+	// The window is long enough for the summary timer not to fire: the
+	// window ends when the fake clock is advanced instead.
+	now := time.Date(2001, time.February, 3, 4, 5, 6, 7, time.UTC)
+	w.(*rateLimitWriter).now = func() time.Time { return now }
+	read := func() string {
+		s := decodeIfBinaryToString(out.Bytes())
+		out.Reset()
+		return s
+	}
+	log := New(w)
+	for i := 0; i < 5; i++ {
+		log.Info().Int("i", i).Msg("")
+	}
+	if got, want := read(), `{"level":"info","i":0}`+"\n"+`{"level":"info","i":1}`+"\n"; got != want {
+		t.Errorf("invalid log output: got %q, want %q", got, want)
+	}
+	now = now.Add(time.Hour)
+	log.Info().Int("i", 5).Msg("")
+	want := `{"level":"warn","time":"2001-02-03T04:05:06Z","dropped":3,"message":"dropped 3 events in the last 1h0m0s"}` + "\n" +
+		`{"level":"info","i":5}` + "\n"
+	if got := read(); got != want {
+		t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestRateLimitWriterSummary(t *testing.T) {
+	TimestampFunc = func() time.Time {
+		return time.Date(2001, time.February, 3, 4, 5, 6, 7, time.UTC)
+	}
+	defer func() {
+		TimestampFunc = time.Now
+	}()
+	lw := &levelWriter{}
+	w := RateLimitWriter(lw, 1, 20*time.Millisecond, MessageKey)
+	log := New(w)
+	log.Error().Msg("foo")
+	log.Error().Msg("foo")
+	log.Error().Msg("foo")
+	log.Error().Msg("bar")
+	time.Sleep(50 * time.Millisecond)
+	w.(*rateLimitWriter).mu.Lock()
+	defer w.(*rateLimitWriter).mu.Unlock()
+	want := []struct {
+		l Level
+		p string
+	}{
+		{ErrorLevel, `{"level":"error","message":"foo"}` + "\n"},
+		{ErrorLevel, `{"level":"error","message":"bar"}` + "\n"},
+		{WarnLevel, `{"level":"warn","time":"2001-02-03T04:05:06Z","limit_key":"\"foo\"","dropped":2,"message":"dropped 2 events in the last 20ms"}` + "\n"},
+	}
+	if got := lw.ops; !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid ops:\ngot:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestMessageKey(t *testing.T) {
+	if got, want := MessageKey(InfoLevel, []byte(`{"a":"\"message\":1","message":"foo"}`+"\n")), `"foo"`; got != want {
+		t.Errorf("MessageKey() = %q, want %q", got, want)
+	}
+	if got, want := MessageKey(InfoLevel, []byte(`{"a":1}`+"\n")), ""; got != want {
+		t.Errorf("MessageKey() = %q, want %q", got, want)
+	}
+}
+
+func TestHookWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	var after []string
+	w := HookWriter(out, WriteHooks{
+		Before: func(level Level, p []byte) []byte {
+			return append([]byte(level.String()+" "), decodeIfBinaryToBytes(p)...)
+		},
+		After: func(level Level, p []byte, d time.Duration, err error) {
+			if d < 0 || err != nil {
+				t.Errorf("invalid write: %v, %v", d, err)
+			}
+			after = append(after, decodeIfBinaryToString(p))
+		},
+	})
+	log := New(w)
+	log.Info().Msg("a")
+	log.Warn().Msg("b")
+	want := "info {\"level\":\"info\",\"message\":\"a\"}\nwarn {\"level\":\"warn\",\"message\":\"b\"}\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, want)
+	}
+	if got := strings.Join(after, ""); got != want {
+		t.Errorf("invalid after hook input:\ngot:  %q\nwant: %q", got, want)
+	}
+	if n, err := w.Write([]byte("x")); n != 1 || err != nil {
+		t.Errorf("Write() = %d, %v, want 1, <nil>", n, err)
+	}
+}
+
+func TestDedupWriter(t *testing.T) {
+	TimestampFunc = func() time.Time {
+		return time.Date(2001, time.February, 3, 4, 5, 6, 7, time.UTC)
+	}
+	defer func() {
+		TimestampFunc = time.Now
+	}()
+	out := &bytes.Buffer{}
+	w := DedupWriter(out, 50*time.Millisecond, nil)
+	read := func() string {
+		w.(*dedupWriter).mu.Lock()
+		defer w.(*dedupWriter).mu.Unlock()
+		s := decodeIfBinaryToString(out.Bytes())
+		out.Reset()
+		return s
+	}
+	log := New(w)
+	log.Error().Msg("foo")
+	log.Error().Msg("foo")
+	log.Error().Msg("foo")
+	log.Warn().Msg("foo")
+	log.Warn().Msg("bar")
+	want := `{"level":"error","message":"foo"}` + "\n" +
+		`{"level":"error","time":"2001-02-03T04:05:06Z","repeated":2,"message":"last message repeated 2 times"}` + "\n" +
+		`{"level":"warn","message":"foo"}` + "\n" +
+		`{"level":"warn","message":"bar"}` + "\n"
+	if got := read(); got != want {
+		t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, want)
+	}
+	log.Warn().Msg("bar")
+	time.Sleep(100 * time.Millisecond)
+	log.Warn().Msg("bar")
+	want = `{"level":"warn","time":"2001-02-03T04:05:06Z","repeated":1,"message":"last message repeated 1 times"}` + "\n" +
+		`{"level":"warn","message":"bar"}` + "\n"
+	if got := read(); got != want {
+		t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestTeeWriter(t *testing.T) {
+	out, audit := &bytes.Buffer{}, &bytes.Buffer{}
+	log := New(TeeWriter(out, audit, FieldMatcher("audit", true)))
+	log.Info().Msg("a")
+	log.Info().Bool("audit", true).Msg("b")
+	log.Info().Str("audit", "true").Msg("c")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"a"}`+"\n"+`{"level":"info","audit":true,"message":"b"}`+"\n"+`{"level":"info","audit":"true","message":"c"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, want)
+	}
+	if got, want := decodeIfBinaryToString(audit.Bytes()), `{"level":"info","audit":true,"message":"b"}`+"\n"; got != want {
+		t.Errorf("invalid audit output:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	out.Reset()
+	audit.Reset()
+	log = New(TeeWriter(out, audit, nil))
+	log.Info().Msg("a")
+	log.Audit().Msg("b")
+	if got, want := decodeIfBinaryToString(audit.Bytes()), `{"level":"audit","message":"b"}`+"\n"; got != want {
+		t.Errorf("invalid audit output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestBroadcastWriter(t *testing.T) {
+	bw := NewBroadcastWriter()
+	log := New(bw)
+	log.Info().Msg("no subscriber")
+	s1, s2 := bw.Subscribe(2), bw.Subscribe(1)
+	log.Info().Msg("a")
+	log.Info().Msg("b")
+	s2.Close()
+	s2.Close()
+	log.Info().Msg("c")
+	s1.Close()
+	var got1 []string
+	for p := range s1.C {
+		got1 = append(got1, decodeIfBinaryToString(p))
+	}
+	if want := []string{`{"level":"info","message":"a"}` + "\n", `{"level":"info","message":"b"}` + "\n"}; !reflect.DeepEqual(got1, want) {
+		t.Errorf("invalid events: got %q, want %q", got1, want)
+	}
+	if got := s1.Dropped(); got != 1 {
+		t.Errorf("s1.Dropped() = %d, want 1", got)
+	}
+	if got := s2.Dropped(); got != 1 {
+		t.Errorf("s2.Dropped() = %d, want 1", got)
+	}
+	if p, ok := <-s2.C; !ok || decodeIfBinaryToString(p) != `{"level":"info","message":"a"}`+"\n" {
+		t.Errorf("invalid s2 event: %q", p)
+	}
+}
+
+type writeRecorder struct {
+	mu     sync.Mutex
+	writes []string
+}
+
+func (w *writeRecorder) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, decodeIfBinaryToString(p))
+	return len(p), nil
+}
+
+func (w *writeRecorder) get() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.writes...)
+}
+
+func TestBatchWriter(t *testing.T) {
+	out := &writeRecorder{}
+	w := NewBatchWriter(out, 45, time.Hour)
+	log := New(w)
+	log.Log().Str("n", "1").Msg("")
+	log.Log().Str("n", "2").Msg("")
+	if got := out.get(); len(got) != 0 {
+		t.Fatalf("events written before the batch is full: %q", got)
+	}
+	log.Log().Str("n", "3").Msg("")
+	log.WithLevel(FatalLevel).Msg("")
+	log.Log().Str("big", strings.Repeat("x", 40)).Msg("")
+	log.Log().Str("n", "4").Msg("")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`{"n":"1"}` + "\n" + `{"n":"2"}` + "\n" + `{"n":"3"}` + "\n",
+		`{"level":"fatal"}` + "\n",
+		`{"big":"` + strings.Repeat("x", 40) + `"}` + "\n",
+		`{"n":"4"}` + "\n",
+	}
+	if got := out.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid writes:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestBatchWriterDelay(t *testing.T) {
+	out := &writeRecorder{}
+	log := New(NewBatchWriter(out, 1024, 10*time.Millisecond))
+	log.Log().Str("n", "1").Msg("")
+	log.Log().Str("n", "2").Msg("")
+	deadline := time.Now().Add(time.Second)
+	for len(out.get()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	want := []string{`{"n":"1"}` + "\n" + `{"n":"2"}` + "\n"}
+	if got := out.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid writes:\ngot:  %q\nwant: %q", got, want)
+	}
+}
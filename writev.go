@@ -0,0 +1,45 @@
+package zerolog
+
+import (
+	"io"
+	"net"
+	"os"
+	"runtime"
+)
+
+// canWriteBuffers returns true if writeBuffers writes to w with vectored
+// writes, without copying the buffers.
+func canWriteBuffers(w io.Writer) bool {
+	switch w.(type) {
+	case *net.TCPConn, *net.UnixConn:
+		return true
+	case *os.File:
+		return runtime.GOOS == "linux"
+	}
+	return false
+}
+
+// writeBuffers writes bufs to w with vectored writes when w is a TCP or Unix
+// connection or, on Linux, a file, so the buffers are not copied. Other
+// writers get a single Write of the concatenation of bufs, built in scratch.
+// It returns scratch for reuse.
+func writeBuffers(w io.Writer, bufs net.Buffers, scratch []byte) ([]byte, error) {
+	switch w := w.(type) {
+	case *net.TCPConn, *net.UnixConn:
+		_, err := bufs.WriteTo(w)
+		return scratch, err
+	case *os.File:
+		if ok, err := writevFile(w, bufs); ok {
+			return scratch, err
+		}
+	}
+	scratch = scratch[:0]
+	for _, b := range bufs {
+		scratch = append(scratch, b...)
+	}
+	if len(scratch) == 0 {
+		return scratch, nil
+	}
+	_, err := w.Write(scratch)
+	return scratch, err
+}
@@ -0,0 +1,70 @@
+package zerolog
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// maxIovecs is the maximum number of buffers written by a writev call.
+const maxIovecs = 1024
+
+// writevFile writes bufs to f with the writev system call. It returns false
+// if f does not support it.
+func writevFile(f *os.File, bufs [][]byte) (bool, error) {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return false, nil
+	}
+	var iovecs [maxIovecs]syscall.Iovec
+	var werr error
+	err = rc.Write(func(fd uintptr) bool {
+		for len(bufs) > 0 {
+			n := 0
+			for _, b := range bufs {
+				if n == maxIovecs {
+					break
+				}
+				if len(b) == 0 {
+					continue
+				}
+				iovecs[n].Base = &b[0]
+				iovecs[n].SetLen(len(b))
+				n++
+			}
+			if n == 0 {
+				return true
+			}
+			written, _, errno := syscall.Syscall(syscall.SYS_WRITEV, fd, uintptr(unsafe.Pointer(&iovecs[0])), uintptr(n))
+			switch errno {
+			case 0:
+			case syscall.EINTR:
+				continue
+			case syscall.EAGAIN:
+				// Wait for the file, a non blocking pipe, to be writable.
+				return false
+			default:
+				werr = errno
+				return true
+			}
+			// Skip the written bytes, the last buffer being partially
+			// written on short writes.
+			for w := int(written); w > 0 && len(bufs) > 0; {
+				if w < len(bufs[0]) {
+					bufs[0] = bufs[0][w:]
+					break
+				}
+				w -= len(bufs[0])
+				bufs = bufs[1:]
+			}
+			for len(bufs) > 0 && len(bufs[0]) == 0 {
+				bufs = bufs[1:]
+			}
+		}
+		return true
+	})
+	if err == nil {
+		err = werr
+	}
+	return true, err
+}
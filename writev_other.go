@@ -0,0 +1,11 @@
+// +build !linux
+
+package zerolog
+
+import "os"
+
+// writevFile writes bufs to f with the writev system call. It returns false
+// if f does not support it.
+func writevFile(f *os.File, bufs [][]byte) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,139 @@
+package zerolog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testBuffers() (net.Buffers, string) {
+	var bufs net.Buffers
+	var want strings.Builder
+	for i := 0; i < 3000; i++ {
+		b := []byte(strings.Repeat("x", i%100) + "\n")
+		if i%7 == 0 {
+			b = nil
+		}
+		bufs = append(bufs, b)
+		want.Write(b)
+	}
+	return bufs, want.String()
+}
+
+func TestWriteBuffers(t *testing.T) {
+	out := &writeRecorder{}
+	bufs := net.Buffers{[]byte("a\n"), nil, []byte("b\n")}
+	if _, err := writeBuffers(out, bufs, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.get(), []string{"a\nb\n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid writes:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestWriteBuffersFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bufs, want := testBuffers()
+	if _, err := writeBuffers(f, bufs, nil); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("invalid file content: got %d bytes, want %d", len(got), len(want))
+	}
+}
+
+func TestBatchWriterFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewBatchWriter(f, 45, time.Hour)
+	log := New(w)
+	log.Log().Str("n", "1").Msg("")
+	log.Log().Str("n", "2").Msg("")
+	log.WithLevel(FatalLevel).Msg("")
+	log.Log().Str("big", strings.Repeat("x", 40)).Msg("")
+	log.Log().Str("n", "3").Msg("")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"n":"1"}` + "\n" + `{"n":"2"}` + "\n" + `{"level":"fatal"}` + "\n" +
+		`{"big":"` + strings.Repeat("x", 40) + `"}` + "\n" + `{"n":"3"}` + "\n"
+	if decodeIfBinaryToString(got) != want {
+		t.Errorf("invalid file content:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestWriteBuffersPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bufs, want := testBuffers()
+	// More than the capacity of the pipe, to wait for the reader.
+	bufs = append(bufs, bytes.Repeat([]byte("y"), 1<<17))
+	want += strings.Repeat("y", 1<<17)
+	read := make(chan []byte)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		read <- b
+	}()
+	if _, err := writeBuffers(w, bufs, nil); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if got := <-read; string(got) != want {
+		t.Errorf("invalid pipe content: got %d bytes, want %d", len(got), len(want))
+	}
+}
+
+func TestWriteBuffersConn(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	read := make(chan []byte)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			read <- nil
+			return
+		}
+		b, _ := ioutil.ReadAll(c)
+		read <- b
+	}()
+	c, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bufs, want := testBuffers()
+	if _, err := writeBuffers(c, bufs, nil); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	if got := <-read; string(got) != want {
+		t.Errorf("invalid connection content: got %d bytes, want %d", len(got), len(want))
+	}
+}
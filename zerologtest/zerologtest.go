@@ -0,0 +1,65 @@
+// Package zerologtest provides loggers writing to the log of a test, so the
+// logs of the code under test are shown with the test output, only for
+// failing tests unless go test is run with -v.
+package zerologtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// Writer is an io.Writer writing each event to the log of a test with
+// t.Log.
+//
+// Events written after the test and its subtests completed are dropped, as
+// calling t.Log after the end of a test panics. This is common with
+// goroutines started by the code under test.
+type Writer struct {
+	t    testing.TB
+	mu   sync.Mutex
+	done bool
+}
+
+// NewWriter returns a Writer writing to the log of t.
+func NewWriter(t testing.TB) *Writer {
+	w := &Writer{t: t}
+	t.Cleanup(func() {
+		w.mu.Lock()
+		w.done = true
+		w.mu.Unlock()
+	})
+	return w
+}
+
+// Write implements the io.Writer interface.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	}
+	return len(p), nil
+}
+
+// NewLogger returns a logger writing to the log of t in the console format.
+// All the events are logged when go test is run with -v, and the events of
+// info level and above otherwise.
+//
+//     func TestServer(t *testing.T) {
+//         s := NewServer(zerologtest.NewLogger(t))
+//         ...
+//     }
+func NewLogger(t testing.TB) zerolog.Logger {
+	level := zerolog.InfoLevel
+	if testing.Verbose() {
+		level = zerolog.TraceLevel
+	}
+	return zerolog.New(zerolog.ConsoleWriter{
+		Out:        NewWriter(t),
+		NoColor:    true,
+		PartsOrder: []string{zerolog.LevelFieldName, zerolog.CallerFieldName, zerolog.MessageFieldName},
+	}).Level(level)
+}
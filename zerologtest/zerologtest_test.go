@@ -0,0 +1,50 @@
+package zerologtest
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+type fakeTB struct {
+	testing.TB
+	logs     []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Log(args ...interface{}) {
+	f.logs = append(f.logs, args[0].(string))
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func TestWriter(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	l := zerolog.New(NewWriter(tb))
+	l.Info().Str("foo", "bar").Msg("running")
+	for _, fn := range tb.cleanups {
+		fn()
+	}
+	l.Info().Msg("after test")
+	if want := `{"level":"info","foo":"bar","message":"running"}`; len(tb.logs) != 1 || tb.logs[0] != want {
+		t.Errorf("invalid logs: %q, want [%q]", tb.logs, want)
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	l := NewLogger(tb)
+	l.Info().Str("foo", "bar").Msg("running")
+	if want := "INF running foo=bar"; len(tb.logs) != 1 || tb.logs[0] != want {
+		t.Errorf("invalid logs: %q, want [%q]", tb.logs, want)
+	}
+	want := zerolog.InfoLevel
+	if testing.Verbose() {
+		want = zerolog.TraceLevel
+	}
+	if got := l.GetLevel(); got != want {
+		t.Errorf("level = %v, want %v", got, want)
+	}
+}